@@ -0,0 +1,242 @@
+// Package anomaly finds steps of a window, such as days, in which the cost of
+// a namespace, controller or label value deviated from its recent baseline.
+package anomaly
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/opencost/opencost/core/pkg/opencost"
+)
+
+// Components are the costs which an anomaly is attributed to. GPU cost counts
+// as CPU, and load balancer cost as network.
+var Components = []string{"CPU", "RAM", "PV", "Network"}
+
+// madScale makes the median absolute deviation comparable to the standard
+// deviation of normally distributed costs.
+const madScale = 1.4826
+
+// Options control which deviations are anomalies.
+type Options struct {
+	// Sensitivity is how many scaled median absolute deviations a cost must
+	// be from its baseline to be an anomaly.
+	Sensitivity float64
+
+	// BaselineSteps is how many preceding steps the baseline is the median
+	// of.
+	BaselineSteps int
+
+	// MinBaselineSteps is how many preceding steps an aggregate must have
+	// before its cost is compared with its baseline.
+	MinBaselineSteps int
+
+	// MinDeviation is the smallest deviation considered, as a fraction of
+	// the baseline, so that aggregates with a steady cost aren't flagged
+	// for negligible changes.
+	MinDeviation float64
+
+	// MinCost is the smallest absolute deviation considered.
+	MinCost float64
+}
+
+// DefaultOptions flag deviations of three scaled MADs from the median of the
+// past week of daily steps.
+var DefaultOptions = Options{
+	Sensitivity:      3,
+	BaselineSteps:    7,
+	MinBaselineSteps: 3,
+	MinDeviation:     0.1,
+	MinCost:          0.01,
+}
+
+// Anomaly is a step in which the cost of an aggregate deviated from its
+// baseline.
+type Anomaly struct {
+	Name  string
+	Start time.Time
+	End   time.Time
+
+	Cost     float64
+	Baseline float64
+
+	// Score is the deviation in scaled median absolute deviations, which is
+	// negative if the cost dropped.
+	Score float64
+
+	// Component is the one of Components which deviated the most in the
+	// direction of the total, and ComponentChange how much it deviated.
+	Component       string
+	ComponentChange float64
+}
+
+// Change is the deviation of the cost from the baseline.
+func (a Anomaly) Change() float64 {
+	return a.Cost - a.Baseline
+}
+
+type point struct {
+	start, end time.Time
+	costs      [4]float64
+}
+
+func (p point) total() float64 {
+	return p.costs[0] + p.costs[1] + p.costs[2] + p.costs[3]
+}
+
+func costs(alloc opencost.Allocation) [4]float64 {
+	return [4]float64{
+		alloc.CPUTotalCost() + alloc.GPUTotalCost(),
+		alloc.RAMTotalCost(),
+		alloc.PVTotalCost(),
+		alloc.NetworkTotalCost() + alloc.LoadBalancerTotalCost(),
+	}
+}
+
+// Detect compares the cost of each aggregate in each step with the rolling
+// median of its preceding steps, given allocation sets in chronological
+// order, and returns the anomalies sorted by start and by descending size.
+// Idle and unallocated allocations are ignored. An aggregate missing from a
+// step after its first appearance costs nothing in it.
+func Detect(sets []map[string]opencost.Allocation, opts Options) []Anomaly {
+	series := map[string][]point{}
+	for _, set := range sets {
+		var start, end time.Time
+		for _, alloc := range set {
+			start, end = alloc.Start, alloc.End
+			break
+		}
+
+		// Aggregates which appeared before but are missing now cost nothing.
+		for name, points := range series {
+			if _, ok := set[name]; !ok {
+				series[name] = append(points, point{start: start, end: end})
+			}
+		}
+
+		for name, alloc := range set {
+			if alloc.IsIdle() || alloc.IsUnallocated() {
+				continue
+			}
+			series[name] = append(series[name], point{start: alloc.Start, end: alloc.End, costs: costs(alloc)})
+		}
+	}
+
+	var anomalies []Anomaly
+	for name, points := range series {
+		for i := opts.MinBaselineSteps; i < len(points); i++ {
+			from := max(i-opts.BaselineSteps, 0)
+			if a, ok := detectAt(name, points[from:i], points[i], opts); ok {
+				anomalies = append(anomalies, a)
+			}
+		}
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		a, b := anomalies[i], anomalies[j]
+		if !a.Start.Equal(b.Start) {
+			return a.Start.Before(b.Start)
+		}
+		if math.Abs(a.Change()) != math.Abs(b.Change()) {
+			return math.Abs(a.Change()) > math.Abs(b.Change())
+		}
+		return a.Name < b.Name
+	})
+	return anomalies
+}
+
+// TrimPartialStep removes the last set if its window is shorter than the
+// first's, as it is when a window ends now, so that a step in progress isn't
+// mistaken for a drop in cost.
+func TrimPartialStep(sets []map[string]opencost.Allocation) []map[string]opencost.Allocation {
+	if len(sets) < 2 {
+		return sets
+	}
+	if stepDuration(sets[len(sets)-1]) < stepDuration(sets[0]) {
+		return sets[:len(sets)-1]
+	}
+	return sets
+}
+
+func stepDuration(set map[string]opencost.Allocation) time.Duration {
+	for _, alloc := range set {
+		return alloc.Window.Duration()
+	}
+	return 0
+}
+
+func detectAt(name string, baseline []point, p point, opts Options) (Anomaly, bool) {
+	totals := make([]float64, len(baseline))
+	for i, b := range baseline {
+		totals[i] = b.total()
+	}
+	median := Median(totals)
+	change := p.total() - median
+	if math.Abs(change) < opts.MinCost {
+		return Anomaly{}, false
+	}
+
+	deviation := math.Max(madScale*MAD(totals), opts.MinDeviation*math.Abs(median))
+	if deviation <= 0 {
+		// Against a baseline of nothing, score by the smallest cost
+		// considered.
+		deviation = opts.MinCost
+	}
+	score := change / deviation
+	if math.Abs(score) < opts.Sensitivity {
+		return Anomaly{}, false
+	}
+
+	a := Anomaly{
+		Name:     name,
+		Start:    p.start,
+		End:      p.end,
+		Cost:     p.total(),
+		Baseline: median,
+		Score:    score,
+	}
+	for c := range Components {
+		values := make([]float64, len(baseline))
+		for i, b := range baseline {
+			values[i] = b.costs[c]
+		}
+		componentChange := p.costs[c] - Median(values)
+		if a.Component == "" || componentChange*sign(change) > a.ComponentChange*sign(change) {
+			a.Component = Components[c]
+			a.ComponentChange = componentChange
+		}
+	}
+	return a, true
+}
+
+func sign(f float64) float64 {
+	if f < 0 {
+		return -1
+	}
+	return 1
+}
+
+// Median is the median of values, or 0 if there are none.
+func Median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// MAD is the median absolute deviation of values from their median.
+func MAD(values []float64) float64 {
+	median := Median(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - median)
+	}
+	return Median(deviations)
+}
@@ -0,0 +1,110 @@
+package anomaly
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/core/pkg/opencost"
+)
+
+var day = time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+func alloc(name string, i int, cpu, ram, pv, network float64) opencost.Allocation {
+	start := day.AddDate(0, 0, i)
+	return opencost.Allocation{
+		Name:        name,
+		Start:       start,
+		End:         start.AddDate(0, 0, 1),
+		CPUCost:     cpu,
+		RAMCost:     ram,
+		PVs:         opencost.PVAllocations{{Name: "pv"}: {Cost: pv}},
+		NetworkCost: network,
+	}
+}
+
+func TestMedianAndMAD(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 100}
+	if Median(values) != 3 || MAD(values) != 1 {
+		t.Errorf("expected median 3 and MAD 1, got %f and %f", Median(values), MAD(values))
+	}
+	if Median([]float64{4, 1, 3, 2}) != 2.5 || Median(nil) != 0 {
+		t.Errorf("unexpected median of an even or empty set")
+	}
+	if values[4] != 100 {
+		t.Errorf("expected values to be unchanged, got %v", values)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	var sets []map[string]opencost.Allocation
+	for i := 0; i < 10; i++ {
+		set := map[string]opencost.Allocation{
+			"web":      alloc("web", i, 10+float64(i%2), 5, 1, 0.5),
+			"__idle__": alloc("__idle__", i, 100*float64(i), 0, 0, 0),
+		}
+		if i < 8 {
+			set["batch"] = alloc("batch", i, 2, 2, 0, 0)
+		}
+		sets = append(sets, set)
+	}
+
+	// Day 6 web's network cost spikes. From day 8, batch is gone.
+	sets[6]["web"] = alloc("web", 6, 10, 5, 1, 20.5)
+
+	anomalies := Detect(sets, DefaultOptions)
+	if len(anomalies) != 3 {
+		t.Fatalf("expected 3 anomalies, got %+v", anomalies)
+	}
+
+	spike := anomalies[0]
+	if spike.Name != "web" || !spike.Start.Equal(day.AddDate(0, 0, 6)) || spike.Component != "Network" {
+		t.Errorf("unexpected spike %+v", spike)
+	}
+	if math.Abs(spike.Change()-19.5) > 1e-9 || math.Abs(spike.ComponentChange-20) > 1e-9 || spike.Score < 3 {
+		t.Errorf("expected a network spike of 20, got %+v", spike)
+	}
+
+	for _, drop := range anomalies[1:] {
+		if drop.Name != "batch" || drop.Cost != 0 || drop.Baseline != 4 || drop.Score >= 0 {
+			t.Errorf("expected batch to drop to nothing, got %+v", drop)
+		}
+	}
+	if !anomalies[2].Start.Equal(day.AddDate(0, 0, 9)) {
+		t.Errorf("expected the drop to be detected on each missing day, got %+v", anomalies[2])
+	}
+}
+
+func TestDetectSensitivity(t *testing.T) {
+	var sets []map[string]opencost.Allocation
+	for i, cpu := range []float64{10, 12, 9, 11, 10, 14} {
+		sets = append(sets, map[string]opencost.Allocation{"web": alloc("web", i, cpu, 0, 0, 0)})
+	}
+
+	if anomalies := Detect(sets, DefaultOptions); len(anomalies) != 0 {
+		t.Errorf("expected ordinary variation not to be an anomaly, got %+v", anomalies)
+	}
+
+	opts := DefaultOptions
+	opts.Sensitivity = 1
+	if anomalies := Detect(sets, opts); len(anomalies) != 1 || anomalies[0].Component != "CPU" {
+		t.Errorf("expected an anomaly at a lower sensitivity, got %+v", anomalies)
+	}
+}
+
+func TestTrimPartialStep(t *testing.T) {
+	set := func(start time.Time, d time.Duration) map[string]opencost.Allocation {
+		return map[string]opencost.Allocation{"web": {Window: opencost.NewClosedWindow(start, start.Add(d))}}
+	}
+	sets := []map[string]opencost.Allocation{
+		set(day, 24*time.Hour),
+		set(day.AddDate(0, 0, 1), 24*time.Hour),
+		set(day.AddDate(0, 0, 2), 5*time.Hour),
+	}
+	if got := TrimPartialStep(sets); len(got) != 2 {
+		t.Errorf("expected the partial step to be trimmed, got %d sets", len(got))
+	}
+	if got := TrimPartialStep(sets[:2]); len(got) != 2 {
+		t.Errorf("expected complete steps to be kept, got %d sets", len(got))
+	}
+}
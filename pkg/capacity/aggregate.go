@@ -0,0 +1,185 @@
+package capacity
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubecost/kubectl-cost/pkg/query"
+
+	"github.com/opencost/opencost/core/pkg/opencost"
+)
+
+// UnallocatedName names the group of nodes which lack the label they are
+// aggregated by, as in Kubecost's APIs.
+const UnallocatedName = "__unallocated__"
+
+// NodeAggregation describes how node assets are grouped: by a node label, by
+// instance type or by provider.
+type NodeAggregation struct {
+	// Label is the node label to group by, if any.
+	Label string
+
+	property string
+}
+
+// ParseNodeAggregation parses "label:<name>", "instance-type" or "provider".
+func ParseNodeAggregation(s string) (NodeAggregation, error) {
+	switch {
+	case strings.HasPrefix(s, "label:"):
+		label := strings.TrimPrefix(s, "label:")
+		if label == "" {
+			return NodeAggregation{}, fmt.Errorf("aggregation '%s' is missing a label name", s)
+		}
+		return NodeAggregation{Label: label}, nil
+	case s == "instance-type" || s == "nodeType":
+		return NodeAggregation{property: "instance-type"}, nil
+	case s == "provider":
+		return NodeAggregation{property: "provider"}, nil
+	}
+	return NodeAggregation{}, fmt.Errorf("unknown aggregation '%s', must be one of: label:<name>, instance-type, provider", s)
+}
+
+// String is a human-readable name for the aggregation.
+func (agg NodeAggregation) String() string {
+	if agg.Label != "" {
+		return agg.Label
+	}
+	if agg.property == "instance-type" {
+		return "Instance Type"
+	}
+	return "Provider"
+}
+
+func (agg NodeAggregation) key(a query.AssetNode) string {
+	var value string
+	switch {
+	case agg.Label != "":
+		value = LabelValue(a, agg.Label)
+	case agg.property == "instance-type":
+		value = a.NodeType
+	default:
+		value = a.Properties.Provider
+	}
+	if value == "" {
+		return UnallocatedName
+	}
+	return value
+}
+
+// LabelValue returns the value of a label of a node asset, which may have been
+// sanitized like a Prometheus label name and prefixed with "label_".
+func LabelValue(a query.AssetNode, label string) string {
+	key := strings.NewReplacer(".", "_", "/", "_", "-", "_").Replace(label)
+	for _, k := range []string{label, key, "label_" + key} {
+		if v, ok := a.Labels[k]; ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// AggregateNodeAssets groups node assets by cluster and aggregation. In each
+// group, Properties.Name is the group's value and capacity, hours and costs
+// are summed. The node type is empty if it is mixed, and Minutes is the
+// longest of the group's nodes, so that projecting a group's cost counts
+// nodes which only ran for part of the window accordingly.
+//
+// Preemptible, Discount and the CPU and RAM breakdowns become averages,
+// weighted by node, by cost before discount, and by core-hours and
+// byte-hours respectively.
+func AggregateNodeAssets(assets map[string]query.AssetNode, agg NodeAggregation) map[string]query.AssetNode {
+	type group struct {
+		node          query.AssetNode
+		count         float64
+		discountBase  float64
+		discountTotal float64
+	}
+
+	groups := map[string]*group{}
+	keys := make([]string, 0, len(assets))
+	for k := range assets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		a := assets[k]
+		name := agg.key(a)
+		key := NodeKey(a.Properties.Cluster, name)
+
+		g, ok := groups[key]
+		if !ok {
+			g = &group{}
+			g.node.Type = a.Type
+			g.node.Properties.Cluster = a.Properties.Cluster
+			g.node.Properties.Name = name
+			g.node.Properties.Provider = a.Properties.Provider
+			g.node.NodeType = a.NodeType
+			groups[key] = g
+		}
+		n := &g.node
+		if n.NodeType != a.NodeType {
+			n.NodeType = ""
+		}
+		if n.Properties.Provider != a.Properties.Provider {
+			n.Properties.Provider = ""
+		}
+		if a.Minutes > n.Minutes {
+			n.Minutes = a.Minutes
+			n.Start = a.Start
+			n.End = a.End
+		}
+
+		// Breakdowns are weighted by core-hours and byte-hours before they
+		// are summed.
+		n.CPUBreakdown = weightedBreakdown(n.CPUBreakdown, n.CPUCoreHours, a.CPUBreakdown, a.CPUCoreHours)
+		n.RAMBreakdown = weightedBreakdown(n.RAMBreakdown, n.RAMByteHours, a.RAMBreakdown, a.RAMByteHours)
+
+		n.CpuCores += a.CpuCores
+		n.RamBytes += a.RamBytes
+		n.GPUCount += a.GPUCount
+		n.CPUCoreHours += a.CPUCoreHours
+		n.RAMByteHours += a.RAMByteHours
+		n.GPUHours += a.GPUHours
+		n.CPUCost += a.CPUCost
+		n.RAMCost += a.RAMCost
+		n.GPUCost += a.GPUCost
+		n.Adjustment += a.Adjustment
+		n.TotalCost += a.TotalCost
+
+		g.count++
+		n.Preemptible += (a.Preemptible - n.Preemptible) / g.count
+
+		// Costs are net of the discount, so the undiscounted cost is
+		// cost / (1 - discount).
+		cost := a.CPUCost + a.RAMCost
+		if a.Discount < 1 && cost > 0 {
+			base := cost / (1 - a.Discount)
+			g.discountBase += base
+			g.discountTotal += base * a.Discount
+		}
+		if g.discountBase > 0 {
+			n.Discount = g.discountTotal / g.discountBase
+		}
+	}
+
+	aggregated := map[string]query.AssetNode{}
+	for key, g := range groups {
+		aggregated[key] = g.node
+	}
+	return aggregated
+}
+
+func weightedBreakdown(x opencost.Breakdown, xWeight float64, y opencost.Breakdown, yWeight float64) opencost.Breakdown {
+	total := xWeight + yWeight
+	if total <= 0 {
+		return x
+	}
+	return opencost.Breakdown{
+		Idle:   (x.Idle*xWeight + y.Idle*yWeight) / total,
+		Other:  (x.Other*xWeight + y.Other*yWeight) / total,
+		System: (x.System*xWeight + y.System*yWeight) / total,
+		User:   (x.User*xWeight + y.User*yWeight) / total,
+	}
+}
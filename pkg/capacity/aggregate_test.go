@@ -0,0 +1,64 @@
+package capacity
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kubecost/kubectl-cost/pkg/query"
+)
+
+func TestParseNodeAggregation(t *testing.T) {
+	agg, err := ParseNodeAggregation("label:team")
+	if err != nil || agg.Label != "team" || agg.String() != "team" {
+		t.Errorf("unexpected label aggregation %+v, %v", agg, err)
+	}
+	for _, s := range []string{"instance-type", "provider"} {
+		if _, err := ParseNodeAggregation(s); err != nil {
+			t.Errorf("%s: %s", s, err)
+		}
+	}
+	for _, s := range []string{"label:", "zone"} {
+		if _, err := ParseNodeAggregation(s); err == nil {
+			t.Errorf("%s: expected an error", s)
+		}
+	}
+}
+
+func TestAggregateNodeAssets(t *testing.T) {
+	spot := asset("one", "node-b", "n2-standard-4", map[string]string{"label_team": "web"}, 4, 16, 0.1)
+	spot.Preemptible = 1
+	spot.CPUCost, spot.CPUCoreHours = 0.06, 4
+	spot.CPUBreakdown.Idle = 0.5
+	spot.Discount = 0.5
+	spot.Minutes = 30
+
+	onDemand := asset("one", "node-a", "n2-standard-8", map[string]string{"label_team": "web"}, 8, 32, 0.4)
+	onDemand.CPUCost, onDemand.CPUCoreHours = 0.12, 12
+	onDemand.CPUBreakdown.Idle = 0.1
+
+	other := asset("one", "node-c", "n2-standard-4", nil, 4, 16, 0.2)
+
+	agg, _ := ParseNodeAggregation("label:team")
+	groups := AggregateNodeAssets(map[string]query.AssetNode{"a": onDemand, "b": spot, "c": other}, agg)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %+v", groups)
+	}
+
+	web := groups["one/web"]
+	if web.Properties.Name != "web" || web.NodeType != "" || web.CpuCores != 12 || web.Minutes != 60 {
+		t.Errorf("unexpected web group %+v", web)
+	}
+	if math.Abs(web.TotalCost-0.5) > 1e-9 {
+		t.Errorf("expected summed cost 0.5, got %f", web.TotalCost)
+	}
+	if web.Preemptible != 0.5 {
+		t.Errorf("expected half of the nodes preemptible, got %f", web.Preemptible)
+	}
+	if math.Abs(web.CPUBreakdown.Idle-0.2) > 1e-9 {
+		t.Errorf("expected idle weighted by core-hours to be 0.2, got %f", web.CPUBreakdown.Idle)
+	}
+
+	if _, ok := groups["one/"+UnallocatedName]; !ok {
+		t.Errorf("expected unlabeled node to be unallocated, got %+v", groups)
+	}
+}
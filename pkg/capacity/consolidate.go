@@ -0,0 +1,220 @@
+package capacity
+
+import (
+	"math"
+	"sort"
+)
+
+// ConsolidationOptions control how much headroom recommendations leave.
+type ConsolidationOptions struct {
+	// TargetUtilization is the fraction of CPU and RAM which may be
+	// requested on the recommended nodes, e.g. 0.8.
+	TargetUtilization float64
+
+	// MinNodes is the smallest number of nodes recommended for a pool.
+	MinNodes int
+}
+
+// Pool is a set of nodes of a cluster which share a pool label, or node type.
+type Pool struct {
+	Cluster  string
+	Name     string
+	NodeType string
+	Nodes    []Node
+
+	CPUCores          float64
+	RAMBytes          float64
+	CPUCoresRequested float64
+	RAMBytesRequested float64
+	MonthlyRate       float64
+}
+
+// CPURequestUtilization is the fraction of the pool's CPU which is requested.
+func (p Pool) CPURequestUtilization() float64 {
+	return fraction(p.CPUCoresRequested, p.CPUCores)
+}
+
+// RAMRequestUtilization is the fraction of the pool's RAM which is requested.
+func (p Pool) RAMRequestUtilization() float64 {
+	return fraction(p.RAMBytesRequested, p.RAMBytes)
+}
+
+// Pools groups nodes by cluster and pool, sorted by cluster and name. The node
+// type of a pool with mixed node types is empty.
+func Pools(nodes []Node) []Pool {
+	byPool := map[string]*Pool{}
+	var keys []string
+	for _, n := range nodes {
+		key := NodeKey(n.Cluster, n.Pool)
+		p, ok := byPool[key]
+		if !ok {
+			p = &Pool{Cluster: n.Cluster, Name: n.Pool, NodeType: n.NodeType}
+			byPool[key] = p
+			keys = append(keys, key)
+		}
+		if p.NodeType != n.NodeType {
+			p.NodeType = ""
+		}
+		p.Nodes = append(p.Nodes, n)
+		p.CPUCores += n.CPUCores
+		p.RAMBytes += n.RAMBytes
+		p.CPUCoresRequested += n.CPUCoresRequested
+		p.RAMBytesRequested += n.RAMBytesRequested
+		p.MonthlyRate += n.MonthlyRate
+	}
+
+	sort.Strings(keys)
+	var pools []Pool
+	for _, key := range keys {
+		pools = append(pools, *byPool[key])
+	}
+	return pools
+}
+
+// NodeShape is the average capacity and cost of the nodes of one type.
+type NodeShape struct {
+	NodeType    string
+	CPUCores    float64
+	RAMBytes    float64
+	MonthlyRate float64
+}
+
+// Shapes averages nodes by cluster and node type. The result maps clusters to
+// the shapes of their node types, sorted by node type.
+func Shapes(nodes []Node) map[string][]NodeShape {
+	type sum struct {
+		NodeShape
+		count float64
+	}
+	sums := map[string]map[string]*sum{}
+	for _, n := range nodes {
+		if n.NodeType == "" {
+			continue
+		}
+		if sums[n.Cluster] == nil {
+			sums[n.Cluster] = map[string]*sum{}
+		}
+		s, ok := sums[n.Cluster][n.NodeType]
+		if !ok {
+			s = &sum{NodeShape: NodeShape{NodeType: n.NodeType}}
+			sums[n.Cluster][n.NodeType] = s
+		}
+		s.CPUCores += n.CPUCores
+		s.RAMBytes += n.RAMBytes
+		s.MonthlyRate += n.MonthlyRate
+		s.count++
+	}
+
+	shapes := map[string][]NodeShape{}
+	for cluster, byType := range sums {
+		for _, s := range byType {
+			shapes[cluster] = append(shapes[cluster], NodeShape{
+				NodeType:    s.NodeType,
+				CPUCores:    s.CPUCores / s.count,
+				RAMBytes:    s.RAMBytes / s.count,
+				MonthlyRate: s.MonthlyRate / s.count,
+			})
+		}
+		sort.Slice(shapes[cluster], func(i, j int) bool {
+			return shapes[cluster][i].NodeType < shapes[cluster][j].NodeType
+		})
+	}
+	return shapes
+}
+
+// Recommendation is the cheapest way found to run the requests of a pool.
+type Recommendation struct {
+	Pool
+
+	// RecommendedNodeType and RecommendedNodes are what the pool should
+	// consist of. If no cheaper option was found, they are the pool's
+	// current node type and count.
+	RecommendedNodeType string
+	RecommendedNodes    int
+
+	RecommendedMonthlyRate float64
+	MonthlySavings         float64
+}
+
+// Recommend finds the cheapest way to run the requests of each pool at the
+// target utilization: with fewer nodes of the same shape, or with nodes of
+// another type which already runs in the same cluster. It is based on total
+// requests only, so pods have to be small enough to fit the recommended
+// node type.
+func Recommend(pools []Pool, shapes map[string][]NodeShape, opts ConsolidationOptions) []Recommendation {
+	var recs []Recommendation
+	for _, p := range pools {
+		count := len(p.Nodes)
+		rec := Recommendation{
+			Pool:                   p,
+			RecommendedNodeType:    p.NodeType,
+			RecommendedNodes:       count,
+			RecommendedMonthlyRate: p.MonthlyRate,
+		}
+		if count == 0 {
+			recs = append(recs, rec)
+			continue
+		}
+
+		candidates := []NodeShape{{
+			NodeType:    p.NodeType,
+			CPUCores:    p.CPUCores / float64(count),
+			RAMBytes:    p.RAMBytes / float64(count),
+			MonthlyRate: p.MonthlyRate / float64(count),
+		}}
+		// Pools of mixed node types can only shrink, as it isn't known which
+		// of their nodes would be replaced.
+		if p.NodeType != "" {
+			for _, s := range shapes[p.Cluster] {
+				if s.NodeType != p.NodeType {
+					candidates = append(candidates, s)
+				}
+			}
+		}
+
+		for _, c := range candidates {
+			n := nodesNeeded(p, c, opts)
+			if n < 0 {
+				continue
+			}
+			cost := float64(n) * c.MonthlyRate
+			if cost < rec.RecommendedMonthlyRate {
+				rec.RecommendedNodeType = c.NodeType
+				rec.RecommendedNodes = n
+				rec.RecommendedMonthlyRate = cost
+			}
+		}
+		rec.MonthlySavings = p.MonthlyRate - rec.RecommendedMonthlyRate
+
+		recs = append(recs, rec)
+	}
+
+	sort.SliceStable(recs, func(i, j int) bool {
+		return recs[i].MonthlySavings > recs[j].MonthlySavings
+	})
+	return recs
+}
+
+// nodesNeeded is the number of nodes of a shape which fit the requests of a
+// pool at the target utilization, or -1 if the shape has no capacity.
+func nodesNeeded(p Pool, s NodeShape, opts ConsolidationOptions) int {
+	if s.CPUCores <= 0 || s.RAMBytes <= 0 {
+		return -1
+	}
+	target := opts.TargetUtilization
+	if target <= 0 {
+		target = 1
+	}
+	cpu := p.CPUCoresRequested / (s.CPUCores * target)
+	ram := p.RAMBytesRequested / (s.RAMBytes * target)
+
+	// Avoid rounding e.g. 2.0000000001 up to 3.
+	n := int(math.Ceil(math.Max(cpu, ram) - 1e-9))
+	if n < opts.MinNodes {
+		n = opts.MinNodes
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
@@ -0,0 +1,60 @@
+package capacity
+
+import (
+	"testing"
+)
+
+func TestRecommend(t *testing.T) {
+	node := func(name, pool, nodeType string, cpu, ramGiB, monthly, cpuRequested, ramGiBRequested float64) Node {
+		return Node{
+			Cluster:           "one",
+			Name:              name,
+			Pool:              pool,
+			NodeType:          nodeType,
+			CPUCores:          cpu,
+			RAMBytes:          ramGiB * 1024 * 1024 * 1024,
+			MonthlyRate:       monthly,
+			CPUCoresRequested: cpuRequested,
+			RAMBytesRequested: ramGiBRequested * 1024 * 1024 * 1024,
+		}
+	}
+	nodes := []Node{
+		// 4 large nodes with 6 cores and 12GiB requested in total.
+		node("large-1", "large", "large", 8, 32, 200, 2, 4),
+		node("large-2", "large", "large", 8, 32, 200, 2, 4),
+		node("large-3", "large", "large", 8, 32, 200, 1, 2),
+		node("large-4", "large", "large", 8, 32, 200, 1, 2),
+		// A busy pool of small nodes.
+		node("small-1", "small", "small", 2, 8, 50, 1.8, 6),
+		node("small-2", "small", "small", 2, 8, 50, 1.8, 6),
+	}
+
+	pools := Pools(nodes)
+	if len(pools) != 2 || pools[0].Name != "large" || len(pools[0].Nodes) != 4 {
+		t.Fatalf("unexpected pools %+v", pools)
+	}
+	if pools[0].CPURequestUtilization() != 6.0/32 {
+		t.Errorf("unexpected CPU utilization %f", pools[0].CPURequestUtilization())
+	}
+
+	recs := Recommend(pools, Shapes(nodes), ConsolidationOptions{TargetUtilization: 0.8, MinNodes: 1})
+	if len(recs) != 2 {
+		t.Fatalf("expected 2 recommendations, got %+v", recs)
+	}
+
+	// 6 cores at 80% need 7.5 cores: 1 large node for 200, or 4 small nodes
+	// (8 cores, 32GiB) for 200, so the pool shrinks to a single large node.
+	large := recs[0]
+	if large.Name != "large" || large.RecommendedNodeType != "large" || large.RecommendedNodes != 1 {
+		t.Errorf("unexpected recommendation for the large pool: %+v", large)
+	}
+	if large.MonthlySavings != 600 {
+		t.Errorf("expected savings of 600, got %f", large.MonthlySavings)
+	}
+
+	// 3.6 cores at 80% need 4.5 cores, so the small pool can't shrink.
+	small := recs[1]
+	if small.RecommendedNodes != 2 || small.MonthlySavings != 0 {
+		t.Errorf("expected the small pool to be kept, got %+v", small)
+	}
+}
@@ -0,0 +1,207 @@
+// Package capacity relates the capacity and cost of nodes to what the
+// workloads scheduled on them request and use.
+package capacity
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/kubecost/kubectl-cost/pkg/query"
+
+	"github.com/opencost/opencost/core/pkg/opencost"
+	"github.com/opencost/opencost/core/pkg/util/timeutil"
+)
+
+// poolLabels are the node labels which name a node's pool, in order of
+// preference. Asset labels are sanitized like Prometheus label names.
+var poolLabels = []string{
+	"cloud.google.com/gke-nodepool",
+	"eks.amazonaws.com/nodegroup",
+	"kubernetes.azure.com/agentpool",
+	"karpenter.sh/nodepool",
+	"karpenter.sh/provisioner-name",
+	"node.kubernetes.io/pool",
+}
+
+// Node is a node with the resources which workloads requested and used on it
+// over a window.
+type Node struct {
+	Cluster  string
+	Name     string
+	NodeType string
+
+	// Pool is the node pool the node belongs to, or its node type if it
+	// has no pool label.
+	Pool string
+
+	CPUCores    float64
+	RAMBytes    float64
+	MonthlyRate float64
+
+	// Minutes is how long the node ran during the window.
+	Minutes float64
+
+	// Averages over the window.
+	CPUCoresRequested float64
+	RAMBytesRequested float64
+	CPUCoresUsed      float64
+	RAMBytesUsed      float64
+
+	// IdleMonthlyRate is the part of MonthlyRate which no allocation's CPU,
+	// GPU or RAM cost accounts for.
+	IdleMonthlyRate float64
+
+	// TopNamespaces are the namespaces with the highest cost on the node,
+	// if added by AddTopNamespaces.
+	TopNamespaces []NamespaceCost
+}
+
+// NamespaceCost is the monthly rate of a namespace's CPU, GPU and RAM cost on
+// a node.
+type NamespaceCost struct {
+	Namespace   string
+	MonthlyRate float64
+}
+
+// CPURequestUtilization is the fraction of the node's CPU which is requested.
+func (n Node) CPURequestUtilization() float64 {
+	return fraction(n.CPUCoresRequested, n.CPUCores)
+}
+
+// RAMRequestUtilization is the fraction of the node's RAM which is requested.
+func (n Node) RAMRequestUtilization() float64 {
+	return fraction(n.RAMBytesRequested, n.RAMBytes)
+}
+
+// CPUUsageUtilization is the fraction of the node's CPU which is used.
+func (n Node) CPUUsageUtilization() float64 {
+	return fraction(n.CPUCoresUsed, n.CPUCores)
+}
+
+// RAMUsageUtilization is the fraction of the node's RAM which is used.
+func (n Node) RAMUsageUtilization() float64 {
+	return fraction(n.RAMBytesUsed, n.RAMBytes)
+}
+
+func fraction(x, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return x / total
+}
+
+// monthlyRate projects a cost over the minutes the node ran to a month.
+func (n Node) monthlyRate(cost float64) float64 {
+	if n.Minutes <= 0 {
+		return 0
+	}
+	return cost / (n.Minutes / 60) * timeutil.HoursPerMonth
+}
+
+// WindowCost converts a monthly rate of the node back to its cost over the
+// minutes the node ran.
+func (n Node) WindowCost(monthlyRate float64) float64 {
+	return monthlyRate / timeutil.HoursPerMonth * n.Minutes / 60
+}
+
+func computeCost(alloc opencost.Allocation) float64 {
+	return alloc.CPUTotalCost() + alloc.GPUTotalCost() + alloc.RAMTotalCost()
+}
+
+// AddTopNamespaces sets the TopNamespaces of nodes to the count namespaces
+// with the highest cost on each, from allocations aggregated by cluster, node
+// and namespace.
+func AddTopNamespaces(nodes []Node, allocations map[string]opencost.Allocation, count int) {
+	byNode := map[string][]NamespaceCost{}
+	minutes := map[string]float64{}
+	for i := range nodes {
+		minutes[NodeKey(nodes[i].Cluster, nodes[i].Name)] = nodes[i].Minutes
+	}
+	for _, alloc := range allocations {
+		if alloc.Properties == nil || alloc.Properties.Node == "" || alloc.Properties.Namespace == "" {
+			continue
+		}
+		key := NodeKey(alloc.Properties.Cluster, alloc.Properties.Node)
+		n := Node{Minutes: minutes[key]}
+		byNode[key] = append(byNode[key], NamespaceCost{
+			Namespace:   alloc.Properties.Namespace,
+			MonthlyRate: n.monthlyRate(computeCost(alloc)),
+		})
+	}
+
+	for i := range nodes {
+		top := byNode[NodeKey(nodes[i].Cluster, nodes[i].Name)]
+		sort.Slice(top, func(a, b int) bool {
+			if top[a].MonthlyRate != top[b].MonthlyRate {
+				return top[a].MonthlyRate > top[b].MonthlyRate
+			}
+			return top[a].Namespace < top[b].Namespace
+		})
+		if len(top) > count {
+			top = top[:count]
+		}
+		nodes[i].TopNamespaces = top
+	}
+}
+
+// NodeKey identifies a node across clusters.
+func NodeKey(cluster, name string) string {
+	return fmt.Sprintf("%s/%s", cluster, name)
+}
+
+// Join combines node assets with allocations aggregated by cluster and node,
+// sorted by cluster and name. Nodes without allocations have no requests or
+// usage.
+func Join(assets []query.AssetNode, allocations map[string]opencost.Allocation) []Node {
+	byNode := map[string]opencost.Allocation{}
+	for _, alloc := range allocations {
+		if alloc.Properties == nil || alloc.Properties.Node == "" {
+			continue
+		}
+		byNode[NodeKey(alloc.Properties.Cluster, alloc.Properties.Node)] = alloc
+	}
+
+	var nodes []Node
+	for _, a := range assets {
+		n := Node{
+			Cluster:  a.Properties.Cluster,
+			Name:     a.Properties.Name,
+			NodeType: a.NodeType,
+			Pool:     PoolOf(a),
+			CPUCores: a.CpuCores,
+			RAMBytes: a.RamBytes,
+		}
+		n.Minutes = a.Minutes
+		n.MonthlyRate = n.monthlyRate(a.TotalCost)
+		n.IdleMonthlyRate = n.MonthlyRate
+		if alloc, ok := byNode[NodeKey(n.Cluster, n.Name)]; ok {
+			n.CPUCoresRequested = alloc.CPUCoreRequestAverage
+			n.RAMBytesRequested = alloc.RAMBytesRequestAverage
+			n.CPUCoresUsed = alloc.CPUCoreUsageAverage
+			n.RAMBytesUsed = alloc.RAMBytesUsageAverage
+			n.IdleMonthlyRate = n.monthlyRate(math.Max(a.TotalCost-computeCost(alloc), 0))
+		}
+		nodes = append(nodes, n)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return NodeKey(nodes[i].Cluster, nodes[i].Name) < NodeKey(nodes[j].Cluster, nodes[j].Name)
+	})
+	return nodes
+}
+
+// PoolOf returns the node pool of a node asset, as reported by Kubecost or
+// from the labels set by managed Kubernetes services and Karpenter, or its
+// node type if it has none.
+func PoolOf(a query.AssetNode) string {
+	if a.Pool != "" {
+		return a.Pool
+	}
+	for _, label := range poolLabels {
+		if pool := LabelValue(a, label); pool != "" {
+			return pool
+		}
+	}
+	return a.NodeType
+}
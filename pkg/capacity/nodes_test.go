@@ -0,0 +1,92 @@
+package capacity
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/kubecost/kubectl-cost/pkg/query"
+
+	"github.com/opencost/opencost/core/pkg/opencost"
+)
+
+func asset(cluster, name, nodeType string, labels map[string]string, cpu, ramGiB, hourly float64) query.AssetNode {
+	return query.AssetNode{
+		Properties: opencost.AssetProperties{Cluster: cluster, Name: name},
+		Labels:     labels,
+		NodeType:   nodeType,
+		CpuCores:   cpu,
+		RamBytes:   ramGiB * 1024 * 1024 * 1024,
+		Minutes:    60,
+		TotalCost:  hourly,
+	}
+}
+
+func TestJoin(t *testing.T) {
+	assets := []query.AssetNode{
+		asset("one", "node-b", "n2-standard-4", map[string]string{"label_cloud_google_com_gke_nodepool": "default-pool"}, 4, 16, 0.2),
+		asset("one", "node-a", "n2-standard-4", nil, 4, 16, 0.2),
+	}
+	allocations := map[string]opencost.Allocation{
+		"one/node-b": {
+			Properties:             &opencost.AllocationProperties{Cluster: "one", Node: "node-b"},
+			CPUCoreRequestAverage:  1,
+			RAMBytesRequestAverage: 8 * 1024 * 1024 * 1024,
+			CPUCoreUsageAverage:    0.5,
+		},
+	}
+
+	nodes := Join(assets, allocations)
+	if len(nodes) != 2 || nodes[0].Name != "node-a" {
+		t.Fatalf("expected nodes sorted by name, got %+v", nodes)
+	}
+	if nodes[0].Pool != "n2-standard-4" || nodes[1].Pool != "default-pool" {
+		t.Errorf("unexpected pools %s and %s", nodes[0].Pool, nodes[1].Pool)
+	}
+	if nodes[0].CPUCoresRequested != 0 {
+		t.Errorf("expected no requests on node-a, got %f", nodes[0].CPUCoresRequested)
+	}
+
+	b := nodes[1]
+	if b.CPURequestUtilization() != 0.25 || b.RAMRequestUtilization() != 0.5 || b.CPUUsageUtilization() != 0.125 {
+		t.Errorf("unexpected utilization of node-b: %+v", b)
+	}
+	if b.MonthlyRate != 0.2*730 {
+		t.Errorf("expected a monthly rate of %f, got %f", 0.2*730, b.MonthlyRate)
+	}
+}
+
+func TestIdleAndTopNamespaces(t *testing.T) {
+	assets := []query.AssetNode{asset("one", "node-a", "n2-standard-4", nil, 4, 16, 1)}
+	allocations := map[string]opencost.Allocation{
+		"one/node-a": {
+			Properties: &opencost.AllocationProperties{Cluster: "one", Node: "node-a"},
+			CPUCost:    0.4,
+			RAMCost:    0.2,
+		},
+	}
+	nodes := Join(assets, allocations)
+	if math.Abs(nodes[0].IdleMonthlyRate-0.4*730) > 1e-9 {
+		t.Errorf("expected idle monthly rate of %f, got %f", 0.4*730, nodes[0].IdleMonthlyRate)
+	}
+
+	byNamespace := map[string]opencost.Allocation{}
+	for ns, cost := range map[string]float64{"web": 0.3, "db": 0.2, "jobs": 0.05, "monitoring": 0.05} {
+		byNamespace["one/node-a/"+ns] = opencost.Allocation{
+			Properties: &opencost.AllocationProperties{Cluster: "one", Node: "node-a", Namespace: ns},
+			CPUCost:    cost,
+		}
+	}
+	AddTopNamespaces(nodes, byNamespace, 3)
+
+	var top []string
+	for _, ns := range nodes[0].TopNamespaces {
+		top = append(top, ns.Namespace)
+	}
+	if strings.Join(top, ",") != "web,db,jobs" {
+		t.Errorf("expected web, db and jobs, got %v", top)
+	}
+	if math.Abs(nodes[0].TopNamespaces[0].MonthlyRate-0.3*730) > 1e-9 {
+		t.Errorf("expected web to cost %f, got %f", 0.3*730, nodes[0].TopNamespaces[0].MonthlyRate)
+	}
+}
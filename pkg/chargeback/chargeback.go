@@ -0,0 +1,174 @@
+// Package chargeback builds invoice-style reports of what groups of workloads,
+// such as teams, cost over a calendar month or quarter.
+package chargeback
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opencost/opencost/core/pkg/opencost"
+
+	"github.com/kubecost/kubectl-cost/pkg/period"
+)
+
+// UnallocatedName names the group of namespaces which lack the label or
+// annotation they are grouped by, as in Kubecost's APIs.
+const UnallocatedName = "__unallocated__"
+
+// IdleName names the group holding idle cost which could not be shared,
+// because no allocation of its cluster had compute cost.
+const IdleName = "__idle__"
+
+// Costs are the parts of a chargeback total.
+type Costs struct {
+	// Compute is CPU, GPU and RAM cost.
+	Compute float64
+
+	// Storage is persistent volume cost.
+	Storage float64
+
+	// Network is network and load balancer cost.
+	Network float64
+
+	// Shared is the share of shared namespaces, labels and overhead.
+	Shared float64
+
+	// Idle is the share of the idle cost of the cluster, in proportion to
+	// compute cost.
+	Idle float64
+}
+
+// Total is the sum of all costs.
+func (c Costs) Total() float64 {
+	return c.Compute + c.Storage + c.Network + c.Shared + c.Idle
+}
+
+func (c *Costs) add(o Costs) {
+	c.Compute += o.Compute
+	c.Storage += o.Storage
+	c.Network += o.Network
+	c.Shared += o.Shared
+	c.Idle += o.Idle
+}
+
+// LineItem is what a namespace of a cluster costs a group.
+type LineItem struct {
+	Cluster   string
+	Namespace string
+	Costs
+}
+
+// Group is a team, or whatever allocations are grouped by, with its line
+// items sorted by descending total.
+type Group struct {
+	Name      string
+	LineItems []LineItem
+	Costs
+}
+
+// Report is the chargeback report of a period, with groups sorted by
+// descending total.
+type Report struct {
+	Period period.Period
+	By     string
+	Groups []Group
+	Costs
+}
+
+// Build builds a report from allocations accumulated over the period and
+// aggregated by cluster, by and namespace, which include unshared idle
+// allocations by cluster. Out-of-cluster (external) costs are not included.
+func Build(p period.Period, by string, allocations map[string]opencost.Allocation) (Report, error) {
+	type key struct{ cluster, group, namespace string }
+
+	items := map[key]*LineItem{}
+	idleByCluster := map[string]float64{}
+	computeByCluster := map[string]float64{}
+
+	for name, alloc := range allocations {
+		if alloc.IsIdle() {
+			cluster := strings.Split(name, "/")[0]
+			if alloc.Properties != nil && alloc.Properties.Cluster != "" {
+				cluster = alloc.Properties.Cluster
+			}
+			idleByCluster[cluster] += alloc.CPUTotalCost() + alloc.GPUTotalCost() + alloc.RAMTotalCost()
+			continue
+		}
+
+		// Label and annotation values may contain "/", so only the cluster
+		// and namespace are split off, from either end of the name.
+		first, last := strings.Index(name, "/"), strings.LastIndex(name, "/")
+		if first < 0 || first == last {
+			return Report{}, fmt.Errorf("allocation '%s' is not aggregated by cluster, %s and namespace", name, by)
+		}
+		k := key{cluster: name[:first], group: name[first+1 : last], namespace: name[last+1:]}
+		if strings.Contains(k.group, "__unallocated__") {
+			k.group = UnallocatedName
+		}
+
+		item, ok := items[k]
+		if !ok {
+			item = &LineItem{Cluster: k.cluster, Namespace: k.namespace}
+			items[k] = item
+		}
+		item.add(Costs{
+			Compute: alloc.CPUTotalCost() + alloc.GPUTotalCost() + alloc.RAMTotalCost(),
+			Storage: alloc.PVTotalCost(),
+			Network: alloc.NetworkTotalCost() + alloc.LoadBalancerTotalCost(),
+			Shared:  alloc.SharedTotalCost(),
+		})
+		computeByCluster[k.cluster] += alloc.CPUTotalCost() + alloc.GPUTotalCost() + alloc.RAMTotalCost()
+	}
+
+	groups := map[string]*Group{}
+	for k, item := range items {
+		if compute := computeByCluster[k.cluster]; compute > 0 {
+			item.Idle = idleByCluster[k.cluster] * item.Compute / compute
+		}
+		g, ok := groups[k.group]
+		if !ok {
+			g = &Group{Name: k.group}
+			groups[k.group] = g
+		}
+		g.LineItems = append(g.LineItems, *item)
+		g.add(item.Costs)
+	}
+
+	// Idle of clusters without compute cost has no one to be charged to.
+	for cluster, idle := range idleByCluster {
+		if computeByCluster[cluster] > 0 || idle == 0 {
+			continue
+		}
+		g, ok := groups[IdleName]
+		if !ok {
+			g = &Group{Name: IdleName}
+			groups[IdleName] = g
+		}
+		item := LineItem{Cluster: cluster, Namespace: IdleName, Costs: Costs{Idle: idle}}
+		g.LineItems = append(g.LineItems, item)
+		g.add(item.Costs)
+	}
+
+	report := Report{Period: p, By: by}
+	for _, g := range groups {
+		sort.Slice(g.LineItems, func(i, j int) bool {
+			a, b := g.LineItems[i], g.LineItems[j]
+			if a.Total() != b.Total() {
+				return a.Total() > b.Total()
+			}
+			return a.Cluster+"/"+a.Namespace < b.Cluster+"/"+b.Namespace
+		})
+		report.Groups = append(report.Groups, *g)
+		report.add(g.Costs)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if a.Total() != b.Total() {
+			return a.Total() > b.Total()
+		}
+		return a.Name < b.Name
+	})
+
+	return report, nil
+}
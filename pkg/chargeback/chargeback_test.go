@@ -0,0 +1,109 @@
+package chargeback
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/core/pkg/opencost"
+
+	"github.com/kubecost/kubectl-cost/pkg/period"
+)
+
+func alloc(name, cluster string, cpu, pv, network, shared float64) opencost.Allocation {
+	return opencost.Allocation{
+		Name:        name,
+		Properties:  &opencost.AllocationProperties{Cluster: cluster},
+		CPUCost:     cpu,
+		PVs:         opencost.PVAllocations{{Cluster: cluster, Name: "pv"}: {Cost: pv}},
+		NetworkCost: network,
+		SharedCost:  shared,
+	}
+}
+
+func TestBuild(t *testing.T) {
+	p, _ := period.Parse("2026-09", time.Now())
+	allocations := map[string]opencost.Allocation{
+		"one/payments/api":             alloc("one/payments/api", "one", 30, 5, 1, 2),
+		"one/payments/db":              alloc("one/payments/db", "one", 10, 20, 0, 0),
+		"one/search/es":                alloc("one/search/es", "one", 60, 0, 4, 0),
+		"one/__unallocated__/default":  alloc("one/__unallocated__/default", "one", 0, 0, 0, 0),
+		"one/__idle__":                 alloc("one/__idle__", "one", 50, 0, 0, 0),
+		"two/__idle__":                 alloc("two/__idle__", "two", 8, 0, 0, 0),
+		"three/team=__unallocated__/x": alloc("three/team=__unallocated__/x", "three", 1, 0, 0, 0),
+	}
+
+	report, err := Build(p, "label:team", allocations)
+	if err != nil {
+		t.Fatalf("building: %s", err)
+	}
+
+	names := []string{}
+	for _, g := range report.Groups {
+		names = append(names, g.Name)
+	}
+	expected := []string{"search", "payments", IdleName, UnallocatedName}
+	if len(names) != len(expected) {
+		t.Fatalf("expected groups %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("expected groups %v, got %v", expected, names)
+		}
+	}
+
+	// Cluster one has 100 of compute cost and 50 of idle.
+	payments := report.Groups[1]
+	if payments.Compute != 40 || payments.Storage != 25 || payments.Network != 1 || payments.Shared != 2 || payments.Idle != 20 {
+		t.Errorf("unexpected payments costs %+v", payments.Costs)
+	}
+	if len(payments.LineItems) != 2 || payments.LineItems[0].Namespace != "api" || payments.LineItems[0].Idle != 15 {
+		t.Errorf("unexpected payments line items %+v", payments.LineItems)
+	}
+	if report.Groups[0].Idle != 30 {
+		t.Errorf("expected search to get 30 of idle, got %f", report.Groups[0].Idle)
+	}
+
+	// Cluster two has no compute cost to share its idle with.
+	if idle := report.Groups[2]; idle.Idle != 8 || idle.LineItems[0].Cluster != "two" {
+		t.Errorf("unexpected unshared idle %+v", idle)
+	}
+
+	if math.Abs(report.Total()-(100+25+5+2+50+8+1)) > 1e-9 {
+		t.Errorf("expected the report total to include all costs, got %f", report.Total())
+	}
+}
+
+func TestBuildRejectsOtherAggregations(t *testing.T) {
+	p, _ := period.Parse("2026-09", time.Now())
+	allocations := map[string]opencost.Allocation{
+		"one/api": alloc("one/api", "one", 1, 0, 0, 0),
+	}
+	if _, err := Build(p, "label:team", allocations); err == nil {
+		t.Errorf("expected an error for allocations not aggregated by cluster, team and namespace")
+	}
+}
+
+func TestBuildGroupWithSlash(t *testing.T) {
+	p, _ := period.Parse("2026-09", time.Now())
+	allocations := map[string]opencost.Allocation{
+		"one/team.example.com/payments/api": alloc("one/team.example.com/payments/api", "one", 30, 0, 0, 0),
+		"one/team.example.com/payments/db":  alloc("one/team.example.com/payments/db", "one", 10, 0, 0, 0),
+		"one/search/es":                     alloc("one/search/es", "one", 60, 0, 0, 0),
+	}
+
+	report, err := Build(p, "annotation:owner", allocations)
+	if err != nil {
+		t.Fatalf("building: %s", err)
+	}
+	if len(report.Groups) != 2 {
+		t.Fatalf("expected 2 groups, got %+v", report.Groups)
+	}
+	payments := report.Groups[1]
+	if payments.Name != "team.example.com/payments" || payments.Compute != 40 {
+		t.Errorf("unexpected group %+v", payments)
+	}
+	if len(payments.LineItems) != 2 || payments.LineItems[0].Cluster != "one" || payments.LineItems[0].Namespace != "api" {
+		t.Errorf("unexpected line items %+v", payments.LineItems)
+	}
+}
@@ -43,6 +43,12 @@ func buildStandardAggregatedAllocationCommand(streams genericclioptions.IOStream
 				return err
 			}
 
+			if o.sharing() {
+				o.ShowSharedCost = true
+				o.ShowDirectCost = true
+			}
+			o.AllocationDisplayOptions.Complete()
+
 			return runAggregatedAllocationCommand(kubeO, o, aggregation)
 		},
 	}
@@ -53,6 +59,8 @@ func buildStandardAggregatedAllocationCommand(streams genericclioptions.IOStream
 	}
 
 	addCostOptionsFlags(cmd, &o.CostOptions)
+	addIdleOptionsFlags(cmd, &o.CostOptions)
+	addShareOptionsFlags(cmd, &o.CostOptions)
 	display.AddAllocationDisplayOptionsFlags(cmd, &o.AllocationDisplayOptions)
 	utilities.AddKubeOptionsFlags(cmd, kubeO)
 
@@ -70,23 +78,25 @@ func runAggregatedAllocationCommand(ko *utilities.KubeOptions, o AggregatedAlloc
 		currencyCode = ""
 	}
 
+	params := map[string]string{
+		"window":           o.window,
+		"aggregate":        strings.Join(aggregation, ","),
+		"accumulate":       "true",
+		"filterNamespaces": o.filterNamespace,
+	}
+	o.addIdleQueryParams(params)
+	o.addShareQueryParams(params)
+
 	allocations, err := query.QueryAllocation(query.AllocationParameters{
-		Ctx: context.Background(),
-		QueryParams: map[string]string{
-			"window":           o.window,
-			"aggregate":        strings.Join(aggregation, ","),
-			"accumulate":       "true",
-			"includeIdle":      fmt.Sprintf("%t", o.includeIdle),
-			"idle":             fmt.Sprintf("%t", o.includeIdle),
-			"filterNamespaces": o.filterNamespace,
-		},
+		Ctx:                 context.Background(),
+		QueryParams:         params,
 		QueryBackendOptions: o.QueryBackendOptions,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to query allocation API: %s", err)
 	}
 
-	display.WriteAllocationTable(ko.Out, aggregation, allocations[0], o.AllocationDisplayOptions, currencyCode, !o.isHistorical)
+	display.WriteAllocationTable(ko.Out, aggregation, o.shareIdleCost(allocations[0]), o.AllocationDisplayOptions, currencyCode, o.costProjection)
 
 	return nil
 }
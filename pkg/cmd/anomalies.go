@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/opencost/opencost/core/pkg/log"
+	"github.com/opencost/opencost/core/pkg/opencost"
+	"github.com/opencost/opencost/core/pkg/util/timeutil"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+
+	"github.com/kubecost/kubectl-cost/pkg/anomaly"
+	"github.com/kubecost/kubectl-cost/pkg/cmd/display"
+	"github.com/kubecost/kubectl-cost/pkg/cmd/utilities"
+	"github.com/kubecost/kubectl-cost/pkg/query"
+)
+
+var anomaliesExample = `
+    # List days of the last 30 in which a namespace's cost deviated from the
+    # median of its previous week.
+    %[1]s cost anomalies
+
+    # Compare controllers hour by hour over the last two days, flagging
+    # smaller deviations.
+    %[1]s cost anomalies --aggregate controller --window 2d --step 1h --baseline 24 --sensitivity 2
+
+    # Find the teams whose cost spiked.
+    %[1]s cost anomalies --aggregate label:team
+`
+
+// AnomaliesOptions contains options specific to detecting cost anomalies.
+type AnomaliesOptions struct {
+	window    string
+	step      string
+	aggregate string
+	namespace string
+
+	anomaly.Options
+
+	query.QueryBackendOptions
+}
+
+func newCmdCostAnomalies(streams genericclioptions.IOStreams) *cobra.Command {
+	kubeO := utilities.NewKubeOptions(streams)
+	anomaliesO := &AnomaliesOptions{Options: anomaly.DefaultOptions}
+
+	cmd := &cobra.Command{
+		Use:     "anomalies",
+		Short:   "list steps, such as days, in which the cost of a namespace, controller or label value deviated from its baseline",
+		Example: fmt.Sprintf(anomaliesExample, "kubectl"),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := kubeO.Complete(c, args); err != nil {
+				return err
+			}
+			if err := kubeO.Validate(); err != nil {
+				return err
+			}
+
+			if err := anomaliesO.Complete(kubeO.RestConfig); err != nil {
+				return fmt.Errorf("completing options: %s", err)
+			}
+			if err := anomaliesO.Validate(); err != nil {
+				return err
+			}
+
+			return runCostAnomalies(kubeO, anomaliesO)
+		},
+	}
+
+	cmd.Flags().StringVar(&anomaliesO.window, "window", "30d", "The window of data to look for anomalies in. See https://github.com/kubecost/docs/blob/master/allocation.md#querying for a detailed explanation of what can be passed here.")
+	cmd.Flags().StringVar(&anomaliesO.step, "step", "1d", "The duration of each step which is compared with its baseline, e.g. 1d or 1h.")
+	cmd.Flags().StringVar(&anomaliesO.aggregate, "aggregate", "namespace", "Look for anomalies by 'namespace', 'controller' or 'label:<name>'.")
+	cmd.Flags().StringVarP(&anomaliesO.namespace, "namespace", "n", "", "Only consider allocations in this namespace.")
+	cmd.Flags().Float64Var(&anomaliesO.Sensitivity, "sensitivity", anomaly.DefaultOptions.Sensitivity, "How many scaled median absolute deviations (MADs) a step's cost must be from its baseline to be listed. Lower is more sensitive.")
+	cmd.Flags().IntVar(&anomaliesO.BaselineSteps, "baseline", anomaly.DefaultOptions.BaselineSteps, "How many preceding steps the baseline is the rolling median of.")
+	query.AddQueryBackendOptionsFlags(cmd, &anomaliesO.QueryBackendOptions)
+	utilities.AddKubeOptionsFlags(cmd, kubeO)
+
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func (o *AnomaliesOptions) Complete(restConfig *rest.Config) error {
+	if err := o.QueryBackendOptions.Complete(restConfig); err != nil {
+		return fmt.Errorf("complete backend opts: %s", err)
+	}
+	o.MinBaselineSteps = min(o.MinBaselineSteps, o.BaselineSteps)
+	return nil
+}
+
+func (o *AnomaliesOptions) Validate() error {
+	if _, err := opencost.ParseWindowWithOffset(o.window, 0); err != nil {
+		return fmt.Errorf("failed to parse window: %s", err)
+	}
+	if step, err := timeutil.ParseDuration(o.step); err != nil {
+		return fmt.Errorf("failed to parse step: %s", err)
+	} else if step <= 0 {
+		return fmt.Errorf("--step must be positive")
+	}
+	if o.Sensitivity <= 0 {
+		return fmt.Errorf("--sensitivity must be positive")
+	}
+	if o.BaselineSteps < 1 {
+		return fmt.Errorf("--baseline must be at least 1")
+	}
+	if _, err := anomalyAggregation(o.aggregate); err != nil {
+		return err
+	}
+
+	if err := o.QueryBackendOptions.Validate(); err != nil {
+		return fmt.Errorf("validating query options: %s", err)
+	}
+
+	return nil
+}
+
+// anomalyAggregation returns the Allocation API aggregation of --aggregate.
+// Controllers are aggregated with their namespace, as their names are only
+// unique within it.
+func anomalyAggregation(aggregate string) (string, error) {
+	switch {
+	case aggregate == "namespace":
+		return "namespace", nil
+	case aggregate == "controller":
+		return "namespace,controller", nil
+	case strings.HasPrefix(aggregate, "label:") && aggregate != "label:":
+		return aggregate, nil
+	}
+	return "", fmt.Errorf("--aggregate must be one of: namespace, controller, label:<name>")
+}
+
+func runCostAnomalies(ko *utilities.KubeOptions, o *AnomaliesOptions) error {
+	currencyCode, err := query.QueryCurrencyCode(query.CurrencyCodeParameters{
+		Ctx:                 context.Background(),
+		QueryBackendOptions: o.QueryBackendOptions,
+	})
+	if err != nil {
+		log.Debugf("failed to get currency code, displaying as empty string: %s", err)
+		currencyCode = ""
+	}
+
+	aggregate, err := anomalyAggregation(o.aggregate)
+	if err != nil {
+		return err
+	}
+
+	sets, err := query.QueryAllocation(query.AllocationParameters{
+		Ctx: context.Background(),
+		QueryParams: map[string]string{
+			"window":           o.window,
+			"step":             o.step,
+			"aggregate":        aggregate,
+			"accumulate":       "false",
+			"includeIdle":      "false",
+			"idle":             "false",
+			"filterNamespaces": o.namespace,
+		},
+		QueryBackendOptions: o.QueryBackendOptions,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to query allocation API: %s", err)
+	}
+	sets = anomaly.TrimPartialStep(sets)
+	if len(sets) <= o.MinBaselineSteps {
+		return fmt.Errorf("window '%s' has %d steps of %s, but at least %d are needed to compare a step with a baseline", o.window, len(sets), o.step, o.MinBaselineSteps+1)
+	}
+
+	aggregateCol := capitalize(o.aggregate)
+	if strings.HasPrefix(o.aggregate, "label:") {
+		aggregateCol = strings.TrimPrefix(o.aggregate, "label:")
+	}
+	display.WriteAnomalyTable(ko.Out, anomaly.Detect(sets, o.Options), aggregateCol, currencyCode)
+	return nil
+}
+
+// capitalize upper-cases the first letter of s.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubecost/kubectl-cost/pkg/anomaly"
+)
+
+func TestAnomaliesOptionsValidateStep(t *testing.T) {
+	cases := []struct {
+		name string
+		step string
+		err  string
+	}{
+		{name: "not a duration", step: "daily", err: "failed to parse step"},
+		{name: "zero", step: "0h", err: "--step must be positive"},
+		{name: "negative", step: "-1d", err: "--step must be positive"},
+	}
+
+	for _, c := range cases {
+		o := AnomaliesOptions{window: "30d", step: c.step, aggregate: "namespace", Options: anomaly.DefaultOptions}
+		err := o.Validate()
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: expected error containing %q, got %v", c.name, c.err, err)
+		}
+	}
+}
+
+func TestCapitalize(t *testing.T) {
+	cases := map[string]string{
+		"":           "",
+		"namespace":  "Namespace",
+		"controller": "Controller",
+	}
+
+	for in, expected := range cases {
+		if got := capitalize(in); got != expected {
+			t.Errorf("capitalize(%q): expected %q, got %q", in, expected, got)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubecost/kubectl-cost/pkg/cmd/display"
+	"github.com/kubecost/kubectl-cost/pkg/cmd/utilities"
+	"github.com/kubecost/kubectl-cost/pkg/query"
+	"github.com/opencost/opencost/core/pkg/log"
+)
+
+var assetsExample = `
+    # Show the projected monthly cost of every asset, of any type.
+    %[1]s cost assets
+
+    # Show the cost of disks and load balancers over the last week.
+    %[1]s cost assets --type disk,loadbalancer --window 7d --historical
+`
+
+// assetTypes maps the values accepted by 'assets --type' to the asset types
+// of the Assets API.
+var assetTypes = map[string]string{
+	"node":              query.AssetTypeNode,
+	"disk":              query.AssetTypeDisk,
+	"loadbalancer":      query.AssetTypeLoadBalancer,
+	"network":           query.AssetTypeNetwork,
+	"clustermanagement": query.AssetTypeClusterManagement,
+	"cloud":             query.AssetTypeCloud,
+}
+
+// CostOptionsAssets contains the standard CostOptions and the asset types
+// of the generic assets command.
+type CostOptionsAssets struct {
+	CostOptions
+
+	types []string
+}
+
+func (o *CostOptionsAssets) filterTypes() (string, error) {
+	var types []string
+	for _, t := range o.types {
+		apiType, ok := assetTypes[strings.ToLower(t)]
+		if !ok {
+			return "", fmt.Errorf("unknown asset type '%s', must be one of: node, disk, loadbalancer, network, clustermanagement, cloud", t)
+		}
+		types = append(types, apiType)
+	}
+	return strings.Join(types, ","), nil
+}
+
+// buildAssetCommand builds a command which queries assets accumulated over
+// the window and displays them with run.
+func buildAssetCommand(
+	streams genericclioptions.IOStreams,
+	commandName string,
+	commandAliases []string,
+	short string,
+	o *CostOptionsAssets,
+	run func(ko *utilities.KubeOptions, o *CostOptionsAssets, currencyCode string) error,
+) *cobra.Command {
+	kubeO := utilities.NewKubeOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:     commandName,
+		Short:   short,
+		Aliases: commandAliases,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := kubeO.Complete(c, args); err != nil {
+				return err
+			}
+			if err := kubeO.Validate(); err != nil {
+				return err
+			}
+
+			if err := o.CostOptions.Complete(kubeO.RestConfig); err != nil {
+				return fmt.Errorf("completing options: %s", err)
+			}
+			if err := o.CostOptions.Validate(); err != nil {
+				return err
+			}
+
+			currencyCode, err := query.QueryCurrencyCode(query.CurrencyCodeParameters{
+				Ctx:                 context.Background(),
+				QueryBackendOptions: o.QueryBackendOptions,
+			})
+			if err != nil {
+				log.Debugf("failed to get currency code, displaying as empty string: %s", err)
+				currencyCode = ""
+			}
+
+			return run(kubeO, o, currencyCode)
+		},
+	}
+
+	addCostOptionsFlags(cmd, &o.CostOptions)
+	utilities.AddKubeOptionsFlags(cmd, kubeO)
+
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func (o *CostOptionsAssets) assetParameters() query.AssetParameters {
+	return query.AssetParameters{
+		Ctx:                 context.Background(),
+		Window:              o.window,
+		Accumulate:          "true",
+		QueryBackendOptions: o.QueryBackendOptions,
+	}
+}
+
+// accumulatedAssets returns the single set of an accumulated asset query.
+func accumulatedAssets[S ~map[string]T, T any](sets []S, err error) (S, error) {
+	if err != nil {
+		return nil, fmt.Errorf("failed to query assets API: %s", err)
+	}
+	if len(sets) == 0 {
+		return S{}, nil
+	}
+	return sets[0], nil
+}
+
+func newCmdCostDisk(streams genericclioptions.IOStreams) *cobra.Command {
+	return buildAssetCommand(streams, "disk", nil,
+		"view cost information by disks, with their size and storage class",
+		&CostOptionsAssets{},
+		func(ko *utilities.KubeOptions, o *CostOptionsAssets, currencyCode string) error {
+			assets, err := accumulatedAssets(query.QueryDiskAssets(o.assetParameters()))
+			if err != nil {
+				return err
+			}
+			display.WriteDiskAssetTable(ko.Out, assets, currencyCode, o.costProjection)
+			return nil
+		},
+	)
+}
+
+func newCmdCostLoadBalancer(streams genericclioptions.IOStreams) *cobra.Command {
+	return buildAssetCommand(streams, "loadbalancer", []string{"lb"},
+		"view cost information by load balancers, with their IPs",
+		&CostOptionsAssets{},
+		func(ko *utilities.KubeOptions, o *CostOptionsAssets, currencyCode string) error {
+			assets, err := accumulatedAssets(query.QueryLoadBalancerAssets(o.assetParameters()))
+			if err != nil {
+				return err
+			}
+			display.WriteLoadBalancerAssetTable(ko.Out, assets, currencyCode, o.costProjection)
+			return nil
+		},
+	)
+}
+
+func newCmdCostNetwork(streams genericclioptions.IOStreams) *cobra.Command {
+	return buildAssetCommand(streams, "network", nil,
+		"view network cost information by cluster",
+		&CostOptionsAssets{},
+		func(ko *utilities.KubeOptions, o *CostOptionsAssets, currencyCode string) error {
+			assets, err := accumulatedAssets(query.QueryNetworkAssets(o.assetParameters()))
+			if err != nil {
+				return err
+			}
+			display.WriteNetworkAssetTable(ko.Out, assets, currencyCode, o.costProjection)
+			return nil
+		},
+	)
+}
+
+func newCmdCostCloud(streams genericclioptions.IOStreams) *cobra.Command {
+	return buildAssetCommand(streams, "cloud", nil,
+		"view cost information of out-of-cluster cloud services",
+		&CostOptionsAssets{},
+		func(ko *utilities.KubeOptions, o *CostOptionsAssets, currencyCode string) error {
+			assets, err := accumulatedAssets(query.QueryCloudAssets(o.assetParameters()))
+			if err != nil {
+				return err
+			}
+			display.WriteCloudAssetTable(ko.Out, assets, currencyCode, o.costProjection)
+			return nil
+		},
+	)
+}
+
+func newCmdCostAssets(streams genericclioptions.IOStreams) *cobra.Command {
+	assetsO := &CostOptionsAssets{}
+
+	cmd := buildAssetCommand(streams, "assets", []string{"asset"},
+		"view cost information of assets of any type",
+		assetsO,
+		func(ko *utilities.KubeOptions, o *CostOptionsAssets, currencyCode string) error {
+			filterTypes, err := o.filterTypes()
+			if err != nil {
+				return err
+			}
+			p := o.assetParameters()
+			p.FilterTypes = filterTypes
+
+			assets, err := accumulatedAssets(query.QueryAssetSets(p))
+			if err != nil {
+				return err
+			}
+			display.WriteGenericAssetTable(ko.Out, assets, currencyCode, o.costProjection)
+			return nil
+		},
+	)
+	cmd.Example = fmt.Sprintf(assetsExample, "kubectl")
+	cmd.Flags().StringSliceVar(&assetsO.types, "type", nil, "Only show assets of these types: node, disk, loadbalancer, network, clustermanagement, cloud. Defaults to all types.")
+
+	return cmd
+}
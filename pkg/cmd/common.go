@@ -2,11 +2,16 @@ package cmd
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/opencost/opencost/core/pkg/opencost"
 	"github.com/spf13/cobra"
 	"k8s.io/client-go/rest"
 
+	"github.com/kubecost/kubectl-cost/pkg/idle"
+	"github.com/kubecost/kubectl-cost/pkg/period"
 	"github.com/kubecost/kubectl-cost/pkg/query"
 )
 
@@ -16,9 +21,25 @@ type CostOptions struct {
 	window          string
 	filterNamespace string
 	includeIdle     bool
+	shareIdle       string
+	idleBy          string
+
+	shareNamespaces   []string
+	shareLabels       []string
+	shareSplit        string
+	shareCost         float64
+	shareTenancyCosts bool
 
 	isHistorical bool
 
+	period     string
+	projection string
+
+	// calendarPeriod is the parsed --period, if any, and costProjection how
+	// costs are displayed. Both are set by Complete.
+	calendarPeriod *period.Period
+	costProjection period.Projection
+
 	query.QueryBackendOptions
 }
 
@@ -26,14 +47,115 @@ func addCostOptionsFlags(cmd *cobra.Command, options *CostOptions) {
 	cmd.Flags().StringVar(&options.window, "window", "1d", "The window of data to query. See https://github.com/kubecost/docs/blob/master/allocation.md#querying for a detailed explanation of what can be passed here.")
 	cmd.Flags().BoolVar(&options.isHistorical, "historical", false, "show the total cost during the window instead of the projected monthly rate based on the data in the window")
 	cmd.Flags().BoolVar(&options.includeIdle, "idle", true, "include the __idle__ cost row in the response")
+	addPeriodOptionsFlags(cmd, options)
 
 	query.AddQueryBackendOptionsFlags(cmd, &options.QueryBackendOptions)
 }
 
+// addPeriodOptionsFlags adds the flags which query a calendar period instead
+// of a window, and set the length of the month which costs are projected to.
+func addPeriodOptionsFlags(cmd *cobra.Command, options *CostOptions) {
+	cmd.Flags().StringVar(&options.period, "period", "", fmt.Sprintf("Query a calendar period instead of --window: %s. Unless --historical, the costs of a period in progress are shown to date and projected to its end.", period.Formats))
+	cmd.Flags().StringVar(&options.projection, "projection", "", fmt.Sprintf("The length of the month which monthly rates are projected to: %s. Defaults to %s. The costs of a --period which is over are shown as what the period cost, unless this is set.", period.ProjectionFormats, period.DefaultProjection))
+}
+
+// addIdleOptionsFlags adds the flags which control how allocation queries
+// break down and share idle cost.
+func addIdleOptionsFlags(cmd *cobra.Command, options *CostOptions) {
+	cmd.Flags().StringVar(&options.shareIdle, "share-idle", "none", "Share idle cost among the other rows: 'weighted' in proportion to their CPU, GPU and RAM cost, 'even' in equal parts per cluster, or 'none' to show it as __idle__ rows.")
+	cmd.Flags().StringVar(&options.idleBy, "idle-by", "cluster", "Compute idle cost by 'cluster' or by 'node'. By node is more accurate when nodes are utilized unevenly.")
+}
+
+// addIdleQueryParams sets the allocation query parameters for idle cost.
+// Idle is always included when shared evenly, which is done client-side by
+// shareIdle.
+func (co *CostOptions) addIdleQueryParams(params map[string]string) {
+	includeIdle := co.includeIdle || co.shareIdle == "even"
+	params["includeIdle"] = fmt.Sprintf("%t", includeIdle)
+	params["idle"] = fmt.Sprintf("%t", includeIdle)
+	params["shareIdle"] = fmt.Sprintf("%t", co.shareIdle == "weighted")
+	params["idleByNode"] = fmt.Sprintf("%t", co.idleBy == "node")
+}
+
+// shareIdleCost shares idle cost evenly if --share-idle is 'even'. Weighted
+// sharing is done by the API.
+func (co *CostOptions) shareIdleCost(allocations map[string]opencost.Allocation) map[string]opencost.Allocation {
+	if co.shareIdle != "even" {
+		return allocations
+	}
+	return idle.ShareEvenly(allocations)
+}
+
+// addShareOptionsFlags adds the flags which control which costs allocation
+// queries share among the other allocations.
+func addShareOptionsFlags(cmd *cobra.Command, options *CostOptions) {
+	cmd.Flags().StringSliceVar(&options.shareNamespaces, "share-namespaces", nil, "Share the cost of these namespaces among all other allocations, e.g. kube-system,monitoring.")
+	cmd.Flags().StringSliceVar(&options.shareLabels, "share-labels", nil, "Share the cost of allocations with these labels among all other allocations, given as name:value, e.g. team:platform.")
+	cmd.Flags().StringVar(&options.shareSplit, "share-split", "weighted", "Share costs 'weighted' in proportion to the cost of each allocation, or 'even' in equal parts.")
+	cmd.Flags().Float64Var(&options.shareCost, "share-cost", 0, "A fixed monthly overhead cost to share among all allocations.")
+	cmd.Flags().BoolVar(&options.shareTenancyCosts, "share-tenancy-costs", false, "Share cluster management and attached volume costs among all allocations.")
+}
+
+// sharing is whether any costs are shared.
+func (co *CostOptions) sharing() bool {
+	return len(co.shareNamespaces) > 0 || len(co.shareLabels) > 0 || co.shareCost > 0 || co.shareTenancyCosts
+}
+
+// addShareQueryParams sets the allocation query parameters for shared costs.
+func (co *CostOptions) addShareQueryParams(params map[string]string) {
+	if len(co.shareNamespaces) > 0 {
+		params["shareNamespaces"] = strings.Join(co.shareNamespaces, ",")
+	}
+	if len(co.shareLabels) > 0 {
+		params["shareLabels"] = strings.Join(co.shareLabels, ",")
+	}
+	if co.shareCost > 0 {
+		params["shareCost"] = strconv.FormatFloat(co.shareCost, 'f', -1, 64)
+	}
+	if co.shareSplit != "" {
+		params["shareSplit"] = co.shareSplit
+	}
+	params["shareTenancyCosts"] = fmt.Sprintf("%t", co.shareTenancyCosts)
+}
+
 func (co *CostOptions) Complete(restConfig *rest.Config) error {
 	if err := co.QueryBackendOptions.Complete(restConfig); err != nil {
 		return fmt.Errorf("complete backend opts: %s", err)
 	}
+	return co.completePeriod(time.Now().UTC())
+}
+
+// completePeriod resolves --period to a window and --projection and
+// --historical to how costs are displayed. A period in progress is projected
+// to its end, and a period which is over is shown as what it cost, unless
+// --projection is set.
+func (co *CostOptions) completePeriod(now time.Time) error {
+	projection := co.projection
+	if projection == "" {
+		projection = period.DefaultProjection
+	}
+	monthHours, err := period.ParseProjection(projection, now)
+	if err != nil {
+		return err
+	}
+
+	co.calendarPeriod = nil
+	co.costProjection = period.Monthly(monthHours)
+	if co.period != "" {
+		p, err := period.Parse(co.period, now)
+		if err != nil {
+			return err
+		}
+		co.calendarPeriod = &p
+		co.window = p.Window(now)
+		co.costProjection = period.ToEndOf(p, now, monthHours)
+		if !p.InProgress(now) && co.projection != "" {
+			co.costProjection = period.Monthly(monthHours)
+		}
+	}
+	if co.isHistorical {
+		co.costProjection = period.Projection{}
+	}
 	return nil
 }
 
@@ -44,6 +166,30 @@ func (co *CostOptions) Validate() error {
 		return fmt.Errorf("failed to parse window: %s", err)
 	}
 
+	switch co.shareIdle {
+	case "", "none", "weighted", "even":
+	default:
+		return fmt.Errorf("--share-idle must be one of: weighted, even, none")
+	}
+	switch co.idleBy {
+	case "", "cluster", "node":
+	default:
+		return fmt.Errorf("--idle-by must be one of: cluster, node")
+	}
+	switch co.shareSplit {
+	case "", "weighted", "even":
+	default:
+		return fmt.Errorf("--share-split must be one of: weighted, even")
+	}
+	if co.shareCost < 0 {
+		return fmt.Errorf("--share-cost cannot be negative")
+	}
+	for _, label := range co.shareLabels {
+		if !strings.Contains(label, ":") {
+			return fmt.Errorf("--share-labels must be given as name:value, got '%s'", label)
+		}
+	}
+
 	if err := co.QueryBackendOptions.Validate(); err != nil {
 		return fmt.Errorf("validating query options: %s", err)
 	}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kubecost/kubectl-cost/pkg/period"
+)
+
+func TestCompletePeriod(t *testing.T) {
+	now := time.Date(2026, time.October, 11, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name       string
+		opts       CostOptions
+		window     string
+		projection period.Projection
+	}{
+		{
+			name:       "window",
+			opts:       CostOptions{window: "7d"},
+			window:     "7d",
+			projection: period.Monthly(730),
+		},
+		{
+			name:       "window with projection",
+			opts:       CostOptions{window: "7d", projection: "30d"},
+			window:     "7d",
+			projection: period.Monthly(720),
+		},
+		{
+			name:       "window historical",
+			opts:       CostOptions{window: "7d", isHistorical: true},
+			window:     "7d",
+			projection: period.Projection{},
+		},
+		{
+			name:       "period in progress",
+			opts:       CostOptions{period: "this-month"},
+			window:     "2026-10-01T00:00:00Z,2026-10-11T00:00:00Z",
+			projection: period.Projection{MonthHours: 730, RemainingHours: 21 * 24},
+		},
+		{
+			name:       "period which is over",
+			opts:       CostOptions{period: "last-month"},
+			window:     "2026-09-01T00:00:00Z,2026-10-01T00:00:00Z",
+			projection: period.Projection{},
+		},
+		{
+			name:       "period which is over with projection",
+			opts:       CostOptions{period: "2026-08", projection: "calendar-month"},
+			window:     "2026-08-01T00:00:00Z,2026-09-01T00:00:00Z",
+			projection: period.Monthly(31 * 24),
+		},
+		{
+			name:       "period in progress historical",
+			opts:       CostOptions{period: "this-month", isHistorical: true},
+			window:     "2026-10-01T00:00:00Z,2026-10-11T00:00:00Z",
+			projection: period.Projection{},
+		},
+	}
+
+	for _, c := range cases {
+		co := c.opts
+		if err := co.completePeriod(now); err != nil {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+			continue
+		}
+		if co.window != c.window {
+			t.Errorf("%s: expected window %s, got %s", c.name, c.window, co.window)
+		}
+		if co.costProjection != c.projection {
+			t.Errorf("%s: expected projection %+v, got %+v", c.name, c.projection, co.costProjection)
+		}
+	}
+}
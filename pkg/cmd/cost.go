@@ -25,6 +25,10 @@ var (
     %[1]s cost predict -f 'k8s-deployment.yaml' \
       --show-cost-per-resource-hr
 
+    # Predict the cost of scaling the live Deployment "api" in the
+    # "prod" namespace to 10 replicas.
+    %[1]s cost predict deployment/api -n prod --replicas 10
+
     # Show how much each namespace cost over the past 5 days
     # with additional CPU and memory cost and without efficiency.
     %[1]s cost namespace \
@@ -158,10 +162,20 @@ helm install \
 	))
 	cmd.AddCommand(newCmdCostLabel(streams))
 	cmd.AddCommand(newCmdCostNode(streams))
+	cmd.AddCommand(newCmdCostDisk(streams))
+	cmd.AddCommand(newCmdCostLoadBalancer(streams))
+	cmd.AddCommand(newCmdCostNetwork(streams))
+	cmd.AddCommand(newCmdCostCloud(streams))
+	cmd.AddCommand(newCmdCostAssets(streams))
+	cmd.AddCommand(newCmdCostIdle(streams))
+	cmd.AddCommand(newCmdReport(streams))
+	cmd.AddCommand(newCmdCostAnomalies(streams))
+	cmd.AddCommand(newCmdCostExplain(streams))
 	cmd.AddCommand(newCmdTUI(streams))
 	cmd.AddCommand(newCmdVersion(streams, GitCommit, GitBranch, GitState, GitSummary, BuildDate))
 	cmd.AddCommand(NewCmdPredict(streams))
 	cmd.AddCommand(newCmdCostSavings(streams))
+	cmd.AddCommand(newCmdPricing(streams))
 
 	return cmd
 }
@@ -0,0 +1,63 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	"github.com/kubecost/kubectl-cost/pkg/anomaly"
+)
+
+func WriteAnomalyTable(out io.Writer, anomalies []anomaly.Anomaly, aggregateCol string, currencyCode string) {
+	t := MakeAnomalyTable(anomalies, aggregateCol, currencyCode)
+	t.SetOutputMirror(out)
+	t.Render()
+}
+
+// MakeAnomalyTable lists anomalies with their cost against the baseline, how
+// many scaled MADs they deviated by, and the component which deviated the
+// most.
+func MakeAnomalyTable(anomalies []anomaly.Anomaly, aggregateCol string, currencyCode string) table.Writer {
+	t := table.NewWriter()
+	t.SetStyle(plainTableStyle())
+
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Start", Align: text.AlignLeft},
+		{Name: aggregateCol, Align: text.AlignLeft},
+		{Name: "Cost", Align: text.AlignRight},
+		{Name: "Baseline", Align: text.AlignRight},
+		{Name: "Change", Align: text.AlignRight},
+		{Name: "Deviation", Align: text.AlignRight},
+		{Name: "Component", Align: text.AlignLeft},
+		{Name: "Component Change", Align: text.AlignRight},
+	})
+
+	t.AppendHeader(table.Row{"Start", aggregateCol, "Cost", "Baseline", "Change", "Deviation", "Component", "Component Change"})
+
+	for _, a := range anomalies {
+		t.AppendRow(table.Row{
+			formatStepStart(a.Start, a.End),
+			a.Name,
+			fmt.Sprintf("%.2f %s", a.Cost, currencyCode),
+			fmt.Sprintf("%.2f %s", a.Baseline, currencyCode),
+			fmt.Sprintf("%+.2f %s", a.Change(), currencyCode),
+			fmt.Sprintf("%+.1f MAD", a.Score),
+			a.Component,
+			fmt.Sprintf("%+.2f %s", a.ComponentChange, currencyCode),
+		})
+	}
+
+	return t
+}
+
+// formatStepStart shows the date of daily or longer steps, and the time of
+// shorter ones.
+func formatStepStart(start, end time.Time) string {
+	if end.Sub(start) >= 24*time.Hour {
+		return start.Format("2006-01-02")
+	}
+	return start.Format("2006-01-02 15:04")
+}
@@ -0,0 +1,35 @@
+package display
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubecost/kubectl-cost/pkg/anomaly"
+)
+
+func TestMakeAnomalyTable(t *testing.T) {
+	start := time.Date(2026, time.September, 7, 0, 0, 0, 0, time.UTC)
+	anomalies := []anomaly.Anomaly{{
+		Name:            "web",
+		Start:           start,
+		End:             start.AddDate(0, 0, 1),
+		Cost:            36.5,
+		Baseline:        17,
+		Score:           11.47,
+		Component:       "Network",
+		ComponentChange: 20,
+	}}
+
+	out := MakeAnomalyTable(anomalies, "Namespace", "USD").Render()
+	for _, want := range []string{"NAMESPACE", "2026-09-07", "web", "36.50 USD", "+19.50 USD", "+11.5 MAD", "Network", "+20.00 USD"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected table to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	anomalies[0].End = start.Add(time.Hour)
+	if out := MakeAnomalyTable(anomalies, "Namespace", "USD").Render(); !strings.Contains(out, "2026-09-07 00:00") {
+		t.Errorf("expected the time of an hourly step, got:\n%s", out)
+	}
+}
@@ -0,0 +1,241 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	"github.com/kubecost/kubectl-cost/pkg/period"
+	"github.com/kubecost/kubectl-cost/pkg/query"
+)
+
+const (
+	StorageClassCol = "Storage Class"
+	SizeCol         = "Size (GiB)"
+	ClaimCol        = "Claim"
+	IPCol           = "IP"
+	PrivateCol      = "Private"
+	ProviderIDCol   = "Provider ID"
+	ProviderCol     = "Provider"
+	AccountCol      = "Account"
+	ServiceCol      = "Service"
+	CategoryCol     = "Category"
+)
+
+// ToDateCol is the column of costs to date, shown alongside costs projected to
+// the end of a period in progress.
+const ToDateCol = "To Date"
+
+func costColumnName(projection period.Projection) string {
+	switch {
+	case projection.ToDate():
+		return "Projected Cost"
+	case projection.Projects():
+		return "Monthly Cost"
+	}
+	return "Total Cost"
+}
+
+// makeTypedAssetTable sets up a table with the given leading columns and a
+// cost column, appends rows sorted by their leading columns and a footer
+// summing costs. Costs over minutes are scaled by the projection, and also
+// shown to date if it projects to the end of a period.
+func makeTypedAssetTable(columns []string, rows []table.Row, costs, minutes []float64, currencyCode string, projection period.Projection) table.Writer {
+	t := table.NewWriter()
+
+	costCol := costColumnName(projection)
+
+	columnConfigs := []table.ColumnConfig{}
+	headerRow := table.Row{}
+	for i, col := range columns {
+		columnConfigs = append(columnConfigs, table.ColumnConfig{
+			Name:      col,
+			AutoMerge: i == 0,
+		})
+		headerRow = append(headerRow, col)
+	}
+	if projection.ToDate() {
+		columnConfigs = append(columnConfigs, table.ColumnConfig{
+			Name:        ToDateCol,
+			Align:       text.AlignRight,
+			AlignFooter: text.AlignRight,
+		})
+		headerRow = append(headerRow, ToDateCol)
+	}
+	columnConfigs = append(columnConfigs, table.ColumnConfig{
+		Name:        costCol,
+		Align:       text.AlignRight,
+		AlignFooter: text.AlignRight,
+	})
+	headerRow = append(headerRow, costCol)
+
+	t.SetColumnConfigs(columnConfigs)
+	t.AppendHeader(headerRow)
+
+	order := make([]int, len(rows))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return fmt.Sprint(rows[order[i]]) < fmt.Sprint(rows[order[j]])
+	})
+
+	var summedCost, summedToDate float64
+	for _, i := range order {
+		row := rows[i]
+		if projection.ToDate() {
+			row = append(row, formatFloat(costs[i]))
+			summedToDate += costs[i]
+		}
+		cost := costs[i] * projection.Scale(minutes[i])
+		t.AppendRow(append(row, formatFloat(cost)))
+		summedCost += cost
+	}
+
+	footerRow := table.Row{"SUMMED"}
+	for i := 1; i < len(columns); i++ {
+		footerRow = append(footerRow, "")
+	}
+	if projection.ToDate() {
+		footerRow = append(footerRow, fmt.Sprintf("%s %s", currencyCode, formatFloat(summedToDate)))
+	}
+	footerRow = append(footerRow, fmt.Sprintf("%s %s", currencyCode, formatFloat(summedCost)))
+	t.AppendFooter(footerRow)
+
+	return t
+}
+
+func WriteDiskAssetTable(out io.Writer, assets map[string]query.AssetDisk, currencyCode string, projection period.Projection) {
+	t := MakeDiskAssetTable(assets, currencyCode, projection)
+
+	t.SetOutputMirror(out)
+	t.Render()
+}
+
+func MakeDiskAssetTable(assets map[string]query.AssetDisk, currencyCode string, projection period.Projection) table.Writer {
+	var rows []table.Row
+	var costs, minutes []float64
+	for _, asset := range assets {
+		claim := ""
+		if asset.ClaimName != "" {
+			claim = fmt.Sprintf("%s/%s", asset.ClaimNamespace, asset.ClaimName)
+		}
+		rows = append(rows, table.Row{
+			asset.Properties.Cluster,
+			asset.Properties.Name,
+			asset.StorageClass,
+			fmtResourceFloat(asset.Bytes / 1024 / 1024 / 1024),
+			claim,
+		})
+		costs = append(costs, asset.TotalCost)
+		minutes = append(minutes, asset.Minutes)
+	}
+
+	return makeTypedAssetTable([]string{ClusterCol, NameCol, StorageClassCol, SizeCol, ClaimCol}, rows, costs, minutes, currencyCode, projection)
+}
+
+func WriteLoadBalancerAssetTable(out io.Writer, assets map[string]query.AssetLoadBalancer, currencyCode string, projection period.Projection) {
+	t := MakeLoadBalancerAssetTable(assets, currencyCode, projection)
+
+	t.SetOutputMirror(out)
+	t.Render()
+}
+
+func MakeLoadBalancerAssetTable(assets map[string]query.AssetLoadBalancer, currencyCode string, projection period.Projection) table.Writer {
+	var rows []table.Row
+	var costs, minutes []float64
+	for _, asset := range assets {
+		private := "no"
+		if asset.Private {
+			private = "yes"
+		}
+		rows = append(rows, table.Row{
+			asset.Properties.Cluster,
+			asset.Properties.Name,
+			asset.IP,
+			private,
+		})
+		costs = append(costs, asset.TotalCost)
+		minutes = append(minutes, asset.Minutes)
+	}
+
+	return makeTypedAssetTable([]string{ClusterCol, NameCol, IPCol, PrivateCol}, rows, costs, minutes, currencyCode, projection)
+}
+
+func WriteNetworkAssetTable(out io.Writer, assets map[string]query.AssetNetwork, currencyCode string, projection period.Projection) {
+	t := MakeNetworkAssetTable(assets, currencyCode, projection)
+
+	t.SetOutputMirror(out)
+	t.Render()
+}
+
+func MakeNetworkAssetTable(assets map[string]query.AssetNetwork, currencyCode string, projection period.Projection) table.Writer {
+	var rows []table.Row
+	var costs, minutes []float64
+	for _, asset := range assets {
+		rows = append(rows, table.Row{
+			asset.Properties.Cluster,
+			asset.Properties.Name,
+			asset.Properties.ProviderID,
+		})
+		costs = append(costs, asset.TotalCost)
+		minutes = append(minutes, asset.Minutes)
+	}
+
+	return makeTypedAssetTable([]string{ClusterCol, NameCol, ProviderIDCol}, rows, costs, minutes, currencyCode, projection)
+}
+
+func WriteCloudAssetTable(out io.Writer, assets map[string]query.AssetCloud, currencyCode string, projection period.Projection) {
+	t := MakeCloudAssetTable(assets, currencyCode, projection)
+
+	t.SetOutputMirror(out)
+	t.Render()
+}
+
+func MakeCloudAssetTable(assets map[string]query.AssetCloud, currencyCode string, projection period.Projection) table.Writer {
+	var rows []table.Row
+	var costs, minutes []float64
+	for _, asset := range assets {
+		rows = append(rows, table.Row{
+			asset.Properties.Provider,
+			asset.Properties.Account,
+			asset.Properties.Service,
+			asset.Properties.Category,
+			asset.Properties.ProviderID,
+		})
+		costs = append(costs, asset.TotalCost)
+		minutes = append(minutes, asset.Minutes)
+	}
+
+	return makeTypedAssetTable([]string{ProviderCol, AccountCol, ServiceCol, CategoryCol, ProviderIDCol}, rows, costs, minutes, currencyCode, projection)
+}
+
+func WriteGenericAssetTable(out io.Writer, assets query.AssetSet, currencyCode string, projection period.Projection) {
+	t := MakeGenericAssetTable(assets, currencyCode, projection)
+
+	t.SetOutputMirror(out)
+	t.Render()
+}
+
+// MakeGenericAssetTable shows assets of any type, with only the columns which
+// all asset types have.
+func MakeGenericAssetTable(assets query.AssetSet, currencyCode string, projection period.Projection) table.Writer {
+	var rows []table.Row
+	var costs, minutes []float64
+	for _, a := range assets {
+		asset := a.Common()
+		rows = append(rows, table.Row{
+			asset.Type,
+			asset.Properties.Cluster,
+			asset.Properties.Name,
+			asset.Properties.Provider,
+		})
+		costs = append(costs, asset.TotalCost)
+		minutes = append(minutes, asset.Minutes)
+	}
+
+	return makeTypedAssetTable([]string{AssetTypeCol, ClusterCol, NameCol, ProviderCol}, rows, costs, minutes, currencyCode, projection)
+}
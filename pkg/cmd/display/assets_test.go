@@ -0,0 +1,82 @@
+package display
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opencost/opencost/core/pkg/opencost"
+
+	"github.com/kubecost/kubectl-cost/pkg/period"
+	"github.com/kubecost/kubectl-cost/pkg/query"
+)
+
+func TestMakeDiskAssetTable(t *testing.T) {
+	assets := map[string]query.AssetDisk{
+		"a": {
+			Properties:     opencost.AssetProperties{Cluster: "cluster-one", Name: "pvc-a"},
+			Minutes:        21600,
+			TotalCost:      5,
+			Bytes:          100 * 1024 * 1024 * 1024,
+			StorageClass:   "standard",
+			ClaimName:      "data",
+			ClaimNamespace: "db",
+		},
+	}
+
+	out := MakeDiskAssetTable(assets, "USD", period.Monthly(720)).Render()
+	for _, want := range []string{"STORAGE CLASS", "standard", "100", "db/data", "MONTHLY COST", "USD 10.000000"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected table to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	out = MakeDiskAssetTable(assets, "USD", period.Projection{}).Render()
+	if !strings.Contains(out, "USD 5.000000") {
+		t.Errorf("expected historical cost, got:\n%s", out)
+	}
+}
+
+func TestMakeGenericAssetTable(t *testing.T) {
+	assets := query.AssetSet{
+		"b": query.AssetNetwork{Type: "Network", Properties: opencost.AssetProperties{Cluster: "cluster-one", Name: "network"}, TotalCost: 1},
+		"a": query.AssetLoadBalancer{Type: "LoadBalancer", Properties: opencost.AssetProperties{Cluster: "cluster-one", Name: "ingress"}, TotalCost: 2},
+	}
+
+	out := MakeGenericAssetTable(assets, "USD", period.Projection{}).Render()
+	lb := strings.Index(out, "LoadBalancer")
+	network := strings.Index(out, "Network")
+	if lb < 0 || network < 0 || lb > network {
+		t.Errorf("expected rows sorted by type, got:\n%s", out)
+	}
+	if !strings.Contains(out, "USD 3.000000") {
+		t.Errorf("expected summed cost, got:\n%s", out)
+	}
+}
+
+func TestMakeAssetTableDetails(t *testing.T) {
+	assets := map[string]query.AssetNode{
+		"node": {
+			Properties:   opencost.AssetProperties{Cluster: "cluster-one", Name: "node-a"},
+			Minutes:      60,
+			CpuCores:     4,
+			RamBytes:     16 * 1024 * 1024 * 1024,
+			CPUCoreHours: 4,
+			RAMByteHours: 16 * 1024 * 1024 * 1024,
+			CPUCost:      0.2,
+			RAMCost:      0.08,
+			CPUBreakdown: opencost.Breakdown{Idle: 0.25},
+			Preemptible:  1,
+			Discount:     0.3,
+			TotalCost:    0.28,
+		},
+	}
+
+	opts := AssetDisplayOptions{ShowAll: true, NameColumn: "Instance"}
+	opts.Complete()
+	out := MakeAssetTable("Node", assets, opts, "USD", period.Projection{}).Render()
+	for _, want := range []string{"INSTANCE", "CORES", "16", "yes", "30.0%", "0.0500", "0.0050", "25.0%"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected table to contain %q, got:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	"github.com/kubecost/kubectl-cost/pkg/capacity"
+	"github.com/kubecost/kubectl-cost/pkg/period"
+)
+
+const utilizationBarWidth = 10
+
+// utilizationBar draws a fraction as a bar followed by its percentage, e.g.
+// "███░░░░░░░  32%". Fractions above 1 are drawn as a full bar.
+func utilizationBar(f float64) string {
+	filled := int(math.Round(math.Min(math.Max(f, 0), 1) * utilizationBarWidth))
+	return fmt.Sprintf("%s%s %3.0f%%", strings.Repeat("█", filled), strings.Repeat("░", utilizationBarWidth-filled), f*100)
+}
+
+func WriteNodeConsolidationTable(out io.Writer, recs []capacity.Recommendation, currencyCode string) {
+	t := MakeNodeConsolidationTable(recs, currencyCode)
+	t.SetOutputMirror(out)
+	t.Render()
+}
+
+// MakeNodeConsolidationTable shows the requested share of each node pool's
+// capacity, and the cheapest nodes found to run the same requests.
+func MakeNodeConsolidationTable(recs []capacity.Recommendation, currencyCode string) table.Writer {
+	t := table.NewWriter()
+	t.SetStyle(plainTableStyle())
+
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Cluster", Align: text.AlignLeft},
+		{Name: "Pool", Align: text.AlignLeft},
+		{Name: "Nodes", Align: text.AlignLeft},
+		{Name: "CPU Requested", Align: text.AlignLeft},
+		{Name: "RAM Requested", Align: text.AlignLeft},
+		{Name: "Cost/mo", Align: text.AlignRight},
+		{Name: "Recommendation", Align: text.AlignLeft},
+		{Name: "Rec. Cost/mo", Align: text.AlignRight},
+		{Name: "Savings/mo", Align: text.AlignRight},
+	})
+
+	t.AppendHeader(table.Row{"Cluster", "Pool", "Nodes", "CPU Requested", "RAM Requested", "Cost/mo", "Recommendation", "Rec. Cost/mo", "Savings/mo"})
+
+	nodes := func(count int, nodeType string) string {
+		if nodeType == "" {
+			nodeType = "mixed"
+		}
+		return fmt.Sprintf("%d × %s", count, nodeType)
+	}
+
+	total, totalRecommended, totalSavings := 0.0, 0.0, 0.0
+	for _, r := range recs {
+		total += r.MonthlyRate
+		totalRecommended += r.RecommendedMonthlyRate
+		totalSavings += r.MonthlySavings
+
+		recommendation := "keep"
+		if r.MonthlySavings > 0 {
+			recommendation = nodes(r.RecommendedNodes, r.RecommendedNodeType)
+		}
+
+		t.AppendRow(table.Row{
+			r.Cluster,
+			r.Name,
+			nodes(len(r.Nodes), r.NodeType),
+			utilizationBar(r.CPURequestUtilization()),
+			utilizationBar(r.RAMRequestUtilization()),
+			fmt.Sprintf("%.2f %s", r.MonthlyRate, currencyCode),
+			recommendation,
+			fmt.Sprintf("%.2f %s", r.RecommendedMonthlyRate, currencyCode),
+			fmt.Sprintf("%.2f %s", r.MonthlySavings, currencyCode),
+		})
+	}
+
+	t.AppendFooter(table.Row{
+		"TOTAL", "", "", "", "",
+		fmt.Sprintf("%.2f %s", total, currencyCode),
+		"",
+		fmt.Sprintf("%.2f %s", totalRecommended, currencyCode),
+		fmt.Sprintf("%.2f %s", totalSavings, currencyCode),
+	})
+
+	return t
+}
+
+func WriteNodeUtilizationTable(out io.Writer, nodes []capacity.Node, currencyCode string) {
+	t := MakeNodeUtilizationTable(nodes, currencyCode)
+	t.SetOutputMirror(out)
+	t.Render()
+}
+
+// MakeNodeUtilizationTable shows the requested and used share of the capacity
+// of each node.
+func MakeNodeUtilizationTable(nodes []capacity.Node, currencyCode string) table.Writer {
+	t := table.NewWriter()
+	t.SetStyle(plainTableStyle())
+
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Cluster", Align: text.AlignLeft},
+		{Name: "Node", Align: text.AlignLeft},
+		{Name: "Pool", Align: text.AlignLeft},
+		{Name: "CPU Requested", Align: text.AlignLeft},
+		{Name: "CPU Used", Align: text.AlignLeft},
+		{Name: "RAM Requested", Align: text.AlignLeft},
+		{Name: "RAM Used", Align: text.AlignLeft},
+		{Name: "Cost/mo", Align: text.AlignRight},
+	})
+
+	t.AppendHeader(table.Row{"Cluster", "Node", "Pool", "CPU Requested", "CPU Used", "RAM Requested", "RAM Used", "Cost/mo"})
+
+	for _, n := range nodes {
+		t.AppendRow(table.Row{
+			n.Cluster,
+			n.Name,
+			n.Pool,
+			utilizationBar(n.CPURequestUtilization()),
+			utilizationBar(n.CPUUsageUtilization()),
+			utilizationBar(n.RAMRequestUtilization()),
+			utilizationBar(n.RAMUsageUtilization()),
+			fmt.Sprintf("%.2f %s", n.MonthlyRate, currencyCode),
+		})
+	}
+
+	return t
+}
+
+func WriteNodeEfficiencyTable(out io.Writer, nodes []capacity.Node, currencyCode string, projection period.Projection, showIdle bool) {
+	t := MakeNodeEfficiencyTable(nodes, currencyCode, projection, showIdle)
+	t.SetOutputMirror(out)
+	t.Render()
+}
+
+// idleColumnName names the column of idle costs by projection, like
+// costColumnName.
+func idleColumnName(projection period.Projection) string {
+	switch {
+	case projection.ToDate():
+		return "Projected Idle"
+	case projection.Projects():
+		return "Monthly Idle"
+	}
+	return "Total Idle"
+}
+
+// MakeNodeEfficiencyTable shows the cost of each node next to how much of its
+// capacity is requested and used, its idle cost if showIdle, and the
+// namespaces which cost the most on it. Costs are scaled by the projection.
+func MakeNodeEfficiencyTable(nodes []capacity.Node, currencyCode string, projection period.Projection, showIdle bool) table.Writer {
+	t := table.NewWriter()
+	t.SetStyle(plainTableStyle())
+
+	columns := []table.ColumnConfig{
+		{Name: "Cluster", Align: text.AlignLeft},
+		{Name: "Node", Align: text.AlignLeft},
+	}
+	if projection.ToDate() {
+		columns = append(columns, table.ColumnConfig{Name: ToDateCol, Align: text.AlignRight})
+	}
+	columns = append(columns,
+		table.ColumnConfig{Name: costColumnName(projection), Align: text.AlignRight},
+		table.ColumnConfig{Name: "CPU Req./Cap.", Align: text.AlignRight},
+		table.ColumnConfig{Name: "CPU Used", Align: text.AlignRight},
+		table.ColumnConfig{Name: "RAM Req./Cap.", Align: text.AlignRight},
+		table.ColumnConfig{Name: "RAM Used", Align: text.AlignRight},
+	)
+	if showIdle {
+		columns = append(columns, table.ColumnConfig{Name: idleColumnName(projection), Align: text.AlignRight})
+	}
+	columns = append(columns, table.ColumnConfig{Name: "Top Namespaces", Align: text.AlignLeft})
+
+	t.SetColumnConfigs(columns)
+	header := table.Row{}
+	for _, c := range columns {
+		header = append(header, c.Name)
+	}
+	t.AppendHeader(header)
+
+	var toDate, total, idle float64
+	for _, n := range nodes {
+		// Node rates are monthly, so they are converted back to costs over
+		// the window before projecting them.
+		scale := projection.Scale(n.Minutes)
+		cost := n.WindowCost(n.MonthlyRate)
+		toDate += cost
+		total += cost * scale
+		idle += n.WindowCost(n.IdleMonthlyRate) * scale
+
+		var top []string
+		for _, ns := range n.TopNamespaces {
+			top = append(top, fmt.Sprintf("%s (%.2f)", ns.Namespace, n.WindowCost(ns.MonthlyRate)*scale))
+		}
+
+		row := table.Row{n.Cluster, n.Name}
+		if projection.ToDate() {
+			row = append(row, fmt.Sprintf("%.2f %s", cost, currencyCode))
+		}
+		row = append(row,
+			fmt.Sprintf("%.2f %s", cost*scale, currencyCode),
+			fmt.Sprintf("%s/%s %3.0f%%", fmtResourceFloat(n.CPUCoresRequested), fmtResourceFloat(n.CPUCores), n.CPURequestUtilization()*100),
+			fmt.Sprintf("%s %3.0f%%", fmtResourceFloat(n.CPUCoresUsed), n.CPUUsageUtilization()*100),
+			fmt.Sprintf("%s/%s GiB %3.0f%%", fmtResourceFloat(n.RAMBytesRequested/1024/1024/1024), fmtResourceFloat(n.RAMBytes/1024/1024/1024), n.RAMRequestUtilization()*100),
+			fmt.Sprintf("%s GiB %3.0f%%", fmtResourceFloat(n.RAMBytesUsed/1024/1024/1024), n.RAMUsageUtilization()*100),
+		)
+		if showIdle {
+			row = append(row, fmt.Sprintf("%.2f %s", n.WindowCost(n.IdleMonthlyRate)*scale, currencyCode))
+		}
+		row = append(row, strings.Join(top, ", "))
+		t.AppendRow(row)
+	}
+
+	footer := table.Row{"TOTAL", ""}
+	if projection.ToDate() {
+		footer = append(footer, fmt.Sprintf("%.2f %s", toDate, currencyCode))
+	}
+	footer = append(footer, fmt.Sprintf("%.2f %s", total, currencyCode), "", "", "", "")
+	if showIdle {
+		footer = append(footer, fmt.Sprintf("%.2f %s", idle, currencyCode))
+	}
+	footer = append(footer, "")
+	t.AppendFooter(footer)
+
+	return t
+}
@@ -0,0 +1,122 @@
+package display
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubecost/kubectl-cost/pkg/capacity"
+	"github.com/kubecost/kubectl-cost/pkg/period"
+)
+
+func TestUtilizationBar(t *testing.T) {
+	cases := map[float64]string{
+		0:    "░░░░░░░░░░   0%",
+		0.32: "███░░░░░░░  32%",
+		1:    "██████████ 100%",
+		1.5:  "██████████ 150%",
+	}
+	for f, expected := range cases {
+		if bar := utilizationBar(f); bar != expected {
+			t.Errorf("%g: expected %q, got %q", f, expected, bar)
+		}
+	}
+}
+
+func TestMakeNodeConsolidationTable(t *testing.T) {
+	recs := []capacity.Recommendation{{
+		Pool: capacity.Pool{
+			Cluster:           "cluster-one",
+			Name:              "default-pool",
+			NodeType:          "n2-standard-8",
+			Nodes:             make([]capacity.Node, 4),
+			CPUCores:          32,
+			RAMBytes:          128,
+			CPUCoresRequested: 8,
+			RAMBytesRequested: 32,
+			MonthlyRate:       800,
+		},
+		RecommendedNodeType:    "n2-standard-8",
+		RecommendedNodes:       2,
+		RecommendedMonthlyRate: 400,
+		MonthlySavings:         400,
+	}}
+
+	out := MakeNodeConsolidationTable(recs, "USD").Render()
+	for _, want := range []string{"4 × n2-standard-8", "2 × n2-standard-8", "400.00 USD", "800.00 USD"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected table to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestMakeNodeEfficiencyTable(t *testing.T) {
+	// The node ran for 73h, a tenth of a 730h month.
+	nodes := []capacity.Node{{
+		Cluster:           "cluster-one",
+		Name:              "node-a",
+		CPUCores:          4,
+		RAMBytes:          16 * 1024 * 1024 * 1024,
+		MonthlyRate:       730,
+		Minutes:           73 * 60,
+		CPUCoresRequested: 1,
+		RAMBytesRequested: 8 * 1024 * 1024 * 1024,
+		CPUCoresUsed:      0.5,
+		IdleMonthlyRate:   292,
+		TopNamespaces: []capacity.NamespaceCost{
+			{Namespace: "web", MonthlyRate: 219},
+			{Namespace: "db", MonthlyRate: 146},
+		},
+	}}
+
+	cases := []struct {
+		name       string
+		projection period.Projection
+		showIdle   bool
+		want       []string
+		notWant    []string
+	}{
+		{
+			name:       "monthly",
+			projection: period.DefaultMonthly(),
+			showIdle:   true,
+			want:       []string{"MONTHLY COST", "MONTHLY IDLE", "1/4  25%", "8/16 GiB  50%", "730.00 USD", "292.00 USD", "web (219.00), db (146.00)"},
+		},
+		{
+			name:       "monthly over a shorter month",
+			projection: period.Monthly(365),
+			showIdle:   true,
+			want:       []string{"365.00 USD", "146.00 USD", "web (109.50), db (73.00)"},
+		},
+		{
+			name:       "historical",
+			projection: period.Projection{},
+			showIdle:   true,
+			want:       []string{"TOTAL COST", "TOTAL IDLE", "73.00 USD", "29.20 USD", "web (21.90), db (14.60)"},
+		},
+		{
+			name:       "to date",
+			projection: period.Projection{MonthHours: 730, RemainingHours: 73},
+			showIdle:   true,
+			want:       []string{"TO DATE", "PROJECTED COST", "PROJECTED IDLE", "73.00 USD", "146.00 USD", "58.40 USD"},
+		},
+		{
+			name:       "without idle",
+			projection: period.DefaultMonthly(),
+			want:       []string{"MONTHLY COST", "730.00 USD"},
+			notWant:    []string{"IDLE", "292.00 USD"},
+		},
+	}
+	for _, c := range cases {
+		out := MakeNodeEfficiencyTable(nodes, "USD", c.projection, c.showIdle).Render()
+		for _, want := range c.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s: expected table to contain %q, got:\n%s", c.name, want, out)
+			}
+		}
+		for _, notWant := range c.notWant {
+			if strings.Contains(out, notWant) {
+				t.Errorf("%s: expected table not to contain %q, got:\n%s", c.name, notWant, out)
+			}
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package display
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	"github.com/kubecost/kubectl-cost/pkg/chargeback"
+)
+
+// ChargebackReportFormats are the formats supported by WriteChargebackReport.
+var ChargebackReportFormats = []string{"markdown", "html", "csv"}
+
+var chargebackCostColumns = []string{"Compute", "Storage", "Network", "Shared", "Idle", "Total"}
+
+// WriteChargebackReport writes a report in one of ChargebackReportFormats.
+// Markdown and HTML have a summary of the groups followed by the line items
+// of each group. CSV has a row per line item and no totals.
+func WriteChargebackReport(out io.Writer, format string, report chargeback.Report, currencyCode string) error {
+	switch format {
+	case "markdown":
+		return writeChargebackMarkdown(out, report, currencyCode)
+	case "html":
+		return writeChargebackHTML(out, report, currencyCode)
+	case "csv":
+		return writeChargebackCSV(out, report, currencyCode)
+	}
+	return fmt.Errorf("unsupported format '%s', must be one of: %s", format, strings.Join(ChargebackReportFormats, ", "))
+}
+
+// MakeChargebackSummaryTable has a row per group of the report.
+func MakeChargebackSummaryTable(report chargeback.Report) table.Writer {
+	t := makeChargebackTable(report.By)
+	for _, g := range report.Groups {
+		t.AppendRow(append(table.Row{g.Name}, chargebackCostCells(g.Costs)...))
+	}
+	t.AppendFooter(append(table.Row{"Total"}, chargebackCostCells(report.Costs)...))
+	return t
+}
+
+// MakeChargebackGroupTable has a row per line item of a group.
+func MakeChargebackGroupTable(group chargeback.Group) table.Writer {
+	t := makeChargebackTable("Cluster", "Namespace")
+	for _, item := range group.LineItems {
+		t.AppendRow(append(table.Row{item.Cluster, item.Namespace}, chargebackCostCells(item.Costs)...))
+	}
+	t.AppendFooter(append(table.Row{"Total", ""}, chargebackCostCells(group.Costs)...))
+	return t
+}
+
+func makeChargebackTable(columns ...string) table.Writer {
+	t := table.NewWriter()
+
+	columnConfigs := []table.ColumnConfig{}
+	headerRow := table.Row{}
+	for _, col := range columns {
+		columnConfigs = append(columnConfigs, table.ColumnConfig{Name: col})
+		headerRow = append(headerRow, col)
+	}
+	for _, col := range chargebackCostColumns {
+		columnConfigs = append(columnConfigs, table.ColumnConfig{
+			Name:        col,
+			Align:       text.AlignRight,
+			AlignFooter: text.AlignRight,
+		})
+		headerRow = append(headerRow, col)
+	}
+	t.SetColumnConfigs(columnConfigs)
+	t.AppendHeader(headerRow)
+
+	return t
+}
+
+func chargebackCostCells(c chargeback.Costs) table.Row {
+	return table.Row{
+		fmt.Sprintf("%.2f", c.Compute),
+		fmt.Sprintf("%.2f", c.Storage),
+		fmt.Sprintf("%.2f", c.Network),
+		fmt.Sprintf("%.2f", c.Shared),
+		fmt.Sprintf("%.2f", c.Idle),
+		fmt.Sprintf("%.2f", c.Total()),
+	}
+}
+
+func chargebackTitle(report chargeback.Report) string {
+	return fmt.Sprintf("Chargeback report for %s", report.Period)
+}
+
+func chargebackSubtitle(report chargeback.Report, currencyCode string) string {
+	s := fmt.Sprintf("By %s, from %s to %s.", report.By, report.Period.Start.Format("2006-01-02"), report.Period.End.Format("2006-01-02"))
+	if currencyCode != "" {
+		s += fmt.Sprintf(" All costs in %s.", currencyCode)
+	}
+	return s
+}
+
+func writeChargebackMarkdown(out io.Writer, report chargeback.Report, currencyCode string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", chargebackTitle(report))
+	fmt.Fprintf(&b, "%s\n\n", chargebackSubtitle(report, currencyCode))
+	fmt.Fprintf(&b, "## Summary\n\n%s\n", MakeChargebackSummaryTable(report).RenderMarkdown())
+	for _, g := range report.Groups {
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n", g.Name, MakeChargebackGroupTable(g).RenderMarkdown())
+	}
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func writeChargebackHTML(out io.Writer, report chargeback.Report, currencyCode string) error {
+	title := html.EscapeString(chargebackTitle(report))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n", title)
+	b.WriteString("<style>\nbody { font-family: sans-serif; }\ntable { border-collapse: collapse; margin-bottom: 2em; }\nth, td { border: 1px solid #ccc; padding: 4px 8px; }\ntfoot { font-weight: bold; }\n</style>\n</head>\n<body>\n")
+	fmt.Fprintf(&b, "<h1>%s</h1>\n", title)
+	fmt.Fprintf(&b, "<p>%s</p>\n", html.EscapeString(chargebackSubtitle(report, currencyCode)))
+	fmt.Fprintf(&b, "<h2>Summary</h2>\n%s\n", MakeChargebackSummaryTable(report).RenderHTML())
+	for _, g := range report.Groups {
+		fmt.Fprintf(&b, "<h2>%s</h2>\n%s\n", html.EscapeString(g.Name), MakeChargebackGroupTable(g).RenderHTML())
+	}
+	b.WriteString("</body>\n</html>\n")
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func writeChargebackCSV(out io.Writer, report chargeback.Report, currencyCode string) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{
+		"period",
+		report.By,
+		"cluster",
+		"namespace",
+		"compute",
+		"storage",
+		"network",
+		"shared",
+		"idle",
+		"total",
+		"currency",
+	})
+
+	float := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	for _, g := range report.Groups {
+		for _, item := range g.LineItems {
+			w.Write([]string{
+				report.Period.Start.Format("2006-01"),
+				g.Name,
+				item.Cluster,
+				item.Namespace,
+				float(item.Compute),
+				float(item.Storage),
+				float(item.Network),
+				float(item.Shared),
+				float(item.Idle),
+				float(item.Total()),
+				currencyCode,
+			})
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,72 @@
+package display
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubecost/kubectl-cost/pkg/chargeback"
+	"github.com/kubecost/kubectl-cost/pkg/period"
+)
+
+func chargebackReport(t *testing.T) chargeback.Report {
+	t.Helper()
+	p, err := period.Parse("2026-09", time.Now())
+	if err != nil {
+		t.Fatalf("parsing period: %s", err)
+	}
+	item := chargeback.LineItem{
+		Cluster:   "cluster-one",
+		Namespace: "api",
+		Costs:     chargeback.Costs{Compute: 30, Storage: 5, Network: 1, Shared: 2, Idle: 12.5},
+	}
+	return chargeback.Report{
+		Period: p,
+		By:     "label:team",
+		Groups: []chargeback.Group{{Name: "<payments>", LineItems: []chargeback.LineItem{item}, Costs: item.Costs}},
+		Costs:  item.Costs,
+	}
+}
+
+func TestWriteChargebackReport(t *testing.T) {
+	report := chargebackReport(t)
+
+	var md bytes.Buffer
+	if err := WriteChargebackReport(&md, "markdown", report, "USD"); err != nil {
+		t.Fatalf("writing markdown: %s", err)
+	}
+	for _, want := range []string{"# Chargeback report for September 2026", "All costs in USD.", "## <payments>", "| cluster-one | api |", "50.50"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", want, md.String())
+		}
+	}
+
+	var h bytes.Buffer
+	if err := WriteChargebackReport(&h, "html", report, "USD"); err != nil {
+		t.Fatalf("writing HTML: %s", err)
+	}
+	if !strings.Contains(h.String(), "<h2>&lt;payments&gt;</h2>") || !strings.Contains(h.String(), "<table") {
+		t.Errorf("expected escaped group heading and tables, got:\n%s", h.String())
+	}
+
+	var c bytes.Buffer
+	if err := WriteChargebackReport(&c, "csv", report, "USD"); err != nil {
+		t.Fatalf("writing CSV: %s", err)
+	}
+	records, err := csv.NewReader(&c).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %s", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected a header and one line item, got %v", records)
+	}
+	if got := strings.Join(records[1], ","); got != "2026-09,<payments>,cluster-one,api,30,5,1,2,12.5,50.5,USD" {
+		t.Errorf("unexpected line item %s", got)
+	}
+
+	if err := WriteChargebackReport(&c, "pdf", report, "USD"); err == nil {
+		t.Errorf("expected an error for an unsupported format")
+	}
+}
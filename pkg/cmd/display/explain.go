@@ -0,0 +1,173 @@
+package display
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	"github.com/kubecost/kubectl-cost/pkg/explain"
+)
+
+// WriteExplanation writes a heading naming the workload and window, followed
+// by the tables of MakeExplanationTables separated by blank lines.
+func WriteExplanation(out io.Writer, e explain.Explanation, window string, currencyCode string) {
+	fmt.Fprintf(out, "%s in namespace %s over %s (%.1f hours)\n", e.Workload, e.Namespace, window, e.Minutes/60)
+	for _, t := range MakeExplanationTables(e, currencyCode) {
+		fmt.Fprintln(out)
+		t.SetOutputMirror(out)
+		t.Render()
+	}
+}
+
+// MakeExplanationTables explains a workload's cost with a summary of its cost
+// by component, its containers' requests and usage, the nodes it ran on with
+// the rates it was charged at, and its volumes if it has any.
+func MakeExplanationTables(e explain.Explanation, currencyCode string) []table.Writer {
+	tables := []table.Writer{
+		makeExplanationSummaryTable(e, currencyCode),
+		makeExplanationContainerTable(e, currencyCode),
+		makeExplanationNodeTable(e, currencyCode),
+	}
+	if len(e.Volumes) > 0 {
+		tables = append(tables, makeExplanationVolumeTable(e, currencyCode))
+	}
+	return tables
+}
+
+func makeExplanationSummaryTable(e explain.Explanation, currencyCode string) table.Writer {
+	t := table.NewWriter()
+	t.SetStyle(plainTableStyle())
+	t.SetTitle("Cost by component")
+
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Component", Align: text.AlignLeft},
+		{Name: "Cost", Align: text.AlignRight, AlignFooter: text.AlignRight},
+		{Name: "Share", Align: text.AlignRight},
+	})
+	t.AppendHeader(table.Row{"Component", "Cost", "Share"})
+
+	total := e.TotalCost()
+	for _, c := range []struct {
+		name string
+		cost float64
+	}{
+		{"CPU", e.CPUCost},
+		{"RAM", e.RAMCost},
+		{"GPU", e.GPUCost},
+		{"PV", e.PVCost},
+		{"Network", e.NetworkCost},
+		{"Load Balancer", e.LoadBalancerCost},
+		{"Shared", e.SharedCost},
+		{"Idle Share", e.IdleCost},
+	} {
+		share := 0.0
+		if total > 0 {
+			share = c.cost / total
+		}
+		t.AppendRow(table.Row{c.name, fmt.Sprintf("%.2f %s", c.cost, currencyCode), formatPercent(share)})
+	}
+
+	t.AppendFooter(table.Row{"TOTAL", fmt.Sprintf("%.2f %s", total, currencyCode), ""})
+
+	return t
+}
+
+func makeExplanationContainerTable(e explain.Explanation, currencyCode string) table.Writer {
+	t := table.NewWriter()
+	t.SetStyle(plainTableStyle())
+	t.SetTitle("Containers: requested, used and charged hours")
+
+	columns := []string{"Pod", "Container", "Node", "CPU Req. (core-h)", "CPU Used (core-h)", "CPU Cost", "RAM Req. (GiB-h)", "RAM Used (GiB-h)", "RAM Cost", "GPU-h", "GPU Cost"}
+	columnConfigs := []table.ColumnConfig{}
+	headerRow := table.Row{}
+	for i, col := range columns {
+		align := text.AlignRight
+		if i < 3 {
+			align = text.AlignLeft
+		}
+		columnConfigs = append(columnConfigs, table.ColumnConfig{Name: col, Align: align})
+		headerRow = append(headerRow, col)
+	}
+	t.SetColumnConfigs(columnConfigs)
+	t.AppendHeader(headerRow)
+
+	for _, c := range e.Containers {
+		t.AppendRow(table.Row{
+			c.Pod,
+			c.Container,
+			c.Node,
+			fmt.Sprintf("%.2f", c.CPU.RequestHours),
+			fmt.Sprintf("%.2f", c.CPU.UsageHours),
+			fmt.Sprintf("%.2f %s", c.CPU.Cost, currencyCode),
+			fmt.Sprintf("%.2f", c.RAM.RequestHours),
+			fmt.Sprintf("%.2f", c.RAM.UsageHours),
+			fmt.Sprintf("%.2f %s", c.RAM.Cost, currencyCode),
+			fmt.Sprintf("%.2f", c.GPU.Hours),
+			fmt.Sprintf("%.2f %s", c.GPU.Cost, currencyCode),
+		})
+	}
+
+	return t
+}
+
+func makeExplanationNodeTable(e explain.Explanation, currencyCode string) table.Writer {
+	t := table.NewWriter()
+	t.SetStyle(plainTableStyle())
+	t.SetTitle("Nodes: rates charged")
+
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: ClusterCol, Align: text.AlignLeft},
+		{Name: NodeCol, Align: text.AlignLeft},
+		{Name: "Pods", Align: text.AlignRight},
+		{Name: CostPerCoreHourCol, Align: text.AlignRight},
+		{Name: CostPerGiBHourCol, Align: text.AlignRight},
+		{Name: "Per GPU-Hr", Align: text.AlignRight},
+		{Name: "Cost", Align: text.AlignRight},
+	})
+	t.AppendHeader(table.Row{ClusterCol, NodeCol, "Pods", CostPerCoreHourCol, CostPerGiBHourCol, "Per GPU-Hr", "Cost"})
+
+	for _, n := range e.Nodes {
+		t.AppendRow(table.Row{
+			n.Cluster,
+			n.Name,
+			n.Pods,
+			formatUnitPrice(n.CPU.Cost, n.CPU.Hours),
+			formatUnitPrice(n.RAM.Cost, n.RAM.Hours),
+			formatUnitPrice(n.GPU.Cost, n.GPU.Hours),
+			fmt.Sprintf("%.2f %s", n.Cost(), currencyCode),
+		})
+	}
+
+	return t
+}
+
+func makeExplanationVolumeTable(e explain.Explanation, currencyCode string) table.Writer {
+	t := table.NewWriter()
+	t.SetStyle(plainTableStyle())
+	t.SetTitle("Persistent volumes")
+
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: ClusterCol, Align: text.AlignLeft},
+		{Name: "Volume", Align: text.AlignLeft},
+		{Name: "GiB-h", Align: text.AlignRight},
+		{Name: CostPerGiBHourCol, Align: text.AlignRight},
+		{Name: "Cost", Align: text.AlignRight, AlignFooter: text.AlignRight},
+	})
+	t.AppendHeader(table.Row{ClusterCol, "Volume", "GiB-h", CostPerGiBHourCol, "Cost"})
+
+	for _, v := range e.Volumes {
+		t.AppendRow(table.Row{
+			v.Cluster,
+			v.Name,
+			fmt.Sprintf("%.2f", v.GiBHours),
+			formatUnitPrice(v.Cost, v.GiBHours),
+			fmt.Sprintf("%.2f %s", v.Cost, currencyCode),
+		})
+	}
+
+	t.AppendFooter(table.Row{"TOTAL", "", "", "", fmt.Sprintf("%.2f %s", e.PVCost, currencyCode)})
+
+	return t
+}
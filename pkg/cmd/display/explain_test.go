@@ -0,0 +1,35 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kubecost/kubectl-cost/pkg/explain"
+)
+
+func TestWriteExplanation(t *testing.T) {
+	cpu := explain.Resource{RequestHours: 5, UsageHours: 2.5, Hours: 5, Cost: 1}
+	e := explain.Explanation{
+		Workload:  explain.Workload{Kind: "deployment", Name: "api"},
+		Namespace: "prod",
+		Containers: []explain.Container{
+			{Cluster: "one", Node: "node-a", Pod: "api-1", Container: "api", CPU: cpu},
+		},
+		Nodes:    []explain.Node{{Cluster: "one", Name: "node-a", Pods: 1, CPU: cpu}},
+		CPUCost:  1,
+		IdleCost: 1,
+		Minutes:  600,
+	}
+
+	var out bytes.Buffer
+	WriteExplanation(&out, e, "7d", "USD")
+	for _, want := range []string{"deployment/api in namespace prod over 7d (10.0 hours)", "Idle Share", "50.0%", "2.00 USD", "api-1", "2.50", "0.2000"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "Persistent volumes") {
+		t.Errorf("expected no volumes table without volumes, got:\n%s", out.String())
+	}
+}
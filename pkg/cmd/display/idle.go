@@ -0,0 +1,103 @@
+package display
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	"github.com/kubecost/kubectl-cost/pkg/idle"
+	"github.com/kubecost/kubectl-cost/pkg/period"
+)
+
+const (
+	NodeCol         = "Node"
+	IdleFractionCol = "Idle %"
+)
+
+func WriteIdleTable(out io.Writer, costs []idle.Cost, byNode bool, currencyCode string, projection period.Projection) {
+	t := MakeIdleTable(costs, byNode, currencyCode, projection)
+
+	t.SetOutputMirror(out)
+	t.Render()
+}
+
+// MakeIdleTable shows idle cost by cluster, or by cluster and node, broken
+// down into CPU, RAM and GPU, with the share of the compute cost which is
+// idle.
+func MakeIdleTable(costs []idle.Cost, byNode bool, currencyCode string, projection period.Projection) table.Writer {
+	t := table.NewWriter()
+
+	columns := []string{ClusterCol}
+	if byNode {
+		columns = append(columns, NodeCol)
+	}
+	columns = append(columns, CPUCol, MemoryCol, GPUCol, IdleFractionCol)
+	if projection.ToDate() {
+		columns = append(columns, ToDateCol)
+	}
+	columns = append(columns, costColumnName(projection))
+
+	columnConfigs := []table.ColumnConfig{}
+	headerRow := table.Row{}
+	for i, col := range columns {
+		config := table.ColumnConfig{
+			Name:        col,
+			Align:       text.AlignRight,
+			AlignFooter: text.AlignRight,
+		}
+		if i == 0 || col == NodeCol {
+			config = table.ColumnConfig{Name: col, AutoMerge: i == 0}
+		}
+		columnConfigs = append(columnConfigs, config)
+		headerRow = append(headerRow, col)
+	}
+	t.SetColumnConfigs(columnConfigs)
+	t.AppendHeader(headerRow)
+
+	var summedCPU, summedRAM, summedGPU, summedToDate, summedCost float64
+	for _, c := range costs {
+		scale := projection.Scale(c.Minutes)
+
+		row := table.Row{c.Cluster}
+		if byNode {
+			row = append(row, c.Node)
+		}
+		row = append(row,
+			formatFloat(c.CPUCost*scale),
+			formatFloat(c.RAMCost*scale),
+			formatFloat(c.GPUCost*scale),
+			formatPercent(c.Fraction()),
+		)
+		if projection.ToDate() {
+			row = append(row, formatFloat(c.TotalCost()))
+			summedToDate += c.TotalCost()
+		}
+		row = append(row, formatFloat(c.TotalCost()*scale))
+		t.AppendRow(row)
+
+		summedCPU += c.CPUCost * scale
+		summedRAM += c.RAMCost * scale
+		summedGPU += c.GPUCost * scale
+		summedCost += c.TotalCost() * scale
+	}
+
+	footerRow := table.Row{"SUMMED"}
+	if byNode {
+		footerRow = append(footerRow, "")
+	}
+	footerRow = append(footerRow,
+		formatFloat(summedCPU),
+		formatFloat(summedRAM),
+		formatFloat(summedGPU),
+		"",
+	)
+	if projection.ToDate() {
+		footerRow = append(footerRow, fmt.Sprintf("%s %s", currencyCode, formatFloat(summedToDate)))
+	}
+	footerRow = append(footerRow, fmt.Sprintf("%s %s", currencyCode, formatFloat(summedCost)))
+	t.AppendFooter(footerRow)
+
+	return t
+}
@@ -0,0 +1,69 @@
+package display
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/core/pkg/opencost"
+
+	"github.com/kubecost/kubectl-cost/pkg/idle"
+	"github.com/kubecost/kubectl-cost/pkg/period"
+)
+
+func TestMakeIdleTable(t *testing.T) {
+	costs := []idle.Cost{{
+		Cluster:       "cluster-one",
+		Node:          "node-a",
+		CPUCost:       1,
+		RAMCost:       0.5,
+		AllocatedCost: 4.5,
+		Minutes:       21600,
+	}}
+
+	out := MakeIdleTable(costs, true, "USD", period.Monthly(720)).Render()
+	for _, want := range []string{"NODE", "node-a", "2.000000", "25.0%", "USD 3.000000"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected table to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	if out := MakeIdleTable(costs, false, "USD", period.Projection{}).Render(); strings.Contains(out, "NODE") {
+		t.Errorf("expected no node column, got:\n%s", out)
+	}
+}
+
+func TestMakeAllocationTableIdleByNode(t *testing.T) {
+	allocations := map[string]opencost.Allocation{
+		"cluster-one/node-a/__idle__": {
+			Name:       "cluster-one/node-a/__idle__",
+			Properties: &opencost.AllocationProperties{Cluster: "cluster-one", Node: "node-a"},
+			Window:     opencost.NewClosedWindow(time.Unix(0, 0), time.Unix(3600, 0)),
+			CPUCost:    1,
+		},
+	}
+
+	allocations["cluster-one/node-b/__idle__"] = opencost.Allocation{
+		Name:       "cluster-one/node-b/__idle__",
+		Properties: &opencost.AllocationProperties{Cluster: "cluster-one", Node: "node-b"},
+		Window:     opencost.NewClosedWindow(time.Unix(0, 0), time.Unix(3600, 0)),
+		CPUCost:    2,
+	}
+
+	cases := []struct {
+		aggregation []string
+		expected    []string
+	}{
+		{[]string{"cluster", "namespace"}, []string{"cluster-one", "__idle__ (node-a)", "__idle__ (node-b)"}},
+		{[]string{"namespace"}, []string{"__idle__ (node-a)", "__idle__ (node-b)"}},
+		{[]string{"cluster"}, []string{"cluster-one/__idle__ (node-a)", "cluster-one/__idle__ (node-b)"}},
+	}
+	for _, c := range cases {
+		out := MakeAllocationTable(c.aggregation, allocations, AllocationDisplayOptions{}, "USD", period.Projection{}).Render()
+		for _, e := range c.expected {
+			if !strings.Contains(out, e) {
+				t.Errorf("%v: expected %q in idle rows, got:\n%s", c.aggregation, e, out)
+			}
+		}
+	}
+}
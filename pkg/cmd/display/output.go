@@ -3,12 +3,14 @@ package display
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
 	"github.com/opencost/opencost/core/pkg/opencost"
 
+	"github.com/kubecost/kubectl-cost/pkg/period"
 	"github.com/kubecost/kubectl-cost/pkg/query"
 
 	"github.com/spf13/cobra"
@@ -25,11 +27,22 @@ const (
 	PVCol               = "PV"
 	NetworkCol          = "Network"
 	SharedCol           = "Shared Cost"
+	DirectCol           = "Direct Cost"
 	LoadBalancerCol     = "Load Balancer Cost"
 	NameCol             = "Name"
 	AssetTypeCol        = "Asset Type"
 	CPUCostCol          = "CPU Cost"
 	RAMCostCol          = "RAM Cost"
+	CoresCol            = "Cores"
+	RAMGiBCol           = "RAM (GiB)"
+	GPUsCol             = "GPUs"
+	SpotCol             = "Spot"
+	DiscountCol         = "Discount"
+	AdjustmentCol       = "Adjustment"
+	CostPerCoreHourCol  = "Per Core-Hr"
+	CostPerGiBHourCol   = "Per GiB-Hr"
+	CPUIdleCol          = "CPU Idle"
+	RAMIdleCol          = "RAM Idle"
 )
 
 func formatFloat(f float64) string {
@@ -45,6 +58,7 @@ type AllocationDisplayOptions struct {
 	ShowEfficiency       bool
 	ShowSharedCost       bool
 	ShowLoadBalancerCost bool
+	ShowDirectCost       bool
 
 	ShowAll bool
 }
@@ -53,8 +67,15 @@ type AssetDisplayOptions struct {
 	ShowCPUCost    bool
 	ShowMemoryCost bool
 	ShowAssetType  bool
+	ShowCapacity   bool
+	ShowPricing    bool
+	ShowIdle       bool
 
 	ShowAll bool
+
+	// NameColumn is the header of the name column, which is "Name" if
+	// empty. Tables of aggregated assets name the aggregation instead.
+	NameColumn string
 }
 
 func AddAllocationDisplayOptionsFlags(cmd *cobra.Command, options *AllocationDisplayOptions) {
@@ -65,15 +86,19 @@ func AddAllocationDisplayOptionsFlags(cmd *cobra.Command, options *AllocationDis
 	cmd.Flags().BoolVar(&options.ShowNetworkCost, "show-network", false, "show data for network cost")
 	cmd.Flags().BoolVar(&options.ShowSharedCost, "show-shared", false, "show shared cost data")
 	cmd.Flags().BoolVar(&options.ShowLoadBalancerCost, "show-lb", false, "show load balancer cost data")
+	cmd.Flags().BoolVar(&options.ShowDirectCost, "show-direct", false, "show direct cost, which is total cost without shared cost. Shown by default when costs are shared")
 	cmd.Flags().BoolVar(&options.ShowEfficiency, "show-efficiency", true, "show efficiency of cost alongside CPU and memory cost")
-	cmd.Flags().BoolVarP(&options.ShowAll, "show-all-resources", "A", false, "Equivalent to --show-cpu --show-memory --show-gpu --show-pv --show-network --show-efficiency for namespace, deployment, controller, label and pod")
+	cmd.Flags().BoolVarP(&options.ShowAll, "show-all-resources", "A", false, "Equivalent to --show-cpu --show-memory --show-gpu --show-pv --show-network --show-shared --show-lb --show-direct for namespace, deployment, controller, label and pod")
 }
 
 func AddAssetDisplayOptionsFlags(cmd *cobra.Command, options *AssetDisplayOptions) {
 	cmd.Flags().BoolVar(&options.ShowCPUCost, "show-cpu", false, "show data for CPU cost")
 	cmd.Flags().BoolVar(&options.ShowMemoryCost, "show-memory", false, "show data for memory cost")
 	cmd.Flags().BoolVar(&options.ShowAssetType, "show-asset-type", false, "show type of assets displayed.")
-	cmd.Flags().BoolVarP(&options.ShowAll, "show-all-resources", "A", false, "Equivalent to --show-type --show-cpu --show-memory for node.")
+	cmd.Flags().BoolVar(&options.ShowCapacity, "show-capacity", false, "show CPU cores, RAM and GPUs")
+	cmd.Flags().BoolVar(&options.ShowPricing, "show-pricing", false, "show whether nodes are spot/preemptible, their discount and adjustment, and the effective price per core-hour and per GiB-hour")
+	cmd.Flags().BoolVar(&options.ShowIdle, "show-idle", false, "show the percentage of CPU and RAM which was idle")
+	cmd.Flags().BoolVarP(&options.ShowAll, "show-all-resources", "A", false, "Equivalent to --show-asset-type --show-cpu --show-memory --show-capacity --show-pricing --show-idle for node.")
 }
 
 func (do *AllocationDisplayOptions) Complete() {
@@ -85,6 +110,7 @@ func (do *AllocationDisplayOptions) Complete() {
 		do.ShowNetworkCost = true
 		do.ShowSharedCost = true
 		do.ShowLoadBalancerCost = true
+		do.ShowDirectCost = true
 	}
 }
 
@@ -93,17 +119,57 @@ func (do *AssetDisplayOptions) Complete() {
 		do.ShowCPUCost = true
 		do.ShowMemoryCost = true
 		do.ShowAssetType = true
+		do.ShowCapacity = true
+		do.ShowPricing = true
+		do.ShowIdle = true
+	}
+}
+
+// idleRowName names the columns of an idle allocation whose name doesn't
+// match the aggregation, such as "cluster/node/__idle__" when idle is computed
+// by node. The node goes in the last column, so that idle rows of different
+// nodes can be told apart.
+func idleRowName(aggregation []string, alloc opencost.Allocation) table.Row {
+	row := table.Row{}
+	for range aggregation {
+		row = append(row, "__idle__")
+	}
+	if alloc.Properties == nil {
+		return row
+	}
+	if aggregation[0] == "cluster" {
+		row[0] = alloc.Properties.Cluster
+	}
+	if alloc.Properties.Node != "" {
+		last := len(row) - 1
+		if last == 0 && aggregation[0] == "cluster" {
+			row[last] = fmt.Sprintf("%s/__idle__ (%s)", alloc.Properties.Cluster, alloc.Properties.Node)
+		} else {
+			row[last] = fmt.Sprintf("__idle__ (%s)", alloc.Properties.Node)
+		}
 	}
+	return row
 }
 
-func WriteAllocationTable(out io.Writer, aggregation []string, allocations map[string]opencost.Allocation, opts AllocationDisplayOptions, currencyCode string, projectToMonthlyRate bool) {
-	t := MakeAllocationTable(aggregation, allocations, opts, currencyCode, projectToMonthlyRate)
+// totalColumnName names the total cost column of allocation tables.
+func totalColumnName(projection period.Projection) string {
+	switch {
+	case projection.ToDate():
+		return "Projected Cost (All)"
+	case projection.Projects():
+		return "Monthly Rate (All)"
+	}
+	return "Total Cost (All)"
+}
+
+func WriteAllocationTable(out io.Writer, aggregation []string, allocations map[string]opencost.Allocation, opts AllocationDisplayOptions, currencyCode string, projection period.Projection) {
+	t := MakeAllocationTable(aggregation, allocations, opts, currencyCode, projection)
 
 	t.SetOutputMirror(out)
 	t.Render()
 }
 
-func MakeAllocationTable(aggregation []string, allocations map[string]opencost.Allocation, opts AllocationDisplayOptions, currencyCode string, projectToMonthlyRate bool) table.Writer {
+func MakeAllocationTable(aggregation []string, allocations map[string]opencost.Allocation, opts AllocationDisplayOptions, currencyCode string, projection period.Projection) table.Writer {
 	t := table.NewWriter()
 
 	columnConfigs := []table.ColumnConfig{}
@@ -167,20 +233,28 @@ func MakeAllocationTable(aggregation []string, allocations map[string]opencost.A
 		})
 	}
 
-	if projectToMonthlyRate {
+	if opts.ShowDirectCost {
 		columnConfigs = append(columnConfigs, table.ColumnConfig{
-			Name:        "Monthly Rate (All)",
+			Name:        DirectCol,
 			Align:       text.AlignRight,
 			AlignFooter: text.AlignRight,
 		})
-	} else {
+	}
+
+	if projection.ToDate() {
 		columnConfigs = append(columnConfigs, table.ColumnConfig{
-			Name:        "Total Cost (All)",
+			Name:        ToDateCol,
 			Align:       text.AlignRight,
 			AlignFooter: text.AlignRight,
 		})
 	}
 
+	columnConfigs = append(columnConfigs, table.ColumnConfig{
+		Name:        totalColumnName(projection),
+		Align:       text.AlignRight,
+		AlignFooter: text.AlignRight,
+	})
+
 	if opts.ShowEfficiency {
 		columnConfigs = append(columnConfigs, table.ColumnConfig{
 			Name:        "Cost Efficiency",
@@ -231,12 +305,16 @@ func MakeAllocationTable(aggregation []string, allocations map[string]opencost.A
 		headerRow = append(headerRow, LoadBalancerCol)
 	}
 
-	if projectToMonthlyRate {
-		headerRow = append(headerRow, "Monthly Rate (All)")
-	} else {
-		headerRow = append(headerRow, "Total Cost (All)")
+	if opts.ShowDirectCost {
+		headerRow = append(headerRow, DirectCol)
+	}
+
+	if projection.ToDate() {
+		headerRow = append(headerRow, ToDateCol)
 	}
 
+	headerRow = append(headerRow, totalColumnName(projection))
+
 	if opts.ShowEfficiency {
 		headerRow = append(headerRow, "Cost Efficiency")
 	}
@@ -251,9 +329,14 @@ func MakeAllocationTable(aggregation []string, allocations map[string]opencost.A
 			Name: "Monthly Rate (All)",
 			Mode: table.DscNumeric,
 		},
+		{
+			Name: "Projected Cost (All)",
+			Mode: table.DscNumeric,
+		},
 	})
 
 	var summedCost float64
+	var summedToDate float64
 	var summedCPU float64
 	var summedMemory float64
 	var summedGPU float64
@@ -261,21 +344,12 @@ func MakeAllocationTable(aggregation []string, allocations map[string]opencost.A
 	var summedNetwork float64
 	var summedShared float64
 	var summedLoadBalancer float64
+	var summedDirect float64
 
 	for _, alloc := range allocations {
 
 		// This variable exists to scale costs by the active window
-		var histScaleFactor float64 = 1
-
-		if projectToMonthlyRate {
-
-			// scale by minutes per month divided by duration
-			// of window in minutes to get projected monthly cost.
-			// Note that this approach assumes the window costs will apply
-			// through the ENTIRE projected month, no matter the window size.
-			histScaleFactor = 43200 / alloc.Minutes()
-
-		}
+		histScaleFactor := projection.Scale(alloc.Minutes())
 
 		allocRow := table.Row{}
 
@@ -283,6 +357,8 @@ func MakeAllocationTable(aggregation []string, allocations map[string]opencost.A
 			for range aggregation {
 				allocRow = append(allocRow, "__idle__")
 			}
+		} else if alloc.IsIdle() && len(strings.Split(alloc.Name, "/")) != len(aggregation) {
+			allocRow = append(allocRow, idleRowName(aggregation, alloc)...)
 		} else {
 			splitName := strings.Split(alloc.Name, "/")
 			if len(splitName) != len(aggregation) {
@@ -342,6 +418,17 @@ func MakeAllocationTable(aggregation []string, allocations map[string]opencost.A
 			summedLoadBalancer += adjLoadBalancerCost
 		}
 
+		if opts.ShowDirectCost {
+			adjDirectCost := (alloc.TotalCost() - alloc.SharedCost) * histScaleFactor
+			allocRow = append(allocRow, formatFloat(adjDirectCost))
+			summedDirect += adjDirectCost
+		}
+
+		if projection.ToDate() {
+			allocRow = append(allocRow, formatFloat(alloc.TotalCost()))
+			summedToDate += alloc.TotalCost()
+		}
+
 		adjTotalCost := alloc.TotalCost() * histScaleFactor
 		cumulativeCost := formatFloat(adjTotalCost)
 		allocRow = append(allocRow, cumulativeCost)
@@ -396,6 +483,14 @@ func MakeAllocationTable(aggregation []string, allocations map[string]opencost.A
 		footerRow = append(footerRow, formatFloat(summedLoadBalancer))
 	}
 
+	if opts.ShowDirectCost {
+		footerRow = append(footerRow, formatFloat(summedDirect))
+	}
+
+	if projection.ToDate() {
+		footerRow = append(footerRow, fmt.Sprintf("%s %s", currencyCode, formatFloat(summedToDate)))
+	}
+
 	footerRow = append(footerRow, fmt.Sprintf("%s %s", currencyCode, formatFloat(summedCost)))
 
 	if opts.ShowEfficiency {
@@ -407,16 +502,21 @@ func MakeAllocationTable(aggregation []string, allocations map[string]opencost.A
 	return t
 }
 
-func WriteAssetTable(out io.Writer, assetType string, assets map[string]query.AssetNode, opts AssetDisplayOptions, currencyCode string, projectToMonthlyRate bool) {
-	t := MakeAssetTable(assetType, assets, opts, currencyCode, projectToMonthlyRate)
+func WriteAssetTable(out io.Writer, assetType string, assets map[string]query.AssetNode, opts AssetDisplayOptions, currencyCode string, projection period.Projection) {
+	t := MakeAssetTable(assetType, assets, opts, currencyCode, projection)
 
 	t.SetOutputMirror(out)
 	t.Render()
 }
 
-func MakeAssetTable(assetType string, assets map[string]query.AssetNode, opts AssetDisplayOptions, currencyCode string, projectToMonthlyRate bool) table.Writer {
+func MakeAssetTable(assetType string, assets map[string]query.AssetNode, opts AssetDisplayOptions, currencyCode string, projection period.Projection) table.Writer {
 	t := table.NewWriter()
 
+	nameCol := NameCol
+	if opts.NameColumn != "" {
+		nameCol = opts.NameColumn
+	}
+
 	columnConfigs := []table.ColumnConfig{}
 
 	columnConfigs = append(columnConfigs, table.ColumnConfig{
@@ -425,7 +525,7 @@ func MakeAssetTable(assetType string, assets map[string]query.AssetNode, opts As
 	})
 
 	columnConfigs = append(columnConfigs, table.ColumnConfig{
-		Name: NameCol,
+		Name: nameCol,
 	})
 
 	if opts.ShowAssetType {
@@ -434,6 +534,38 @@ func MakeAssetTable(assetType string, assets map[string]query.AssetNode, opts As
 		})
 	}
 
+	if opts.ShowCapacity {
+		for _, col := range []string{CoresCol, RAMGiBCol, GPUsCol} {
+			columnConfigs = append(columnConfigs, table.ColumnConfig{
+				Name:        col,
+				Align:       text.AlignRight,
+				AlignFooter: text.AlignRight,
+			})
+		}
+	}
+
+	if opts.ShowPricing {
+		columnConfigs = append(columnConfigs, table.ColumnConfig{
+			Name: SpotCol,
+		})
+		for _, col := range []string{DiscountCol, AdjustmentCol, CostPerCoreHourCol, CostPerGiBHourCol} {
+			columnConfigs = append(columnConfigs, table.ColumnConfig{
+				Name:        col,
+				Align:       text.AlignRight,
+				AlignFooter: text.AlignRight,
+			})
+		}
+	}
+
+	if opts.ShowIdle {
+		for _, col := range []string{CPUIdleCol, RAMIdleCol} {
+			columnConfigs = append(columnConfigs, table.ColumnConfig{
+				Name:  col,
+				Align: text.AlignRight,
+			})
+		}
+	}
+
 	if opts.ShowCPUCost {
 		columnConfigs = append(columnConfigs, table.ColumnConfig{
 			Name:        CPUCostCol,
@@ -450,32 +582,44 @@ func MakeAssetTable(assetType string, assets map[string]query.AssetNode, opts As
 		})
 	}
 
-	if projectToMonthlyRate {
-		columnConfigs = append(columnConfigs, table.ColumnConfig{
-			Name:        "Monthly Cost",
-			Align:       text.AlignRight,
-			AlignFooter: text.AlignRight,
-		})
-	} else {
+	if projection.ToDate() {
 		columnConfigs = append(columnConfigs, table.ColumnConfig{
-			Name:        "Total Cost",
+			Name:        ToDateCol,
 			Align:       text.AlignRight,
 			AlignFooter: text.AlignRight,
 		})
 	}
 
+	columnConfigs = append(columnConfigs, table.ColumnConfig{
+		Name:        costColumnName(projection),
+		Align:       text.AlignRight,
+		AlignFooter: text.AlignRight,
+	})
+
 	t.SetColumnConfigs(columnConfigs)
 
 	headerRow := table.Row{}
 
 	headerRow = append(headerRow, ClusterCol)
 
-	headerRow = append(headerRow, NameCol)
+	headerRow = append(headerRow, nameCol)
 
 	if opts.ShowAssetType {
 		headerRow = append(headerRow, AssetTypeCol)
 	}
 
+	if opts.ShowCapacity {
+		headerRow = append(headerRow, CoresCol, RAMGiBCol, GPUsCol)
+	}
+
+	if opts.ShowPricing {
+		headerRow = append(headerRow, SpotCol, DiscountCol, AdjustmentCol, CostPerCoreHourCol, CostPerGiBHourCol)
+	}
+
+	if opts.ShowIdle {
+		headerRow = append(headerRow, CPUIdleCol, RAMIdleCol)
+	}
+
 	if opts.ShowCPUCost {
 		headerRow = append(headerRow, CPUCostCol)
 	}
@@ -484,32 +628,40 @@ func MakeAssetTable(assetType string, assets map[string]query.AssetNode, opts As
 		headerRow = append(headerRow, RAMCostCol)
 	}
 
-	if projectToMonthlyRate {
-		headerRow = append(headerRow, "Monthly Cost")
-	} else {
-		headerRow = append(headerRow, "Total Cost")
+	if projection.ToDate() {
+		headerRow = append(headerRow, ToDateCol)
 	}
 
+	headerRow = append(headerRow, costColumnName(projection))
+
 	t.AppendHeader(headerRow)
 
 	var summedCost float64
+	var summedToDate float64
 	var summedCPUCost float64
 	var summedRAMCost float64
+	var summedAdjustment float64
+	var summedCores float64
+	var summedRAMBytes float64
+	var summedGPUs float64
+
+	keys := make([]string, 0, len(assets))
+	for key := range assets {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := assets[keys[i]], assets[keys[j]]
+		if a.Properties.Cluster != b.Properties.Cluster {
+			return a.Properties.Cluster < b.Properties.Cluster
+		}
+		return a.Properties.Name < b.Properties.Name
+	})
 
-	for _, asset := range assets {
+	for _, key := range keys {
+		asset := assets[key]
 
 		// This variable exists to scale costs by the active window
-		var histScaleFactor float64 = 1
-
-		if projectToMonthlyRate {
-
-			// scale by minutes per month divided by duration
-			// of window in minutes to get projected monthly cost.
-			// Note that this approach assumes the window costs will apply
-			// through the ENTIRE projected month, no matter the window size.
-			histScaleFactor = 43200 / asset.Minutes
-
-		}
+		histScaleFactor := projection.Scale(asset.Minutes)
 
 		name := asset.Properties.Name
 		cluster := asset.Properties.Cluster
@@ -525,6 +677,33 @@ func MakeAssetTable(assetType string, assets map[string]query.AssetNode, opts As
 			assetRow = append(assetRow, assetType)
 		}
 
+		if opts.ShowCapacity {
+			assetRow = append(assetRow,
+				fmtResourceFloat(asset.CpuCores),
+				fmtResourceFloat(asset.RamBytes/1024/1024/1024),
+				fmtResourceFloat(asset.GPUCount),
+			)
+			summedCores += asset.CpuCores
+			summedRAMBytes += asset.RamBytes
+			summedGPUs += asset.GPUCount
+		}
+
+		if opts.ShowPricing {
+			adjAdjustment := asset.Adjustment * histScaleFactor
+			assetRow = append(assetRow,
+				formatPreemptible(asset.Preemptible),
+				formatPercent(asset.Discount),
+				formatFloat(adjAdjustment),
+				formatUnitPrice(asset.CPUCost, asset.CPUCoreHours),
+				formatUnitPrice(asset.RAMCost, asset.RAMByteHours/1024/1024/1024),
+			)
+			summedAdjustment += adjAdjustment
+		}
+
+		if opts.ShowIdle {
+			assetRow = append(assetRow, formatPercent(asset.CPUBreakdown.Idle), formatPercent(asset.RAMBreakdown.Idle))
+		}
+
 		if opts.ShowCPUCost {
 			adjCPUCost := asset.CPUCost * histScaleFactor
 			assetRow = append(assetRow, formatFloat(adjCPUCost))
@@ -537,6 +716,11 @@ func MakeAssetTable(assetType string, assets map[string]query.AssetNode, opts As
 			summedRAMCost += adjRAMCost
 		}
 
+		if projection.ToDate() {
+			assetRow = append(assetRow, formatFloat(asset.TotalCost))
+			summedToDate += asset.TotalCost
+		}
+
 		adjTotalCost := asset.TotalCost * histScaleFactor
 		cumulativeCost := formatFloat(adjTotalCost)
 		assetRow = append(assetRow, cumulativeCost)
@@ -555,6 +739,22 @@ func MakeAssetTable(assetType string, assets map[string]query.AssetNode, opts As
 		footerRow = append(footerRow, "")
 	}
 
+	if opts.ShowCapacity {
+		footerRow = append(footerRow,
+			fmtResourceFloat(summedCores),
+			fmtResourceFloat(summedRAMBytes/1024/1024/1024),
+			fmtResourceFloat(summedGPUs),
+		)
+	}
+
+	if opts.ShowPricing {
+		footerRow = append(footerRow, "", "", fmt.Sprintf("%s %s", currencyCode, formatFloat(summedAdjustment)), "", "")
+	}
+
+	if opts.ShowIdle {
+		footerRow = append(footerRow, "", "")
+	}
+
 	if opts.ShowCPUCost {
 		footerRow = append(footerRow, fmt.Sprintf("%s %s", currencyCode, formatFloat(summedCPUCost)))
 	}
@@ -563,9 +763,38 @@ func MakeAssetTable(assetType string, assets map[string]query.AssetNode, opts As
 		footerRow = append(footerRow, fmt.Sprintf("%s %s", currencyCode, formatFloat(summedRAMCost)))
 	}
 
+	if projection.ToDate() {
+		footerRow = append(footerRow, fmt.Sprintf("%s %s", currencyCode, formatFloat(summedToDate)))
+	}
+
 	footerRow = append(footerRow, fmt.Sprintf("%s %s", currencyCode, formatFloat(summedCost)))
 
 	t.AppendFooter(footerRow)
 
 	return t
 }
+
+// formatPreemptible shows whether a node is spot or preemptible. Aggregated
+// nodes may be partly preemptible, which is shown as a percentage.
+func formatPreemptible(f float64) string {
+	switch {
+	case f <= 0:
+		return "no"
+	case f >= 1:
+		return "yes"
+	}
+	return formatPercent(f)
+}
+
+func formatPercent(f float64) string {
+	return fmt.Sprintf("%.1f%%", f*100)
+}
+
+// formatUnitPrice formats the price of one unit of a resource, e.g. one
+// core-hour, which is empty if no units were used.
+func formatUnitPrice(cost, units float64) string {
+	if units <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.4f", cost/units)
+}
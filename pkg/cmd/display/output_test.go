@@ -0,0 +1,82 @@
+package display
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/core/pkg/opencost"
+
+	"github.com/kubecost/kubectl-cost/pkg/period"
+)
+
+func TestMakeAllocationTableDirectCost(t *testing.T) {
+	allocations := map[string]opencost.Allocation{
+		"cluster-one/web": {
+			Name:       "cluster-one/web",
+			Window:     opencost.NewClosedWindow(time.Unix(0, 0), time.Unix(3600, 0)),
+			CPUCost:    3,
+			RAMCost:    1,
+			SharedCost: 1.5,
+		},
+	}
+
+	opts := AllocationDisplayOptions{ShowSharedCost: true, ShowDirectCost: true}
+	out := MakeAllocationTable([]string{"cluster", "namespace"}, allocations, opts, "USD", period.Projection{}).Render()
+	for _, want := range []string{"SHARED COST", "DIRECT COST", "1.500000", "4.000000", "5.500000"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected table to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestMakeAllocationTableProjection(t *testing.T) {
+	allocations := map[string]opencost.Allocation{
+		"cluster-one/web": {
+			Name:    "cluster-one/web",
+			Start:   time.Unix(0, 0),
+			End:     time.Unix(24*3600, 0),
+			CPUCost: 2,
+		},
+	}
+
+	out := MakeAllocationTable([]string{"cluster", "namespace"}, allocations, AllocationDisplayOptions{}, "USD", period.DefaultMonthly()).Render()
+	if !strings.Contains(out, "MONTHLY RATE (ALL)") || !strings.Contains(out, "USD 60.833333") {
+		t.Errorf("expected a day to be projected to 730 hours, got:\n%s", out)
+	}
+
+	// A day into a period with four days left, to date plus the remainder.
+	projection := period.Projection{MonthHours: 730, RemainingHours: 96}
+	out = MakeAllocationTable([]string{"cluster", "namespace"}, allocations, AllocationDisplayOptions{}, "USD", projection).Render()
+	for _, want := range []string{"TO DATE", "PROJECTED COST (ALL)", "USD 2.000000", "USD 10.000000"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected table to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestAllocationDisplayOptionsComplete(t *testing.T) {
+	opts := AllocationDisplayOptions{ShowAll: true}
+	opts.Complete()
+
+	want := AllocationDisplayOptions{
+		ShowCPUCost:          true,
+		ShowMemoryCost:       true,
+		ShowGPUCost:          true,
+		ShowPVCost:           true,
+		ShowNetworkCost:      true,
+		ShowSharedCost:       true,
+		ShowLoadBalancerCost: true,
+		ShowDirectCost:       true,
+		ShowAll:              true,
+	}
+	if opts != want {
+		t.Errorf("expected %+v, got %+v", want, opts)
+	}
+
+	opts = AllocationDisplayOptions{ShowCPUCost: true}
+	opts.Complete()
+	if opts != (AllocationDisplayOptions{ShowCPUCost: true}) {
+		t.Errorf("expected only CPU cost without --show-all-resources, got %+v", opts)
+	}
+}
@@ -50,6 +50,20 @@ func WritePredictionTable(out io.Writer, rowData []query.SpecCostDiff, currencyC
 	t.Render()
 }
 
+// WritePredictionAssumptions lists assumptions which were made to predict the
+// workloads in a prediction table, one per line below a heading. Nothing is
+// written if there are none.
+func WritePredictionAssumptions(out io.Writer, assumptions []string) {
+	if len(assumptions) == 0 {
+		return
+	}
+
+	fmt.Fprintf(out, "\nAssumptions:\n")
+	for _, a := range assumptions {
+		fmt.Fprintf(out, "  - %s\n", a)
+	}
+}
+
 // fmtResourceFloat formats with a precision of 2 and then trims trailing 0s in
 // the decimal places.
 func fmtResourceFloat(x float64) string {
@@ -338,6 +352,29 @@ func MakePredictionTable(specDiffs []query.SpecCostDiff, currencyCode string, op
 			}
 			t.AppendRow(row)
 		}
+
+		if !(specData.CostBefore.PVMonthlyRate == 0 && specData.CostAfter.PVMonthlyRate == 0) {
+			units := "PV GiB"
+			avgUnitsNew := specData.CostAfter.MonthlyPVByteHours / timeutil.HoursPerMonth
+			avgUnitsDiff := specData.CostChange.MonthlyPVByteHours / timeutil.HoursPerMonth
+			factor := 1.0 / (1024 * 1024 * 1024)
+			avgUnitsDiff *= factor
+			avgUnitsNew *= factor
+			costPerUnit := specData.CostChange.PVMonthlyRate / avgUnitsDiff
+			row := table.Row{
+				workloadName,
+				avgUnitsNew,
+				avgUnitsDiff,
+				units,
+				costPerUnit,
+				specData.CostAfter.PVMonthlyRate,
+				specData.CostChange.PVMonthlyRate,
+			}
+			if specData.CostBefore.PVMonthlyRate != 0 {
+				row = append(row, specData.CostChange.PVMonthlyRate/specData.CostBefore.PVMonthlyRate*100)
+			}
+			t.AppendRow(row)
+		}
 		t.AppendSeparator()
 	}
 
@@ -353,3 +390,93 @@ func MakePredictionTable(specDiffs []query.SpecCostDiff, currencyCode string, op
 
 	return t
 }
+
+// ReplicaRangeCost is the predicted cost of an autoscaled workload at the
+// bounds of its replica range and at its current replica count.
+type ReplicaRangeCost struct {
+	Namespace      string
+	ControllerKind string
+	ControllerName string
+
+	Autoscaler string
+
+	MinReplicas int32
+	MaxReplicas int32
+
+	// CurrentReplicas is nil if the autoscaler hasn't been observed in the
+	// cluster.
+	CurrentReplicas *int32
+
+	MonthlyRatePerReplica float64
+}
+
+func WriteReplicaRangeTable(out io.Writer, ranges []ReplicaRangeCost, currencyCode string) {
+	t := MakeReplicaRangeTable(ranges, currencyCode)
+	t.SetOutputMirror(out)
+	t.Render()
+}
+
+// MakeReplicaRangeTable shows the monthly cost of autoscaled workloads at
+// their minimum, current and maximum replica counts. The prediction table
+// only reflects spec.replicas, which autoscalers override.
+func MakeReplicaRangeTable(ranges []ReplicaRangeCost, currencyCode string) table.Writer {
+	t := table.NewWriter()
+
+	style := table.StyleLight
+	style.Options.SeparateColumns = false
+	style.Options.DrawBorder = false
+	style.Options.SeparateHeader = true
+	style.Title.Colors = append(style.Title.Colors, text.Bold)
+	t.SetStyle(style)
+	t.SetTitle("Autoscaled workloads")
+
+	costTransformer := func(val interface{}) string {
+		if f, ok := val.(float64); ok {
+			return fmt.Sprintf("%s %s", fmtOverallCostFloat(f), currencyCode)
+		}
+		if s, ok := val.(string); ok {
+			return s
+		}
+		return "invalid value"
+	}
+
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: ColObject, Align: text.AlignLeft, WidthMax: 26, WidthMaxEnforcer: text.WrapSoft},
+		{Name: "autoscaler", Align: text.AlignLeft},
+		{Name: "min", Align: text.AlignRight},
+		{Name: "cost/mo at min", Align: text.AlignRight, Transformer: costTransformer},
+		{Name: "current", Align: text.AlignRight},
+		{Name: "cost/mo at current", Align: text.AlignRight, Transformer: costTransformer},
+		{Name: "max", Align: text.AlignRight},
+		{Name: "cost/mo at max", Align: text.AlignRight, Transformer: costTransformer},
+	})
+
+	t.AppendHeader(table.Row{
+		ColObject,
+		"autoscaler",
+		"min",
+		"cost/mo at min",
+		"current",
+		"cost/mo at current",
+		"max",
+		"cost/mo at max",
+	})
+
+	for _, r := range ranges {
+		row := table.Row{
+			fmt.Sprintf("%s %s %s", r.Namespace, r.ControllerKind, r.ControllerName),
+			r.Autoscaler,
+			r.MinReplicas,
+			float64(r.MinReplicas) * r.MonthlyRatePerReplica,
+		}
+		if r.CurrentReplicas != nil {
+			row = append(row, *r.CurrentReplicas, float64(*r.CurrentReplicas)*r.MonthlyRatePerReplica)
+		} else {
+			row = append(row, "-", "-")
+		}
+		row = append(row, r.MaxReplicas, float64(r.MaxReplicas)*r.MonthlyRatePerReplica)
+		t.AppendRow(row)
+	}
+
+	return t
+}
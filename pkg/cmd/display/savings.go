@@ -1,23 +1,221 @@
 package display
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"io"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 
 	"github.com/kubecost/kubectl-cost/pkg/query"
+	"github.com/kubecost/kubectl-cost/pkg/rightsizing"
 )
 
-func WriteSavingsTable(out io.Writer, recs []query.RequestSizingRecommendation, currencyCode string) {
-	t := MakeSavingsTable(recs, currencyCode)
+// SavingsSortKeys are the values supported by SavingsDisplayOptions.SortBy.
+var SavingsSortKeys = []string{"savings", "namespace", "controller", "cpu-efficiency", "ram-efficiency"}
+
+type SavingsDisplayOptions struct {
+	// SortBy is one of SavingsSortKeys. Savings are sorted in descending
+	// order, efficiencies in ascending order so that the least efficient
+	// containers come first. Defaults to savings.
+	SortBy string
+
+	// Top limits the table to the first Top rows after sorting, if positive.
+	Top int
+}
+
+func AddSavingsDisplayOptionsFlags(cmd *cobra.Command, options *SavingsDisplayOptions) {
+	cmd.Flags().StringVar(&options.SortBy, "sort-by", "savings", fmt.Sprintf("The column to sort recommendations by, one of: %s.", strings.Join(SavingsSortKeys, ", ")))
+	cmd.Flags().IntVar(&options.Top, "top", 0, "If positive, only show this many recommendations after sorting.")
+}
+
+func (o *SavingsDisplayOptions) Validate() error {
+	if o.SortBy != "" && !slices.Contains(SavingsSortKeys, o.SortBy) {
+		return fmt.Errorf("unsupported sort key '%s', must be one of: %s", o.SortBy, strings.Join(SavingsSortKeys, ", "))
+	}
+	if o.Top < 0 {
+		return fmt.Errorf("--top cannot be negative")
+	}
+	return nil
+}
+
+func WriteSavingsTable(out io.Writer, recs []query.RequestSizingRecommendation, currencyCode string, opts SavingsDisplayOptions) {
+	t := MakeSavingsTable(recs, currencyCode, opts)
 	t.SetOutputMirror(out)
 	t.Render()
 }
 
-func MakeSavingsTable(recs []query.RequestSizingRecommendation, currencyCode string) table.Writer {
+// SortSavings returns a sorted copy of recs, limited to the top recommendations
+// as configured by opts.
+func SortSavings(recs []query.RequestSizingRecommendation, opts SavingsDisplayOptions) []query.RequestSizingRecommendation {
+	savings := func(r query.RequestSizingRecommendation) float64 {
+		return r.MonthlySavings.CPU + r.MonthlySavings.Memory
+	}
+	controller := func(r query.RequestSizingRecommendation) string {
+		return fmt.Sprintf("%s/%s/%s", r.ControllerKind, r.ControllerName, r.ContainerName)
+	}
+
+	sorted := make([]query.RequestSizingRecommendation, len(recs))
+	copy(sorted, recs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		switch opts.SortBy {
+		case "namespace":
+			if a.Namespace != b.Namespace {
+				return a.Namespace < b.Namespace
+			}
+		case "controller":
+			if controller(a) != controller(b) {
+				return controller(a) < controller(b)
+			}
+		case "cpu-efficiency":
+			if a.CurrentEfficiency.CPU != b.CurrentEfficiency.CPU {
+				return a.CurrentEfficiency.CPU < b.CurrentEfficiency.CPU
+			}
+		case "ram-efficiency":
+			if a.CurrentEfficiency.Memory != b.CurrentEfficiency.Memory {
+				return a.CurrentEfficiency.Memory < b.CurrentEfficiency.Memory
+			}
+		}
+		return savings(a) > savings(b)
+	})
+
+	if opts.Top > 0 && len(sorted) > opts.Top {
+		sorted = sorted[:opts.Top]
+	}
+	return sorted
+}
+
+// SavingsReportFormats are the formats supported by WriteSavingsReport.
+var SavingsReportFormats = []string{"json", "yaml", "csv", "markdown"}
+
+// SavingsReport is the machine-readable form of a savings table.
+type SavingsReport struct {
+	CurrencyCode    string                 `json:"currencyCode"`
+	Totals          SavingsTotals          `json:"totals"`
+	Recommendations []SavingsReportElement `json:"recommendations"`
+}
+
+// SavingsTotals are the sums over all recommendations of a report.
+type SavingsTotals struct {
+	Recommendations      int     `json:"recommendations"`
+	MonthlyCPUSavings    float64 `json:"monthlyCPUSavings"`
+	MonthlyMemorySavings float64 `json:"monthlyMemorySavings"`
+	MonthlySavings       float64 `json:"monthlySavings"`
+}
+
+// SavingsReportElement is a recommendation with its total savings.
+type SavingsReportElement struct {
+	query.RequestSizingRecommendation
+
+	TotalMonthlySavings float64 `json:"totalMonthlySavings"`
+}
+
+// NewSavingsReport sorts and limits recs like MakeSavingsTable, and sums up
+// their savings.
+func NewSavingsReport(recs []query.RequestSizingRecommendation, currencyCode string, opts SavingsDisplayOptions) SavingsReport {
+	report := SavingsReport{
+		CurrencyCode:    currencyCode,
+		Recommendations: []SavingsReportElement{},
+	}
+	for _, rec := range SortSavings(recs, opts) {
+		total := rec.MonthlySavings.CPU + rec.MonthlySavings.Memory
+		report.Recommendations = append(report.Recommendations, SavingsReportElement{
+			RequestSizingRecommendation: rec,
+			TotalMonthlySavings:         total,
+		})
+		report.Totals.Recommendations++
+		report.Totals.MonthlyCPUSavings += rec.MonthlySavings.CPU
+		report.Totals.MonthlyMemorySavings += rec.MonthlySavings.Memory
+		report.Totals.MonthlySavings += total
+	}
+	return report
+}
+
+// WriteSavingsReport writes recs in one of SavingsReportFormats. Unlike the
+// table, JSON, YAML and CSV contain unformatted values. CSV has a row per
+// recommendation and no totals.
+func WriteSavingsReport(out io.Writer, format string, recs []query.RequestSizingRecommendation, currencyCode string, opts SavingsDisplayOptions) error {
+	report := NewSavingsReport(recs, currencyCode, opts)
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "yaml":
+		b, err := yaml.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("marshaling report: %s", err)
+		}
+		_, err = out.Write(b)
+		return err
+	case "csv":
+		return writeSavingsCSV(out, report)
+	case "markdown":
+		t := MakeSavingsTable(recs, currencyCode, opts)
+		_, err := fmt.Fprintln(out, t.RenderMarkdown())
+		return err
+	}
+	return fmt.Errorf("unsupported format '%s', must be one of: %s", format, strings.Join(SavingsReportFormats, ", "))
+}
+
+func writeSavingsCSV(out io.Writer, report SavingsReport) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{
+		"cluster",
+		"namespace",
+		"controllerKind",
+		"controllerName",
+		"container",
+		"latestKnownCPURequest",
+		"recommendedCPURequest",
+		"latestKnownMemoryRequest",
+		"recommendedMemoryRequest",
+		"cpuEfficiency",
+		"memoryEfficiency",
+		"monthlyCPUSavings",
+		"monthlyMemorySavings",
+		"totalMonthlySavings",
+		"currency",
+	})
+
+	float := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	for _, rec := range report.Recommendations {
+		w.Write([]string{
+			rec.ClusterID,
+			rec.Namespace,
+			rec.ControllerKind,
+			rec.ControllerName,
+			rec.ContainerName,
+			rec.LatestKnownRequest.CPU,
+			rec.RecommendedRequest.CPU,
+			rec.LatestKnownRequest.Memory,
+			rec.RecommendedRequest.Memory,
+			float(rec.CurrentEfficiency.CPU),
+			float(rec.CurrentEfficiency.Memory),
+			float(rec.MonthlySavings.CPU),
+			float(rec.MonthlySavings.Memory),
+			float(rec.TotalMonthlySavings),
+			report.CurrencyCode,
+		})
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func MakeSavingsTable(recs []query.RequestSizingRecommendation, currencyCode string, opts SavingsDisplayOptions) table.Writer {
 	t := table.NewWriter()
 
 	style := table.StyleLight
@@ -65,14 +263,7 @@ func MakeSavingsTable(recs []query.RequestSizingRecommendation, currencyCode str
 		"Savings/mo",
 	})
 
-	// Pre-sort by total monthly savings descending
-	sorted := make([]query.RequestSizingRecommendation, len(recs))
-	copy(sorted, recs)
-	sort.Slice(sorted, func(i, j int) bool {
-		si := sorted[i].MonthlySavings.CPU + sorted[i].MonthlySavings.Memory
-		sj := sorted[j].MonthlySavings.CPU + sorted[j].MonthlySavings.Memory
-		return si > sj
-	})
+	sorted := SortSavings(recs, opts)
 
 	totalSavings := 0.0
 	for _, rec := range sorted {
@@ -101,3 +292,27 @@ func MakeSavingsTable(recs []query.RequestSizingRecommendation, currencyCode str
 
 	return t
 }
+
+// WriteResourceDiff writes the changes to the container resources of a
+// controller, below a heading naming the controller and a note, e.g. its
+// estimated savings. Unset values are shown as <none>.
+func WriteResourceDiff(out io.Writer, kind, namespace, name, note string, containers []rightsizing.ContainerDiff) {
+	heading := fmt.Sprintf("%s %s/%s", strings.ToLower(kind), namespace, name)
+	if note != "" {
+		heading = fmt.Sprintf("%s (%s)", heading, note)
+	}
+	fmt.Fprintln(out, heading)
+
+	orNone := func(s string) string {
+		if s == "" {
+			return "<none>"
+		}
+		return s
+	}
+	for _, c := range containers {
+		fmt.Fprintf(out, "  container %s\n", c.Name)
+		for _, change := range c.Changes {
+			fmt.Fprintf(out, "    %-16s %s -> %s\n", change.Field, orNone(change.From), orNone(change.To))
+		}
+	}
+}
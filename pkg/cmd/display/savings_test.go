@@ -2,14 +2,19 @@ package display
 
 import (
 	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/kubecost/kubectl-cost/pkg/query"
 )
 
+var update = flag.Bool("update", false, "update golden files in testdata")
+
 func TestMakeSavingsTable_Empty(t *testing.T) {
-	tw := MakeSavingsTable(nil, "USD")
+	tw := MakeSavingsTable(nil, "USD", SavingsDisplayOptions{})
 	out := tw.Render()
 
 	if !strings.Contains(strings.ToUpper(out), "NAMESPACE") {
@@ -51,7 +56,7 @@ func TestMakeSavingsTable_SingleRec(t *testing.T) {
 		},
 	}
 
-	tw := MakeSavingsTable(recs, "EUR")
+	tw := MakeSavingsTable(recs, "EUR", SavingsDisplayOptions{})
 	out := tw.Render()
 
 	checks := []string{
@@ -123,7 +128,7 @@ func TestMakeSavingsTable_MultipleRecs_SortedBySavings(t *testing.T) {
 		},
 	}
 
-	tw := MakeSavingsTable(recs, "USD")
+	tw := MakeSavingsTable(recs, "USD", SavingsDisplayOptions{})
 	out := tw.Render()
 
 	// big-saver (30.00) should appear before small-saver (1.50) due to DscNumeric sort
@@ -150,7 +155,7 @@ func TestMakeSavingsTable_MultipleRecs_SortedBySavings(t *testing.T) {
 
 func TestWriteSavingsTable_WritesToOutput(t *testing.T) {
 	var buf bytes.Buffer
-	WriteSavingsTable(&buf, nil, "USD")
+	WriteSavingsTable(&buf, nil, "USD", SavingsDisplayOptions{})
 
 	out := buf.String()
 	if len(out) == 0 {
@@ -160,3 +165,103 @@ func TestWriteSavingsTable_WritesToOutput(t *testing.T) {
 		t.Errorf("expected header in output, got:\n%s", out)
 	}
 }
+
+func TestSortSavings(t *testing.T) {
+	rec := func(namespace, name string, savings, cpuEfficiency float64) query.RequestSizingRecommendation {
+		r := query.RequestSizingRecommendation{
+			Namespace:      namespace,
+			ControllerKind: "Deployment",
+			ControllerName: name,
+			ContainerName:  "app",
+		}
+		r.MonthlySavings.CPU = savings
+		r.CurrentEfficiency.CPU = cpuEfficiency
+		return r
+	}
+	recs := []query.RequestSizingRecommendation{
+		rec("b", "one", 1, 0.5),
+		rec("a", "two", 3, 0.9),
+		rec("b", "three", 2, 0.1),
+		rec("a", "four", 4, 0.3),
+	}
+
+	cases := map[string]struct {
+		opts     SavingsDisplayOptions
+		expected []string
+	}{
+		"default":        {SavingsDisplayOptions{}, []string{"four", "two", "three", "one"}},
+		"top":            {SavingsDisplayOptions{Top: 2}, []string{"four", "two"}},
+		"namespace":      {SavingsDisplayOptions{SortBy: "namespace"}, []string{"four", "two", "three", "one"}},
+		"controller":     {SavingsDisplayOptions{SortBy: "controller"}, []string{"four", "one", "three", "two"}},
+		"cpu-efficiency": {SavingsDisplayOptions{SortBy: "cpu-efficiency", Top: 3}, []string{"three", "four", "one"}},
+	}
+	for name, c := range cases {
+		sorted := SortSavings(recs, c.opts)
+		var names []string
+		for _, r := range sorted {
+			names = append(names, r.ControllerName)
+		}
+		if strings.Join(names, ",") != strings.Join(c.expected, ",") {
+			t.Errorf("%s: expected %v, got %v", name, c.expected, names)
+		}
+	}
+}
+
+func TestWriteSavingsReport_Golden(t *testing.T) {
+	rec := func(namespace, name, container string, cpuSavings, memorySavings float64) query.RequestSizingRecommendation {
+		r := query.RequestSizingRecommendation{
+			ClusterID:      "cluster-one",
+			Namespace:      namespace,
+			ControllerKind: "deployment",
+			ControllerName: name,
+			ContainerName:  container,
+		}
+		r.RecommendedRequest.CPU = "100m"
+		r.RecommendedRequest.Memory = "128Mi"
+		r.LatestKnownRequest.CPU = "500m"
+		r.LatestKnownRequest.Memory = "512Mi"
+		r.MonthlySavings.CPU = cpuSavings
+		r.MonthlySavings.Memory = memorySavings
+		r.CurrentEfficiency.CPU = 0.2
+		r.CurrentEfficiency.Memory = 0.25
+		r.CurrentEfficiency.Total = 0.225
+		return r
+	}
+	recs := []query.RequestSizingRecommendation{
+		rec("default", "web", "nginx", 1.5, 0.25),
+		rec("prod", "api", "app", 5.5, 2.3),
+	}
+
+	for format, file := range map[string]string{
+		"json":     "savings.json",
+		"yaml":     "savings.yaml",
+		"csv":      "savings.csv",
+		"markdown": "savings.md",
+	} {
+		var buf bytes.Buffer
+		if err := WriteSavingsReport(&buf, format, recs, "USD", SavingsDisplayOptions{}); err != nil {
+			t.Fatalf("%s: unexpected error: %s", format, err)
+		}
+
+		path := filepath.Join("testdata", file)
+		if *update {
+			if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+				t.Fatalf("updating %s: %s", path, err)
+			}
+		}
+		expected, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading %s: %s", path, err)
+		}
+		if buf.String() != string(expected) {
+			t.Errorf("%s: output differs from %s, got:\n%s", format, path, buf.String())
+		}
+	}
+}
+
+func TestWriteSavingsReport_UnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSavingsReport(&buf, "xml", nil, "USD", SavingsDisplayOptions{}); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
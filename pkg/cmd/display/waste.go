@@ -0,0 +1,121 @@
+package display
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	"github.com/kubecost/kubectl-cost/pkg/waste"
+)
+
+func plainTableStyle() table.Style {
+	style := table.StyleLight
+	style.Options.SeparateColumns = false
+	style.Options.DrawBorder = false
+	style.Options.SeparateHeader = true
+	style.Title.Colors = append(style.Title.Colors, text.Bold)
+	return style
+}
+
+func WriteAbandonedTable(out io.Writer, workloads []waste.AbandonedWorkload, currencyCode string) {
+	t := MakeAbandonedTable(workloads, currencyCode)
+	t.SetOutputMirror(out)
+	t.Render()
+}
+
+func MakeAbandonedTable(workloads []waste.AbandonedWorkload, currencyCode string) table.Writer {
+	t := table.NewWriter()
+	t.SetStyle(plainTableStyle())
+
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Cluster", Align: text.AlignLeft},
+		{Name: "Namespace", Align: text.AlignLeft},
+		{Name: "Controller", Align: text.AlignLeft, WidthMax: 40, WidthMaxEnforcer: text.WrapSoft},
+		{Name: "Avg. CPU", Align: text.AlignRight},
+		{Name: "Avg. Traffic", Align: text.AlignRight},
+		{Name: "Cost/mo", Align: text.AlignRight},
+	})
+
+	t.AppendHeader(table.Row{"Cluster", "Namespace", "Controller", "Avg. CPU", "Avg. Traffic", "Cost/mo"})
+
+	total := 0.0
+	for _, w := range workloads {
+		total += w.MonthlyRate
+		t.AppendRow(table.Row{
+			w.Cluster,
+			w.Namespace,
+			fmt.Sprintf("%s/%s", w.ControllerKind, w.ControllerName),
+			fmt.Sprintf("%.0fm", w.CPUCoreUsageAverage*1000),
+			fmt.Sprintf("%s B/s", fmtResourceFloat(w.TrafficBytesPerSecond)),
+			fmt.Sprintf("%.2f %s", w.MonthlyRate, currencyCode),
+		})
+	}
+
+	t.AppendFooter(table.Row{"TOTAL", "", "", "", "", fmt.Sprintf("%.2f %s", total, currencyCode)})
+
+	return t
+}
+
+func WriteUnclaimedVolumesTable(out io.Writer, volumes []waste.UnclaimedVolume, currencyCode string) {
+	t := MakeUnclaimedVolumesTable(volumes, currencyCode)
+	t.SetOutputMirror(out)
+	t.Render()
+}
+
+// MakeUnclaimedVolumesTable lists unclaimed volumes. Costs estimated from the
+// price of the volume's StorageClass are marked with a '*'.
+func MakeUnclaimedVolumesTable(volumes []waste.UnclaimedVolume, currencyCode string) table.Writer {
+	t := table.NewWriter()
+	t.SetStyle(plainTableStyle())
+
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Volume", Align: text.AlignLeft},
+		{Name: "Claim", Align: text.AlignLeft},
+		{Name: "Phase", Align: text.AlignLeft},
+		{Name: "Storage Class", Align: text.AlignLeft},
+		{Name: "Size", Align: text.AlignRight},
+		{Name: "Used in Window", Align: text.AlignLeft},
+		{Name: "Cost/mo", Align: text.AlignRight},
+	})
+
+	t.AppendHeader(table.Row{"Volume", "Claim", "Phase", "Storage Class", "Size", "Used in Window", "Cost/mo"})
+
+	total := 0.0
+	estimated := false
+	for _, v := range volumes {
+		total += v.MonthlyRate
+
+		claim := ""
+		if v.ClaimName != "" {
+			claim = fmt.Sprintf("%s/%s", v.ClaimNamespace, v.ClaimName)
+		}
+		used := "no"
+		if v.UsedInWindow {
+			used = "yes"
+		}
+		cost := fmt.Sprintf("%.2f %s", v.MonthlyRate, currencyCode)
+		if v.Estimated {
+			cost += "*"
+			estimated = true
+		}
+
+		t.AppendRow(table.Row{
+			v.Name,
+			claim,
+			string(v.Phase),
+			v.StorageClass,
+			fmt.Sprintf("%s GiB", fmtResourceFloat(float64(v.Bytes)/1024/1024/1024)),
+			used,
+			cost,
+		})
+	}
+
+	t.AppendFooter(table.Row{"TOTAL", "", "", "", "", "", fmt.Sprintf("%.2f %s", total, currencyCode)})
+	if estimated {
+		t.SetCaption("* estimated from the average price of the volume's StorageClass")
+	}
+
+	return t
+}
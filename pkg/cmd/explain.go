@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubecost/kubectl-cost/pkg/cmd/display"
+	"github.com/kubecost/kubectl-cost/pkg/cmd/utilities"
+	"github.com/kubecost/kubectl-cost/pkg/explain"
+	"github.com/kubecost/kubectl-cost/pkg/query"
+	"github.com/opencost/opencost/core/pkg/log"
+)
+
+var explainExample = `
+    # Explain what the api deployment in the prod namespace cost over the
+    # last week.
+    %[1]s cost explain deployment/api -n prod --window 7d
+
+    # Explain a pod's cost, with idle cost shared by node and kube-system
+    # shared as overhead.
+    %[1]s cost explain pod/api-7c9f8d-x2x4z -n prod --idle-by node --share-namespaces kube-system
+`
+
+// ExplainOptions contains the standard CostOptions and the workload to
+// explain.
+type ExplainOptions struct {
+	namespace string
+	workload  explain.Workload
+
+	CostOptions
+}
+
+func newCmdCostExplain(streams genericclioptions.IOStreams) *cobra.Command {
+	kubeO := utilities.NewKubeOptions(streams)
+	explainO := &ExplainOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "explain KIND/NAME",
+		Short:   "explain what a single workload's cost is derived from",
+		Example: fmt.Sprintf(explainExample, "kubectl"),
+		Args:    cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := kubeO.Complete(c, args); err != nil {
+				return err
+			}
+			if err := kubeO.Validate(); err != nil {
+				return err
+			}
+
+			workload, err := explain.ParseWorkload(args[0])
+			if err != nil {
+				return err
+			}
+			explainO.workload = workload
+			if explainO.namespace == "" {
+				explainO.namespace = kubeO.DefaultNamespace
+			}
+
+			if err := explainO.Complete(kubeO.RestConfig); err != nil {
+				return fmt.Errorf("completing options: %s", err)
+			}
+			if err := explainO.Validate(); err != nil {
+				return err
+			}
+
+			return runCostExplain(kubeO, explainO)
+		},
+	}
+
+	cmd.Flags().StringVarP(&explainO.namespace, "namespace", "n", "", "The namespace of the workload. Defaults to the current namespace.")
+	cmd.Flags().StringVar(&explainO.window, "window", "7d", "The window of data to query. See https://github.com/kubecost/docs/blob/master/allocation.md#querying for a detailed explanation of what can be passed here.")
+	cmd.Flags().StringVar(&explainO.idleBy, "idle-by", "cluster", "Share idle cost computed by 'cluster' or by 'node'.")
+	addShareOptionsFlags(cmd, &explainO.CostOptions)
+	query.AddQueryBackendOptionsFlags(cmd, &explainO.QueryBackendOptions)
+	utilities.AddKubeOptionsFlags(cmd, kubeO)
+
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func runCostExplain(ko *utilities.KubeOptions, o *ExplainOptions) error {
+	currencyCode, err := query.QueryCurrencyCode(query.CurrencyCodeParameters{
+		Ctx:                 context.Background(),
+		QueryBackendOptions: o.QueryBackendOptions,
+	})
+	if err != nil {
+		log.Debugf("failed to get currency code, displaying as empty string: %s", err)
+		currencyCode = ""
+	}
+
+	// The workload's own cost is queried without idle, which is shared from
+	// the idle and allocated cost of its clusters or nodes instead.
+	params := o.workload.QueryParams(o.namespace)
+	params["window"] = o.window
+	params["aggregate"] = "cluster,node,pod,container"
+	o.addShareQueryParams(params)
+	allocations, err := queryAccumulatedAllocations(o.QueryBackendOptions, params)
+	if err != nil {
+		return err
+	}
+
+	o.includeIdle = true
+	clusterParams := map[string]string{
+		"window":    o.window,
+		"aggregate": "cluster",
+	}
+	if o.idleBy == "node" {
+		clusterParams["aggregate"] = "cluster,node"
+	}
+	o.addIdleQueryParams(clusterParams)
+	clusterAllocations, err := queryAccumulatedAllocations(o.QueryBackendOptions, clusterParams)
+	if err != nil {
+		return err
+	}
+
+	e, err := explain.Explain(o.workload, o.namespace, allocations, clusterAllocations)
+	if err != nil {
+		return fmt.Errorf("%s over window '%s'", err, o.window)
+	}
+
+	display.WriteExplanation(ko.Out, e, o.window, currencyCode)
+	return nil
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubecost/kubectl-cost/pkg/cmd/display"
+	"github.com/kubecost/kubectl-cost/pkg/cmd/utilities"
+	"github.com/kubecost/kubectl-cost/pkg/idle"
+	"github.com/kubecost/kubectl-cost/pkg/query"
+	"github.com/opencost/opencost/core/pkg/log"
+)
+
+var idleExample = `
+    # Show the projected monthly idle cost of each cluster.
+    %[1]s cost idle
+
+    # Show the idle cost of each node over the last week.
+    %[1]s cost idle --idle-by node --window 7d --historical
+`
+
+func newCmdCostIdle(streams genericclioptions.IOStreams) *cobra.Command {
+	kubeO := utilities.NewKubeOptions(streams)
+	idleO := &CostOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "idle",
+		Short:   "view idle cost by cluster or node, broken down into CPU, RAM and GPU",
+		Example: fmt.Sprintf(idleExample, "kubectl"),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := kubeO.Complete(c, args); err != nil {
+				return err
+			}
+			if err := kubeO.Validate(); err != nil {
+				return err
+			}
+
+			if err := idleO.Complete(kubeO.RestConfig); err != nil {
+				return fmt.Errorf("completing options: %s", err)
+			}
+			if err := idleO.Validate(); err != nil {
+				return err
+			}
+
+			return runCostIdle(kubeO, idleO)
+		},
+	}
+
+	cmd.Flags().StringVar(&idleO.window, "window", "1d", "The window of data to query. See https://github.com/kubecost/docs/blob/master/allocation.md#querying for a detailed explanation of what can be passed here.")
+	cmd.Flags().BoolVar(&idleO.isHistorical, "historical", false, "show the total cost during the window instead of the projected monthly rate based on the data in the window")
+	cmd.Flags().StringVar(&idleO.idleBy, "idle-by", "cluster", "Show idle cost by 'cluster' or by 'node'.")
+	addPeriodOptionsFlags(cmd, idleO)
+	query.AddQueryBackendOptionsFlags(cmd, &idleO.QueryBackendOptions)
+	utilities.AddKubeOptionsFlags(cmd, kubeO)
+
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func runCostIdle(ko *utilities.KubeOptions, o *CostOptions) error {
+	currencyCode, err := query.QueryCurrencyCode(query.CurrencyCodeParameters{
+		Ctx:                 context.Background(),
+		QueryBackendOptions: o.QueryBackendOptions,
+	})
+	if err != nil {
+		log.Debugf("failed to get currency code, displaying as empty string: %s", err)
+		currencyCode = ""
+	}
+
+	byNode := o.idleBy == "node"
+	aggregate := "cluster"
+	if byNode {
+		aggregate = "cluster,node"
+	}
+
+	o.includeIdle = true
+	params := map[string]string{
+		"window":    o.window,
+		"aggregate": aggregate,
+	}
+	o.addIdleQueryParams(params)
+
+	allocations, err := queryAccumulatedAllocations(o.QueryBackendOptions, params)
+	if err != nil {
+		return err
+	}
+
+	display.WriteIdleTable(ko.Out, idle.Costs(allocations), byNode, currencyCode, o.costProjection)
+	return nil
+}
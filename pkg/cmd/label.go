@@ -51,6 +51,12 @@ func newCmdCostLabel(streams genericclioptions.IOStreams) *cobra.Command {
 				return err
 			}
 
+			if labelO.sharing() {
+				labelO.ShowSharedCost = true
+				labelO.ShowDirectCost = true
+			}
+			labelO.AllocationDisplayOptions.Complete()
+
 			return runCostLabel(kubeO, labelO)
 		},
 	}
@@ -59,6 +65,8 @@ func newCmdCostLabel(streams genericclioptions.IOStreams) *cobra.Command {
 	cmd.MarkFlagRequired("label")
 
 	addCostOptionsFlags(cmd, &labelO.CostOptions)
+	addIdleOptionsFlags(cmd, &labelO.CostOptions)
+	addShareOptionsFlags(cmd, &labelO.CostOptions)
 	display.AddAllocationDisplayOptionsFlags(cmd, &labelO.AllocationDisplayOptions)
 	utilities.AddKubeOptionsFlags(cmd, kubeO)
 
@@ -78,15 +86,17 @@ func runCostLabel(ko *utilities.KubeOptions, no *CostOptionsLabel) error {
 		currencyCode = ""
 	}
 
+	params := map[string]string{
+		"window":     no.window,
+		"aggregate":  strings.Join(aggregation, ","),
+		"accumulate": "true",
+	}
+	no.addIdleQueryParams(params)
+	no.addShareQueryParams(params)
+
 	allocations, err := query.QueryAllocation(query.AllocationParameters{
-		Ctx: context.Background(),
-		QueryParams: map[string]string{
-			"window":      no.window,
-			"aggregate":   strings.Join(aggregation, ","),
-			"accumulate":  "true",
-			"includeIdle": fmt.Sprintf("%t", no.includeIdle),
-			"idle":        fmt.Sprintf("%t", no.includeIdle),
-		},
+		Ctx:                 context.Background(),
+		QueryParams:         params,
 		QueryBackendOptions: no.QueryBackendOptions,
 	})
 	if err != nil {
@@ -94,7 +104,7 @@ func runCostLabel(ko *utilities.KubeOptions, no *CostOptionsLabel) error {
 	}
 
 	// Use allocations[0] because the query accumulates to a single result
-	display.WriteAllocationTable(ko.Out, aggregation, allocations[0], no.AllocationDisplayOptions, currencyCode, !no.isHistorical)
+	display.WriteAllocationTable(ko.Out, aggregation, no.shareIdleCost(allocations[0]), no.AllocationDisplayOptions, currencyCode, no.costProjection)
 
 	return nil
 }
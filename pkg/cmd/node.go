@@ -8,6 +8,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/kubecost/kubectl-cost/pkg/capacity"
 	"github.com/kubecost/kubectl-cost/pkg/cmd/display"
 	"github.com/kubecost/kubectl-cost/pkg/cmd/utilities"
 	"github.com/kubecost/kubectl-cost/pkg/query"
@@ -19,6 +20,11 @@ import (
 type CostOptionsNode struct {
 	CostOptions
 	display.AssetDisplayOptions
+
+	aggregate   string
+	aggregation capacity.NodeAggregation
+
+	utilization bool
 }
 
 func newCmdCostNode(streams genericclioptions.IOStreams) *cobra.Command {
@@ -45,10 +51,25 @@ func newCmdCostNode(streams genericclioptions.IOStreams) *cobra.Command {
 				return err
 			}
 
+			if assetsO.utilization && assetsO.aggregate != "" {
+				return fmt.Errorf("--utilization and --aggregate cannot be used together")
+			}
+			if assetsO.aggregate != "" {
+				agg, err := capacity.ParseNodeAggregation(assetsO.aggregate)
+				if err != nil {
+					return err
+				}
+				assetsO.aggregation = agg
+				assetsO.NameColumn = agg.String()
+			}
+			assetsO.AssetDisplayOptions.Complete()
+
 			return runCostNode(kubeO, assetsO)
 		},
 	}
 
+	cmd.Flags().StringVar(&assetsO.aggregate, "aggregate", "", "Aggregate nodes by 'label:<name>', 'instance-type' or 'provider' to compare groups of nodes, e.g. node pools with 'label:cloud.google.com/gke-nodepool'.")
+	cmd.Flags().BoolVar(&assetsO.utilization, "utilization", false, "Show each node's cost with its requested and used CPU and RAM against capacity, its idle cost unless --idle=false, and the three namespaces which cost the most on it.")
 	addCostOptionsFlags(cmd, &assetsO.CostOptions)
 	display.AddAssetDisplayOptionsFlags(cmd, &assetsO.AssetDisplayOptions)
 	utilities.AddKubeOptionsFlags(cmd, kubeO)
@@ -66,6 +87,10 @@ func runCostNode(ko *utilities.KubeOptions, no *CostOptionsNode) error {
 		currencyCode = ""
 	}
 
+	if no.utilization {
+		return runCostNodeUtilization(ko, no, currencyCode)
+	}
+
 	assets, err := query.QueryAssets(query.AssetParameters{
 		Ctx:                 context.Background(),
 		Window:              no.window,
@@ -78,7 +103,30 @@ func runCostNode(ko *utilities.KubeOptions, no *CostOptionsNode) error {
 	}
 
 	// Use assets[0] because the query accumulates to a single result
-	display.WriteAssetTable(ko.Out, "Node", assets[0], no.AssetDisplayOptions, currencyCode, !no.isHistorical)
+	nodes := assets[0]
+	if no.aggregate != "" {
+		nodes = capacity.AggregateNodeAssets(nodes, no.aggregation)
+	}
+	display.WriteAssetTable(ko.Out, "Node", nodes, no.AssetDisplayOptions, currencyCode, no.costProjection)
+
+	return nil
+}
+
+func runCostNodeUtilization(ko *utilities.KubeOptions, no *CostOptionsNode, currencyCode string) error {
+	nodes, err := queryNodeCapacity(no.QueryBackendOptions, no.window)
+	if err != nil {
+		return err
+	}
+
+	allocations, err := queryAccumulatedAllocations(no.QueryBackendOptions, map[string]string{
+		"window":    no.window,
+		"aggregate": "cluster,node,namespace",
+	})
+	if err != nil {
+		return err
+	}
+	capacity.AddTopNamespaces(nodes, allocations, 3)
 
+	display.WriteNodeEfficiencyTable(ko.Out, nodes, currencyCode, no.costProjection, no.includeIdle)
 	return nil
 }
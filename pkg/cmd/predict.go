@@ -1,26 +1,59 @@
 package cmd
 
 import (
-	"bufio"
 	"context"
 	"errors"
 	"fmt"
-	"io"
-	"io/ioutil"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/kubecost/kubectl-cost/pkg/cmd/display"
 	"github.com/kubecost/kubectl-cost/pkg/cmd/utilities"
+	"github.com/kubecost/kubectl-cost/pkg/manifests"
+	"github.com/kubecost/kubectl-cost/pkg/pricing"
 	"github.com/kubecost/kubectl-cost/pkg/query"
 
 	"github.com/opencost/opencost/core/pkg/log"
 
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 )
 
+var predictExample = `
+    # Predict the cost of the workloads defined in a file.
+    %[1]s cost predict -f 'k8s-deployment.yaml'
+
+    # Predict the cost of a live Deployment if it were scaled to 10
+    # replicas and its "app" container requested more resources.
+    %[1]s cost predict deployment/api -n prod \
+      --replicas 10 \
+      --set-request cpu=500m,memory=1Gi \
+      --container app
+
+    # Predict the cost of all workloads defined in a directory tree
+    # and in a separate file.
+    %[1]s cost predict -R -f k8s/ -f extra/job.yaml
+
+    # Predict the cost impact of the changes to the workloads in the
+    # k8s/ directory relative to the main branch.
+    %[1]s cost predict --git-base origin/main -f k8s/
+
+    # Predict the cost of the workloads in a Kustomize overlay.
+    %[1]s cost predict --kustomize overlays/prod
+
+    # Predict the cost of the workloads in a Helm chart.
+    %[1]s cost predict --helm-chart ./charts/api --values values-prod.yaml
+`
+
 // PredictOptions contains options specific to prediction queries.
 type PredictOptions struct {
 	avgUsageWindow     string
@@ -28,11 +61,38 @@ type PredictOptions struct {
 
 	clusterID string
 
-	// The file containing the workload definition to be predicted.
-	filepath string
+	// The files, directories or glob patterns containing the workload
+	// definitions to be predicted.
+	files manifests.FileOptions
+
+	// A live workload (TYPE/NAME) to be predicted, with edits applied.
+	liveWorkload string
+	namespace    string
+	replicas     int32
+	setRequests  map[string]string
+	container    string
+
+	edits manifests.Edits
+
+	// A git revision to read the base version of filepath from. If set,
+	// predictions are relative to the base version instead of the cluster.
+	gitBase string
+
+	// Manifests to be rendered locally before prediction.
+	kustomizeDir string
+	helm         manifests.HelmOptions
+
+	// The assumed run time of each pod of a Job or CronJob.
+	jobDuration time.Duration
 
 	noUsage bool
 
+	// A pricing model exported by "pricing export". If set, predictions are
+	// computed locally from it instead of by Kubecost.
+	pricingFile  string
+	prices       *pricing.ClusterPrices
+	pricingModel *pricing.Model
+
 	query.QueryBackendOptions
 	display.PredictDisplayOptions
 }
@@ -44,11 +104,22 @@ func NewCmdPredict(
 	predictO := &PredictOptions{}
 
 	cmd := &cobra.Command{
-		Use:   "predict",
-		Short: "Estimate the monthly cost rate of a workload based on tracked cluster resource costs and historical usage.",
+		Use:     "predict [TYPE/NAME]",
+		Short:   "Estimate the monthly cost rate of a workload based on tracked cluster resource costs and historical usage.",
+		Example: fmt.Sprintf(predictExample, "kubectl"),
+		Args:    cobra.MaximumNArgs(1),
 		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				predictO.liveWorkload = args[0]
+			}
+
 			if err := kubeO.Complete(c, args); err != nil {
-				return fmt.Errorf("complete k8s options: %s", err)
+				// Predicting from a pricing model doesn't need a cluster,
+				// so it works without a kubeconfig.
+				if predictO.pricingFile == "" {
+					return fmt.Errorf("complete k8s options: %s", err)
+				}
+				log.Debugf("predicting without k8s options: %s", err)
 			}
 			if err := kubeO.Validate(); err != nil {
 				return fmt.Errorf("validate k8s options: %s", err)
@@ -64,10 +135,22 @@ func NewCmdPredict(
 			return runCostPredict(kubeO, predictO)
 		},
 	}
-	cmd.Flags().StringVarP(&predictO.filepath, "filepath", "f", "", "The file containing the workload definition whose cost should be predicted. E.g. a file might be 'test-deployment.yaml' containing an apps/v1 Deployment definition. '-' can also be passed, in which case workload definitions will be read from stdin.")
+	cmd.Flags().StringSliceVarP(&predictO.files.Filenames, "filepath", "f", nil, "The file containing the workload definitions whose cost should be predicted. E.g. a file might be 'test-deployment.yaml' containing an apps/v1 Deployment definition. Can be repeated, and directories and glob patterns are also accepted. '-' can also be passed, in which case workload definitions will be read from stdin.")
+	cmd.Flags().BoolVarP(&predictO.files.Recursive, "recursive", "R", false, "Process the directories passed to --filepath recursively.")
 	cmd.Flags().StringVarP(&predictO.clusterID, "cluster-id", "c", "", "The cluster ID (in Kubecost) of the presumed cluster which the workload will be deployed to. This is used to determine resource costs. Defaults to local cluster.")
 	cmd.Flags().StringVar(&predictO.avgUsageWindow, "window-usage", "2d", "The window of Kubecost data to calculate historical average usage from, if historical data exists. See https://github.com/kubecost/docs/blob/master/allocation.md#querying for a detailed explanation of what can be passed here.")
 	cmd.Flags().StringVar(&predictO.resourceCostWindow, "window-cost", "7d offset 48h", "The window of Kubecost data to base resource costs on. Defaults with an offset of 48h to incorporate reconciled data if reconciliation is set up. See https://github.com/kubecost/docs/blob/master/allocation.md#querying for a detailed explanation of what can be passed here.")
+	cmd.Flags().StringVarP(&predictO.namespace, "namespace", "n", "", "The namespace of the live workload to predict, and the namespace assumed for workload definitions which don't specify one. Defaults to the namespace of the current context.")
+	cmd.Flags().Int32Var(&predictO.replicas, "replicas", -1, "Predict the live workload as if it were scaled to this number of replicas. Only valid with TYPE/NAME.")
+	cmd.Flags().StringToStringVar(&predictO.setRequests, "set-request", nil, "Predict the live workload as if its containers had these cpu, memory or nvidia.com/gpu requests, e.g. 'cpu=500m,memory=1Gi'. Only valid with TYPE/NAME.")
+	cmd.Flags().StringVar(&predictO.container, "container", "", "The container which --set-request applies to. Defaults to all containers.")
+	cmd.Flags().StringVar(&predictO.gitBase, "git-base", "", "A git revision, e.g. 'origin/main'. The workload definitions in --filepath are read at this revision as well as from the working tree, and the cost difference between the two versions is shown instead of the difference from what is currently deployed.")
+	cmd.Flags().StringVarP(&predictO.kustomizeDir, "kustomize", "k", "", "A directory containing a kustomization.yaml. It is built locally and the resulting workloads are predicted.")
+	cmd.Flags().StringVar(&predictO.helm.Chart, "helm-chart", "", "A Helm chart to render locally with 'helm template' and predict the resulting workloads. Requires helm to be installed.")
+	cmd.Flags().StringArrayVar(&predictO.helm.ValuesFiles, "values", nil, "A values file for --helm-chart. Can be repeated.")
+	cmd.Flags().StringVar(&predictO.helm.ReleaseName, "helm-release-name", "", "The release name to render --helm-chart with. Set this to the name of the installed release to compare against the workloads it has deployed.")
+	cmd.Flags().DurationVar(&predictO.jobDuration, "job-duration", time.Hour, "The assumed run time of each pod of a Job or CronJob. Jobs are predicted as the fraction of a month that their pods run for.")
+	cmd.Flags().StringVar(&predictO.pricingFile, "pricing", "", "A pricing model written by 'kubectl cost pricing export'. Costs are predicted locally from the resource requests of the workloads and the prices in the model, without contacting Kubecost or the cluster.")
 	cmd.Flags().BoolVar(&predictO.noUsage, "no-usage", false, "Set true ignore historical usage data (if any exists) when performing cost prediction.")
 	cmd.Flags().BoolVar(&predictO.ShowTotal, "show-total", false, "Show the total cost of the new spec(s). See --hide-diff for a similar option..")
 	cmd.Flags().BoolVar(&predictO.HideDiff, "hide-diff", false, "Hide the cost difference of applying the new spec(s). See --show-total for a similar option..")
@@ -82,14 +165,72 @@ func NewCmdPredict(
 }
 
 func (predictO *PredictOptions) Validate() error {
-	if predictO.filepath != "-" {
-		if _, err := os.Stat(predictO.filepath); errors.Is(err, os.ErrNotExist) {
-			return fmt.Errorf("file '%s' does not exist, not a valid option", predictO.filepath)
+	var sources []string
+	if predictO.liveWorkload != "" {
+		sources = append(sources, "a workload (TYPE/NAME)")
+	}
+	if len(predictO.files.Filenames) > 0 {
+		sources = append(sources, "--filepath")
+	}
+	if predictO.kustomizeDir != "" {
+		sources = append(sources, "--kustomize")
+	}
+	if predictO.helm.Chart != "" {
+		sources = append(sources, "--helm-chart")
+	}
+	if len(sources) == 0 {
+		return fmt.Errorf("one of a workload (TYPE/NAME), --filepath, --kustomize or --helm-chart must be specified")
+	}
+	if len(sources) > 1 {
+		return fmt.Errorf("only one of %s can be specified", strings.Join(sources, ", "))
+	}
+
+	if predictO.pricingFile != "" && predictO.liveWorkload != "" {
+		return fmt.Errorf("--pricing cannot be used to predict a workload (TYPE/NAME), which is read from the cluster")
+	}
+
+	if predictO.liveWorkload == "" && !predictO.edits.IsEmpty() {
+		return fmt.Errorf("--replicas and --set-request can only be used when predicting a workload (TYPE/NAME)")
+	}
+	if predictO.container != "" && len(predictO.setRequests) == 0 {
+		return fmt.Errorf("--container can only be used with --set-request")
+	}
+	if predictO.gitBase != "" {
+		if len(predictO.files.Filenames) == 0 {
+			return fmt.Errorf("--git-base requires --filepath")
+		}
+		for _, name := range predictO.files.Filenames {
+			if name == "-" {
+				return fmt.Errorf("--git-base cannot be used when reading from stdin")
+			}
+		}
+	}
+	if predictO.files.Recursive && len(predictO.files.Filenames) == 0 {
+		return fmt.Errorf("--recursive can only be used with --filepath")
+	}
+	if predictO.helm.Chart == "" && len(predictO.helm.ValuesFiles) > 0 {
+		return fmt.Errorf("--values can only be used with --helm-chart")
+	}
+
+	if predictO.kustomizeDir != "" {
+		if _, err := os.Stat(predictO.kustomizeDir); errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("kustomization directory '%s' does not exist, not a valid option", predictO.kustomizeDir)
 		}
 	}
 
-	if err := predictO.QueryBackendOptions.Validate(); err != nil {
-		return fmt.Errorf("validating query options: %s", err)
+	if predictO.jobDuration <= 0 {
+		return fmt.Errorf("--job-duration must be positive")
+	}
+
+	if predictO.replicas < -1 {
+		return fmt.Errorf("--replicas cannot be negative")
+	}
+
+	// Kubecost isn't queried when predicting from a pricing model.
+	if predictO.pricingFile == "" {
+		if err := predictO.QueryBackendOptions.Validate(); err != nil {
+			return fmt.Errorf("validating query options: %s", err)
+		}
 	}
 
 	if err := predictO.PredictDisplayOptions.Validate(); err != nil {
@@ -100,6 +241,34 @@ func (predictO *PredictOptions) Validate() error {
 }
 
 func (predictO *PredictOptions) Complete(restConfig *rest.Config) error {
+	if predictO.replicas >= 0 {
+		replicas := predictO.replicas
+		predictO.edits.Replicas = &replicas
+	}
+
+	if len(predictO.setRequests) > 0 {
+		requests, err := manifests.ParseRequests(predictO.setRequests)
+		if err != nil {
+			return fmt.Errorf("parsing --set-request: %s", err)
+		}
+		predictO.edits.Requests = requests
+	}
+	predictO.edits.Container = predictO.container
+
+	if predictO.pricingFile != "" {
+		f, err := os.Open(predictO.pricingFile)
+		if err != nil {
+			return fmt.Errorf("opening pricing model: %s", err)
+		}
+		defer f.Close()
+
+		predictO.pricingModel, err = pricing.ReadModel(f)
+		if err != nil {
+			return fmt.Errorf("reading pricing model '%s': %s", predictO.pricingFile, err)
+		}
+		return nil
+	}
+
 	if err := predictO.QueryBackendOptions.Complete(restConfig); err != nil {
 		return fmt.Errorf("complete backend opts: %s", err)
 	}
@@ -107,28 +276,20 @@ func (predictO *PredictOptions) Complete(restConfig *rest.Config) error {
 }
 
 func runCostPredict(ko *utilities.KubeOptions, no *PredictOptions) error {
-	var b []byte
-	var err error
-
-	// Filepath of - means read from stdin.
-	if no.filepath == "-" {
-		reader := bufio.NewReader(ko.In)
-
-		scratch := make([]byte, 1024)
-		for {
-			n, err := reader.Read(scratch)
-			b = append(b, scratch[:n]...)
-			if err == io.EOF {
-				break
-			} else if err != nil {
-				return fmt.Errorf("reading from stdin: %s", err)
-			}
-		}
-	} else {
-		b, err = ioutil.ReadFile(no.filepath)
-		if err != nil {
-			return fmt.Errorf("failed to read file '%s': %s", no.filepath, err)
-		}
+	if no.namespace == "" {
+		no.namespace = ko.DefaultNamespace
+	}
+	if no.namespace == "" {
+		no.namespace = "default"
+	}
+
+	objs, err := readWorkloads(ko, no)
+	if err != nil {
+		return err
+	}
+	workloads := filterWorkloads(ko, no, objs)
+	if len(workloads) == 0 && !containsKind(objs, "PersistentVolumeClaim") {
+		return fmt.Errorf("input contains no predictable workloads")
 	}
 
 	// If the user doesn't provide a cluster ID, default to the "local" (the
@@ -136,7 +297,12 @@ func runCostPredict(ko *utilities.KubeOptions, no *PredictOptions) error {
 	// TODO: Should we at some point distinguish between cluster ID of API and
 	// cluster ID of the actual configured cluster? Env var retrieval or
 	// something?
-	if len(no.clusterID) == 0 {
+	if no.pricingModel != nil {
+		no.prices, err = no.pricingModel.Cluster(no.clusterID)
+		if err != nil {
+			return err
+		}
+	} else if len(no.clusterID) == 0 {
 		clusterID, err := query.QueryClusterID(query.ClusterInfoParameters{
 			Ctx:                 context.Background(),
 			QueryBackendOptions: no.QueryBackendOptions,
@@ -148,6 +314,310 @@ func runCostPredict(ko *utilities.KubeOptions, no *PredictOptions) error {
 		log.Debugf("Cluster ID for query set to: %s", no.clusterID)
 	}
 
+	rows, assumptions, err := predictWorkloads(ko, no, workloads, no.gitBase == "" && no.pricingModel == nil)
+	if err != nil {
+		return err
+	}
+
+	storage := &storagePredictor{ko: ko, no: no}
+	rows, err = storage.withStorage(rows, objs, no.gitBase == "" && no.pricingModel == nil)
+	if err != nil {
+		return err
+	}
+
+	// With a git base, the prediction is relative to the base version of the
+	// same workloads instead of whatever is currently deployed.
+	if no.gitBase != "" {
+		baseObjs, err := no.files.ReadAtRevision(no.gitBase)
+		if err != nil {
+			return fmt.Errorf("reading workload definitions at revision '%s':\n%s", no.gitBase, err)
+		}
+		manifests.SetDefaultNamespace(baseObjs, no.namespace)
+		baseWorkloads, _ := manifests.FilterPredictable(baseObjs)
+
+		baseRows, _, err := predictWorkloads(ko, no, baseWorkloads, false)
+		if err != nil {
+			return fmt.Errorf("predicting workloads at revision '%s': %s", no.gitBase, err)
+		}
+		baseRows, err = storage.withStorage(baseRows, baseObjs, false)
+		if err != nil {
+			return fmt.Errorf("predicting workloads at revision '%s': %s", no.gitBase, err)
+		}
+		rows = diffSpecCosts(baseRows, rows)
+	}
+
+	var currencyCode string
+	if no.pricingModel != nil {
+		currencyCode = no.pricingModel.CurrencyCode
+		assumptions = append(assumptions, fmt.Sprintf("predicted offline from resource requests and prices averaged over '%s' as of %s, without historical usage or comparing against the cluster", no.pricingModel.Window, no.pricingModel.GeneratedAt.Format(time.RFC3339)))
+	} else {
+		currencyCode, err = query.QueryCurrencyCode(query.CurrencyCodeParameters{
+			Ctx:                 context.Background(),
+			QueryBackendOptions: no.QueryBackendOptions,
+		})
+		if err != nil {
+			log.Debugf("failed to get currency code, displaying as empty string: %s", err)
+			currencyCode = ""
+		}
+	}
+
+	display.WritePredictionTable(ko.Out, rows, currencyCode, no.PredictDisplayOptions)
+	display.WritePredictionAssumptions(ko.Out, append(assumptions, storage.assumptions...))
+
+	if ranges := replicaRanges(ko, no, objs, workloads, rows); len(ranges) > 0 {
+		fmt.Fprintln(ko.Out)
+		display.WriteReplicaRangeTable(ko.Out, ranges, currencyCode)
+	}
+	return nil
+}
+
+// readWorkloads reads the objects to be predicted from whichever input source
+// the user has configured.
+func readWorkloads(ko *utilities.KubeOptions, no *PredictOptions) ([]*unstructured.Unstructured, error) {
+	switch {
+	case no.liveWorkload != "":
+		obj, err := readLiveWorkload(ko, no)
+		if err != nil {
+			return nil, err
+		}
+		return []*unstructured.Unstructured{obj}, nil
+	case no.kustomizeDir != "":
+		objs, err := manifests.RenderKustomize(no.kustomizeDir)
+		if err != nil {
+			return nil, fmt.Errorf("rendering manifests: %s", err)
+		}
+		return objs, nil
+	case no.helm.Chart != "":
+		no.helm.Namespace = no.namespace
+		objs, err := manifests.RenderHelm(context.Background(), no.helm)
+		if err != nil {
+			return nil, fmt.Errorf("rendering manifests: %s", err)
+		}
+		return objs, nil
+	default:
+		// Filepath of - means read from stdin.
+		no.files.Stdin = ko.In
+		objs, err := no.files.Read()
+		if err != nil {
+			return nil, fmt.Errorf("reading workload definitions:\n%s", err)
+		}
+		return objs, nil
+	}
+}
+
+// readLiveWorkload fetches the workload referenced on the command line from
+// the cluster, with any proposed edits applied.
+func readLiveWorkload(ko *utilities.KubeOptions, no *PredictOptions) (*unstructured.Unstructured, error) {
+	ref, err := manifests.ParseWorkloadRef(no.liveWorkload, no.namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(ko.RestConfig)
+	if err != nil {
+		return nil, fmt.Errorf("creating clientset: %s", err)
+	}
+
+	obj, err := manifests.FetchLiveWorkload(context.Background(), clientset, ref, no.edits)
+	if err != nil {
+		return nil, fmt.Errorf("reading live workload %s: %s", ref, err)
+	}
+	return obj, nil
+}
+
+func containsKind(objs []*unstructured.Unstructured, kind string) bool {
+	for _, obj := range objs {
+		if obj.GetKind() == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// filterWorkloads drops objects which can't be predicted, like Services and
+// ConfigMaps, with a note to the user. Objects without a namespace are
+// assigned the default namespace.
+func filterWorkloads(ko *utilities.KubeOptions, no *PredictOptions, objs []*unstructured.Unstructured) []*unstructured.Unstructured {
+	workloads, skipped := manifests.FilterPredictable(objs)
+	// Autoscalers and claims aren't predicted by the speccost API, but are
+	// used for replica ranges and storage costs.
+	delete(skipped, "HorizontalPodAutoscaler")
+	delete(skipped, "PersistentVolumeClaim")
+	if len(skipped) > 0 {
+		fmt.Fprintf(ko.ErrOut, "Note: skipped objects which are not predictable workloads: %s\n", manifests.SkippedSummary(skipped))
+	}
+	manifests.SetDefaultNamespace(objs, no.namespace)
+	return workloads
+}
+
+// replicaRanges finds the workloads which are scaled by a
+// HorizontalPodAutoscaler, either one in the input or one in the cluster, and
+// extrapolates their predicted cost to the autoscaler's replica range. If
+// both exist, the input's takes precedence, but the current replica count
+// can only be observed in the cluster.
+func replicaRanges(ko *utilities.KubeOptions, no *PredictOptions, objs, workloads []*unstructured.Unstructured, rows []query.SpecCostDiff) []display.ReplicaRangeCost {
+	var inputAutoscalers []manifests.Autoscaler
+	for _, obj := range objs {
+		a, ok, err := manifests.AutoscalerFromObject(obj)
+		if err != nil {
+			log.Warnf("ignoring autoscaler: %s", err)
+			continue
+		}
+		if ok {
+			inputAutoscalers = append(inputAutoscalers, a)
+		}
+	}
+
+	namespaces := map[string]bool{}
+	for _, w := range workloads {
+		if _, ok := manifests.SpecReplicas(w); ok {
+			namespaces[w.GetNamespace()] = true
+		}
+	}
+	if len(namespaces) == 0 {
+		return nil
+	}
+
+	var liveAutoscalers []manifests.Autoscaler
+	if no.pricingModel == nil {
+		clientset, err := kubernetes.NewForConfig(ko.RestConfig)
+		if err == nil {
+			var nsList []string
+			for ns := range namespaces {
+				nsList = append(nsList, ns)
+			}
+			sort.Strings(nsList)
+			liveAutoscalers, err = manifests.FetchLiveAutoscalers(context.Background(), clientset, nsList)
+		}
+		if err != nil {
+			log.Warnf("predicting without live autoscalers: %s", err)
+		}
+	}
+
+	find := func(as []manifests.Autoscaler, w *unstructured.Unstructured) *manifests.Autoscaler {
+		for i := range as {
+			if as[i].Targets(w.GetKind(), w.GetNamespace(), w.GetName()) {
+				return &as[i]
+			}
+		}
+		return nil
+	}
+
+	var ranges []display.ReplicaRangeCost
+	for _, w := range workloads {
+		replicas, ok := manifests.SpecReplicas(w)
+		if !ok {
+			continue
+		}
+
+		live := find(liveAutoscalers, w)
+		a := find(inputAutoscalers, w)
+		if a == nil {
+			a = live
+		}
+		if a == nil {
+			continue
+		}
+		current := a.CurrentReplicas
+		if live != nil {
+			current = live.CurrentReplicas
+		}
+
+		for _, r := range rows {
+			if r.Namespace != w.GetNamespace() || r.ControllerName != w.GetName() || !strings.EqualFold(r.ControllerKind, w.GetKind()) {
+				continue
+			}
+
+			perReplica := 0.0
+			if replicas > 0 {
+				perReplica = r.CostAfter.TotalMonthlyRate / float64(replicas)
+			}
+			ranges = append(ranges, display.ReplicaRangeCost{
+				Namespace:             r.Namespace,
+				ControllerKind:        r.ControllerKind,
+				ControllerName:        r.ControllerName,
+				Autoscaler:            a.Name,
+				MinReplicas:           a.MinReplicas,
+				MaxReplicas:           a.MaxReplicas,
+				CurrentReplicas:       current,
+				MonthlyRatePerReplica: perReplica,
+			})
+			break
+		}
+	}
+	return ranges
+}
+
+// predictWorkloads submits workloads to the speccost API. Workloads of kinds
+// which the API doesn't support are first converted to equivalent ones, and
+// the assumptions made doing so are returned for display. If compareLive,
+// converted workloads are compared with their live versions.
+func predictWorkloads(ko *utilities.KubeOptions, no *PredictOptions, workloads []*unstructured.Unstructured, compareLive bool) ([]query.SpecCostDiff, []string, error) {
+	if len(workloads) == 0 {
+		return nil, nil, nil
+	}
+
+	expandOpts := manifests.ExpandOptions{
+		ListNodes: func() ([]corev1.Node, error) {
+			if no.prices != nil {
+				if len(no.prices.Nodes) == 0 {
+					return nil, fmt.Errorf("the pricing model has no nodes for this cluster")
+				}
+				return no.prices.KubeNodes(), nil
+			}
+
+			clientset, err := kubernetes.NewForConfig(ko.RestConfig)
+			if err != nil {
+				return nil, fmt.Errorf("creating clientset: %s", err)
+			}
+			nodes, err := clientset.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			return nodes.Items, nil
+		},
+		JobDuration: no.jobDuration,
+	}
+	workloads, expansions, err := manifests.Expand(workloads, expandOpts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("converting workloads: %s", err)
+	}
+
+	if no.prices != nil {
+		rows, assumptions, err := no.prices.Predict(workloads)
+		if err != nil {
+			return nil, nil, fmt.Errorf("predicting workloads: %s", err)
+		}
+		return rows, append(applyExpansions(rows, expansions), assumptions...), nil
+	}
+
+	rows, err := querySpecCost(no, workloads)
+	if err != nil {
+		return nil, nil, err
+	}
+	assumptions := applyExpansions(rows, expansions)
+
+	// The speccost API compares the equivalent Deployments with live
+	// Deployments of the same name, of which there are none, so they are
+	// compared with their live originals, converted the same way.
+	if compareLive && len(expansions) > 0 {
+		live, err := predictLiveExpanded(ko, no, expansions, expandOpts)
+		if err != nil {
+			return nil, nil, err
+		}
+		compareWithLive(rows, live)
+	}
+
+	return rows, assumptions, nil
+}
+
+func querySpecCost(no *PredictOptions, workloads []*unstructured.Unstructured) ([]query.SpecCostDiff, error) {
+	b, err := manifests.Encode(workloads)
+	if err != nil {
+		return nil, fmt.Errorf("encoding workloads: %s", err)
+	}
+	log.Debugf("Predicting workloads:\n%s", string(b))
+
 	rows, err := query.QuerySpecCost(query.SpecCostParameters{
 		Ctx:                 context.Background(),
 		QueryBackendOptions: no.QueryBackendOptions,
@@ -157,21 +627,137 @@ func runCostPredict(ko *utilities.KubeOptions, no *PredictOptions) error {
 			"windowAvgUsage":     no.avgUsageWindow,
 			"windowResourceCost": no.resourceCostWindow,
 			"clusterID":          no.clusterID,
-			"defaultNamespace":   ko.DefaultNamespace,
+			"defaultNamespace":   no.namespace,
 		},
 	})
 	if err != nil {
-		return fmt.Errorf("Failed querying the speccost API. This API requires a version of Kubecost >= 1.101, which may be why this query failed. If running Kubecost v1.100, you can downgrade kubectl cost to v0.4 for old-style prediction. Error: %s", err)
+		return nil, fmt.Errorf("Failed querying the speccost API. This API requires a version of Kubecost >= 1.101, which may be why this query failed. If running Kubecost v1.100, you can downgrade kubectl cost to v0.4 for old-style prediction. Error: %s", err)
 	}
-	currencyCode, err := query.QueryCurrencyCode(query.CurrencyCodeParameters{
-		Ctx:                 context.Background(),
-		QueryBackendOptions: no.QueryBackendOptions,
-	})
+	return rows, nil
+}
+
+// predictLiveExpanded predicts the live versions of the workloads which were
+// converted to equivalent Deployments, converted with the same options.
+func predictLiveExpanded(ko *utilities.KubeOptions, no *PredictOptions, expansions []manifests.Expansion, opts manifests.ExpandOptions) ([]query.SpecCostDiff, error) {
+	client, err := dynamic.NewForConfig(ko.RestConfig)
 	if err != nil {
-		log.Debugf("failed to get currency code, displaying as empty string: %s", err)
-		currencyCode = ""
+		return nil, fmt.Errorf("creating dynamic client: %s", err)
 	}
 
-	display.WritePredictionTable(ko.Out, rows, currencyCode, no.PredictDisplayOptions)
-	return nil
+	var live []*unstructured.Unstructured
+	for _, exp := range expansions {
+		obj, err := manifests.FetchLiveExpanded(context.Background(), client, exp)
+		if err != nil {
+			return nil, fmt.Errorf("reading live workload: %s", err)
+		}
+		if obj != nil {
+			live = append(live, obj)
+		}
+	}
+	if len(live) == 0 {
+		return nil, nil
+	}
+
+	liveWorkloads, liveExpansions, err := manifests.Expand(live, opts)
+	if err != nil {
+		return nil, fmt.Errorf("converting live workloads: %s", err)
+	}
+	rows, err := querySpecCost(no, liveWorkloads)
+	if err != nil {
+		return nil, err
+	}
+	applyExpansions(rows, liveExpansions)
+	return rows, nil
+}
+
+// applyExpansions turns the predictions for Deployments which were converted
+// from other kinds, found by their equivalent names, back into predictions
+// for the original workloads. Their cost is scaled by the expansion's factor
+// and compared with nothing, as there is no live equivalent Deployment. It
+// returns the assumptions made for each, prefixed with the workload.
+func applyExpansions(rows []query.SpecCostDiff, expansions []manifests.Expansion) []string {
+	var assumptions []string
+	for _, exp := range expansions {
+		for i := range rows {
+			r := &rows[i]
+			if r.Namespace != exp.Namespace || r.ControllerName != exp.EquivalentName || !strings.EqualFold(r.ControllerKind, "deployment") {
+				continue
+			}
+
+			r.ControllerKind = strings.ToLower(exp.Kind)
+			r.ControllerName = exp.Name
+			r.CostAfter = r.CostAfter.Scale(exp.Factor)
+			r.CostBefore = query.CostPrediction{}
+			r.CostChange = r.CostAfter
+			break
+		}
+
+		for _, a := range exp.Assumptions {
+			assumptions = append(assumptions, fmt.Sprintf("%s %s %s: %s", exp.Namespace, strings.ToLower(exp.Kind), exp.Name, a))
+		}
+	}
+	return assumptions
+}
+
+// compareWithLive sets the cost before of each row to the predicted cost of
+// the live version of the same workload, if there is one.
+func compareWithLive(rows, live []query.SpecCostDiff) {
+	for i := range rows {
+		r := &rows[i]
+		for _, l := range live {
+			if l.Namespace == r.Namespace && l.ControllerName == r.ControllerName && strings.EqualFold(l.ControllerKind, r.ControllerKind) {
+				r.CostBefore = l.CostAfter
+				r.CostChange = r.CostAfter.Sub(r.CostBefore)
+				break
+			}
+		}
+	}
+}
+
+// diffSpecCosts pairs the predictions for two versions of the same set of
+// workloads by namespace, kind and name. The result describes the cost change
+// from the base version to the head version. Workloads which only exist in one
+// version are compared against nothing.
+func diffSpecCosts(base, head []query.SpecCostDiff) []query.SpecCostDiff {
+	key := func(d query.SpecCostDiff) string {
+		return fmt.Sprintf("%s/%s/%s", d.Namespace, strings.ToLower(d.ControllerKind), d.ControllerName)
+	}
+
+	baseByKey := map[string]query.SpecCostDiff{}
+	for _, b := range base {
+		baseByKey[key(b)] = b
+	}
+
+	var diffs []query.SpecCostDiff
+	for _, h := range head {
+		d := query.SpecCostDiff{
+			Namespace:      h.Namespace,
+			ControllerKind: h.ControllerKind,
+			ControllerName: h.ControllerName,
+			CostAfter:      h.CostAfter,
+		}
+		if b, ok := baseByKey[key(h)]; ok {
+			d.CostBefore = b.CostAfter
+			delete(baseByKey, key(h))
+		}
+		d.CostChange = d.CostAfter.Sub(d.CostBefore)
+		diffs = append(diffs, d)
+	}
+
+	// Anything left in base was removed in head.
+	for _, b := range base {
+		if _, ok := baseByKey[key(b)]; !ok {
+			continue
+		}
+		d := query.SpecCostDiff{
+			Namespace:      b.Namespace,
+			ControllerKind: b.ControllerKind,
+			ControllerName: b.ControllerName,
+			CostBefore:     b.CostAfter,
+		}
+		d.CostChange = d.CostAfter.Sub(d.CostBefore)
+		diffs = append(diffs, d)
+	}
+
+	return diffs
 }
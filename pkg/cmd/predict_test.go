@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"math"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/kubecost/kubectl-cost/pkg/cmd/utilities"
+	"github.com/kubecost/kubectl-cost/pkg/manifests"
+	"github.com/kubecost/kubectl-cost/pkg/query"
+)
+
+func TestApplyExpansionsComparedWithLive(t *testing.T) {
+	cost := func(total float64) query.CostPrediction {
+		return query.CostPrediction{TotalMonthlyRate: total}
+	}
+
+	// A Deployment and a DaemonSet share a name, and the DaemonSet also
+	// exists in the cluster. The Deployment's cost before is from the API.
+	rows := []query.SpecCostDiff{
+		{Namespace: "default", ControllerKind: "deployment", ControllerName: "agent", CostBefore: cost(5), CostAfter: cost(8), CostChange: cost(3)},
+		{Namespace: "default", ControllerKind: "deployment", ControllerName: manifests.EquivalentName("DaemonSet", "agent"), CostAfter: cost(30)},
+		{Namespace: "default", ControllerKind: "deployment", ControllerName: manifests.EquivalentName("CronJob", "agent"), CostAfter: cost(100)},
+	}
+	expansions := []manifests.Expansion{
+		{Kind: "DaemonSet", Namespace: "default", Name: "agent", EquivalentName: manifests.EquivalentName("DaemonSet", "agent"), Factor: 1, Assumptions: []string{"1 pod on each of 3 nodes"}},
+		{Kind: "CronJob", Namespace: "default", Name: "agent", EquivalentName: manifests.EquivalentName("CronJob", "agent"), Factor: 0.1},
+	}
+	assumptions := applyExpansions(rows, expansions)
+
+	// The live versions, converted the same way, with the CronJob scaled by
+	// its own factor.
+	live := []query.SpecCostDiff{
+		{Namespace: "default", ControllerKind: "deployment", ControllerName: manifests.EquivalentName("DaemonSet", "agent"), CostAfter: cost(20)},
+		{Namespace: "default", ControllerKind: "deployment", ControllerName: manifests.EquivalentName("CronJob", "agent"), CostAfter: cost(50)},
+	}
+	applyExpansions(live, expansions)
+	compareWithLive(rows, live)
+
+	expected := []struct {
+		kind                  string
+		before, after, change float64
+	}{
+		{"deployment", 5, 8, 3},
+		{"daemonset", 20, 30, 10},
+		{"cronjob", 5, 10, 5},
+	}
+	for i, e := range expected {
+		r := rows[i]
+		if r.ControllerKind != e.kind || r.ControllerName != "agent" {
+			t.Errorf("row %d: expected %s agent, got %s %s", i, e.kind, r.ControllerKind, r.ControllerName)
+		}
+		for _, c := range []struct {
+			name          string
+			got, expected float64
+		}{
+			{"before", r.CostBefore.TotalMonthlyRate, e.before},
+			{"after", r.CostAfter.TotalMonthlyRate, e.after},
+			{"change", r.CostChange.TotalMonthlyRate, e.change},
+		} {
+			if math.Abs(c.got-c.expected) > 1e-9 {
+				t.Errorf("row %d: expected cost %s %f, got %f", i, c.name, c.expected, c.got)
+			}
+		}
+	}
+
+	if len(assumptions) != 1 || assumptions[0] != "default daemonset agent: 1 pod on each of 3 nodes" {
+		t.Errorf("unexpected assumptions %v", assumptions)
+	}
+}
+
+func TestDiffSpecCosts(t *testing.T) {
+	type expectedRow struct {
+		name                  string
+		before, after, change float64
+	}
+	row := func(ns, kind, name string, total float64) query.SpecCostDiff {
+		return query.SpecCostDiff{Namespace: ns, ControllerKind: kind, ControllerName: name, CostAfter: query.CostPrediction{TotalMonthlyRate: total}}
+	}
+
+	cases := []struct {
+		name       string
+		base, head []query.SpecCostDiff
+		expected   []expectedRow
+	}{
+		{
+			name:     "paired",
+			base:     []query.SpecCostDiff{row("default", "deployment", "api", 10)},
+			head:     []query.SpecCostDiff{row("default", "Deployment", "api", 15)},
+			expected: []expectedRow{{"api", 10, 15, 5}},
+		},
+		{
+			name: "added in head",
+			base: []query.SpecCostDiff{row("default", "deployment", "api", 10)},
+			head: []query.SpecCostDiff{
+				row("default", "deployment", "api", 10),
+				row("default", "deployment", "worker", 4),
+			},
+			expected: []expectedRow{{"api", 10, 10, 0}, {"worker", 0, 4, 4}},
+		},
+		{
+			name: "removed from base",
+			base: []query.SpecCostDiff{
+				row("default", "deployment", "api", 10),
+				row("default", "statefulset", "db", 20),
+			},
+			head:     []query.SpecCostDiff{row("default", "deployment", "api", 8)},
+			expected: []expectedRow{{"api", 10, 8, -2}, {"db", 20, 0, -20}},
+		},
+		{
+			name:     "same name in another namespace",
+			base:     []query.SpecCostDiff{row("staging", "deployment", "api", 3)},
+			head:     []query.SpecCostDiff{row("default", "deployment", "api", 10)},
+			expected: []expectedRow{{"api", 0, 10, 10}, {"api", 3, 0, -3}},
+		},
+	}
+
+	for _, c := range cases {
+		got := diffSpecCosts(c.base, c.head)
+		if len(got) != len(c.expected) {
+			t.Errorf("%s: expected %d rows, got %+v", c.name, len(c.expected), got)
+			continue
+		}
+		for i, e := range c.expected {
+			r := got[i]
+			if r.ControllerName != e.name || r.CostBefore.TotalMonthlyRate != e.before || r.CostAfter.TotalMonthlyRate != e.after || r.CostChange.TotalMonthlyRate != e.change {
+				t.Errorf("%s: row %d: expected %s %.0f -> %.0f (%.0f), got %s %.0f -> %.0f (%.0f)", c.name, i,
+					e.name, e.before, e.after, e.change,
+					r.ControllerName, r.CostBefore.TotalMonthlyRate, r.CostAfter.TotalMonthlyRate, r.CostChange.TotalMonthlyRate)
+			}
+		}
+	}
+}
+
+func TestFilterWorkloads(t *testing.T) {
+	obj := func(kind, name, namespace string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetKind(kind)
+		u.SetName(name)
+		u.SetNamespace(namespace)
+		return u
+	}
+	objs := []*unstructured.Unstructured{
+		obj("Deployment", "api", ""),
+		obj("CronJob", "report", "batch"),
+		obj("Service", "api", ""),
+		obj("ConfigMap", "a", ""),
+		obj("ConfigMap", "b", ""),
+		obj("HorizontalPodAutoscaler", "api", ""),
+		obj("PersistentVolumeClaim", "data", ""),
+	}
+
+	streams, _, _, errOut := genericclioptions.NewTestIOStreams()
+	ko := utilities.NewKubeOptions(streams)
+	workloads := filterWorkloads(ko, &PredictOptions{namespace: "prod"}, objs)
+
+	if len(workloads) != 2 || workloads[0].GetName() != "api" || workloads[1].GetName() != "report" {
+		t.Errorf("expected the api Deployment and report CronJob, got %v", workloads)
+	}
+	if workloads[0].GetNamespace() != "prod" || workloads[1].GetNamespace() != "batch" {
+		t.Errorf("expected only missing namespaces to be defaulted, got %s and %s", workloads[0].GetNamespace(), workloads[1].GetNamespace())
+	}
+
+	// Autoscalers and claims are used, so they aren't reported as skipped.
+	expected := "Note: skipped objects which are not predictable workloads: ConfigMap (2), Service (1)\n"
+	if errOut.String() != expected {
+		t.Errorf("expected note %q, got %q", expected, errOut.String())
+	}
+}
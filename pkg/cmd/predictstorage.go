@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kubecost/kubectl-cost/pkg/cmd/utilities"
+	"github.com/kubecost/kubectl-cost/pkg/manifests"
+	"github.com/kubecost/kubectl-cost/pkg/pricing"
+	"github.com/kubecost/kubectl-cost/pkg/query"
+
+	"github.com/opencost/opencost/core/pkg/util/timeutil"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+)
+
+// storagePredictor predicts the cost of persistent storage, which the
+// speccost API doesn't cover. Prices are derived from the disk assets in
+// Kubecost and fetched on first use.
+type storagePredictor struct {
+	ko *utilities.KubeOptions
+	no *PredictOptions
+
+	clientset    kubernetes.Interface
+	rates        *pricing.StorageRates
+	defaultClass string
+
+	// assumptions are notes about prices which had to be estimated.
+	assumptions []string
+}
+
+func (s *storagePredictor) load() error {
+	if s.rates != nil {
+		return nil
+	}
+
+	if s.no.prices != nil {
+		s.rates = &s.no.prices.Storage
+		s.defaultClass = s.no.prices.DefaultStorageClass
+		return nil
+	}
+
+	clientset, err := kubernetes.NewForConfig(s.ko.RestConfig)
+	if err != nil {
+		return fmt.Errorf("creating clientset: %s", err)
+	}
+	s.clientset = clientset
+
+	s.defaultClass, err = manifests.DefaultStorageClass(context.Background(), clientset)
+	if err != nil {
+		return err
+	}
+
+	assetSets, err := query.QueryDiskAssets(query.AssetParameters{
+		Ctx:                 context.Background(),
+		Window:              s.no.resourceCostWindow,
+		Accumulate:          "true",
+		QueryBackendOptions: s.no.QueryBackendOptions,
+	})
+	if err != nil {
+		return fmt.Errorf("querying disk assets: %s", err)
+	}
+
+	var disks []query.AssetDisk
+	for _, set := range assetSets {
+		for _, d := range set {
+			disks = append(disks, d)
+		}
+	}
+	rates := pricing.StorageRatesFromDisks(disks)
+	s.rates = &rates
+	return nil
+}
+
+// cost returns the monthly cost of claims.
+func (s *storagePredictor) cost(claims []manifests.StorageClaim) query.CostPrediction {
+	var p query.CostPrediction
+	for _, c := range claims {
+		class := c.StorageClass
+		if class == "" {
+			class = s.defaultClass
+		}
+
+		rate, ok := s.rates.GiBHourRate(class)
+		if !ok {
+			s.assume(fmt.Sprintf("%s %s %s: no disks of StorageClass '%s' are tracked, so storage is priced at the average of all persistent volumes (%.4f per GiB-month)", c.Namespace, strings.ToLower(c.Kind), c.Name, class, rate*timeutil.HoursPerMonth))
+		}
+
+		gibHours := float64(c.Bytes) / pricing.BytesPerGiB * timeutil.HoursPerMonth
+		p = p.Add(query.CostPrediction{
+			TotalMonthlyRate:   gibHours * rate,
+			PVMonthlyRate:      gibHours * rate,
+			MonthlyPVByteHours: float64(c.Bytes) * timeutil.HoursPerMonth,
+		})
+	}
+	return p
+}
+
+func (s *storagePredictor) assume(note string) {
+	for _, a := range s.assumptions {
+		if a == note {
+			return
+		}
+	}
+	s.assumptions = append(s.assumptions, note)
+}
+
+// withStorage adds the predicted cost of the storage claimed by objs to the
+// rows of the claiming workloads. Standalone PersistentVolumeClaims get rows
+// of their own. If compareLive is true, the storage which the same objects
+// currently claim in the cluster is the cost before.
+func (s *storagePredictor) withStorage(rows []query.SpecCostDiff, objs []*unstructured.Unstructured, compareLive bool) ([]query.SpecCostDiff, error) {
+	claims, err := manifests.StorageClaims(objs)
+	if err != nil {
+		return nil, err
+	}
+	if len(claims) == 0 {
+		return rows, nil
+	}
+	if err := s.load(); err != nil {
+		return nil, fmt.Errorf("predicting storage costs: %s", err)
+	}
+
+	type object struct{ kind, namespace, name string }
+	var order []object
+	byObject := map[object][]manifests.StorageClaim{}
+	for _, c := range claims {
+		o := object{c.Kind, c.Namespace, c.Name}
+		if _, ok := byObject[o]; !ok {
+			order = append(order, o)
+		}
+		byObject[o] = append(byObject[o], c)
+	}
+
+	for _, o := range order {
+		after := s.cost(byObject[o])
+
+		var before query.CostPrediction
+		if compareLive {
+			live, err := manifests.FetchLiveStorageClaims(context.Background(), s.clientset, o.kind, o.namespace, o.name)
+			if err != nil {
+				return nil, fmt.Errorf("reading current storage of %s %s/%s: %s", o.kind, o.namespace, o.name, err)
+			}
+			before = s.cost(live)
+		}
+
+		i := 0
+		for ; i < len(rows); i++ {
+			if rows[i].Namespace == o.namespace && rows[i].ControllerName == o.name && strings.EqualFold(rows[i].ControllerKind, o.kind) {
+				break
+			}
+		}
+		if i == len(rows) {
+			rows = append(rows, query.SpecCostDiff{
+				Namespace:      o.namespace,
+				ControllerKind: strings.ToLower(o.kind),
+				ControllerName: o.name,
+			})
+		}
+
+		rows[i].CostAfter = rows[i].CostAfter.Add(after)
+		rows[i].CostBefore = rows[i].CostBefore.Add(before)
+		rows[i].CostChange = rows[i].CostAfter.Sub(rows[i].CostBefore)
+	}
+
+	return rows, nil
+}
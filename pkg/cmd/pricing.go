@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubecost/kubectl-cost/pkg/cmd/utilities"
+	"github.com/kubecost/kubectl-cost/pkg/manifests"
+	"github.com/kubecost/kubectl-cost/pkg/pricing"
+	"github.com/kubecost/kubectl-cost/pkg/query"
+
+	"github.com/opencost/opencost/core/pkg/log"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+var pricingExportExample = `
+    # Save the current prices, to predict costs without access to the
+    # cluster later.
+    %[1]s cost pricing export > prices.json
+    %[1]s cost predict --pricing prices.json -f k8s/
+`
+
+// PricingExportOptions contains options specific to exporting a pricing model.
+type PricingExportOptions struct {
+	window string
+
+	query.QueryBackendOptions
+}
+
+func newCmdPricing(
+	streams genericclioptions.IOStreams,
+) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pricing",
+		Short: "Manage snapshots of resource prices for predicting costs offline.",
+		RunE: func(c *cobra.Command, args []string) error {
+			return fmt.Errorf("please use a subcommand")
+		},
+	}
+
+	cmd.AddCommand(newCmdPricingExport(streams))
+
+	return cmd
+}
+
+func newCmdPricingExport(
+	streams genericclioptions.IOStreams,
+) *cobra.Command {
+	kubeO := utilities.NewKubeOptions(streams)
+	exportO := &PricingExportOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "export",
+		Short:   "Write the CPU, RAM, GPU and storage prices of each cluster and node type as JSON, for use with 'predict --pricing'.",
+		Example: fmt.Sprintf(pricingExportExample, "kubectl"),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := kubeO.Complete(c, args); err != nil {
+				return fmt.Errorf("complete k8s options: %s", err)
+			}
+			if err := kubeO.Validate(); err != nil {
+				return fmt.Errorf("validate k8s options: %s", err)
+			}
+
+			if err := exportO.Complete(kubeO.RestConfig); err != nil {
+				return fmt.Errorf("complete: %s", err)
+			}
+			if err := exportO.Validate(); err != nil {
+				return fmt.Errorf("validate: %s", err)
+			}
+
+			return runPricingExport(kubeO, exportO)
+		},
+	}
+	cmd.Flags().StringVar(&exportO.window, "window", "7d offset 48h", "The window of Kubecost data to average prices over. Defaults with an offset of 48h to incorporate reconciled data if reconciliation is set up. See https://github.com/kubecost/docs/blob/master/allocation.md#querying for a detailed explanation of what can be passed here.")
+
+	query.AddQueryBackendOptionsFlags(cmd, &exportO.QueryBackendOptions)
+	utilities.AddKubeOptionsFlags(cmd, kubeO)
+
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func (exportO *PricingExportOptions) Validate() error {
+	if err := exportO.QueryBackendOptions.Validate(); err != nil {
+		return fmt.Errorf("validating query options: %s", err)
+	}
+
+	return nil
+}
+
+func (exportO *PricingExportOptions) Complete(restConfig *rest.Config) error {
+	if err := exportO.QueryBackendOptions.Complete(restConfig); err != nil {
+		return fmt.Errorf("complete backend opts: %s", err)
+	}
+	return nil
+}
+
+func runPricingExport(ko *utilities.KubeOptions, eo *PricingExportOptions) error {
+	ctx := context.Background()
+
+	nodeSets, err := query.QueryAssets(query.AssetParameters{
+		Ctx:                 ctx,
+		Window:              eo.window,
+		Accumulate:          "true",
+		FilterTypes:         "Node",
+		QueryBackendOptions: eo.QueryBackendOptions,
+	})
+	if err != nil {
+		return fmt.Errorf("querying node assets: %s", err)
+	}
+	var nodes []query.AssetNode
+	for _, set := range nodeSets {
+		for _, n := range set {
+			nodes = append(nodes, n)
+		}
+	}
+
+	diskSets, err := query.QueryDiskAssets(query.AssetParameters{
+		Ctx:                 ctx,
+		Window:              eo.window,
+		Accumulate:          "true",
+		QueryBackendOptions: eo.QueryBackendOptions,
+	})
+	if err != nil {
+		return fmt.Errorf("querying disk assets: %s", err)
+	}
+	var disks []query.AssetDisk
+	for _, set := range diskSets {
+		for _, d := range set {
+			disks = append(disks, d)
+		}
+	}
+
+	model := pricing.NewModel(eo.window, nodes, disks)
+	if len(model.Clusters) == 0 {
+		return fmt.Errorf("no node assets in window '%s'", eo.window)
+	}
+
+	model.CurrencyCode, err = query.QueryCurrencyCode(query.CurrencyCodeParameters{
+		Ctx:                 ctx,
+		QueryBackendOptions: eo.QueryBackendOptions,
+	})
+	if err != nil {
+		log.Debugf("failed to get currency code, exporting as empty string: %s", err)
+	}
+
+	model.LocalCluster, err = query.QueryClusterID(query.ClusterInfoParameters{
+		Ctx:                 ctx,
+		QueryBackendOptions: eo.QueryBackendOptions,
+	})
+	if err != nil {
+		return fmt.Errorf("acquiring cluster ID from service: %s", err)
+	}
+
+	// The local cluster's nodes and default storage class can be read from
+	// the Kubernetes API, which predict would otherwise do itself.
+	if local, ok := model.Clusters[model.LocalCluster]; ok {
+		clientset, err := kubernetes.NewForConfig(ko.RestConfig)
+		if err != nil {
+			return fmt.Errorf("creating clientset: %s", err)
+		}
+		kubeNodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("listing nodes: %s", err)
+		}
+		local.SetNodes(kubeNodes.Items)
+
+		local.DefaultStorageClass, err = manifests.DefaultStorageClass(ctx, clientset)
+		if err != nil {
+			return err
+		}
+	}
+
+	return model.Write(ko.Out)
+}
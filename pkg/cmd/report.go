@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubecost/kubectl-cost/pkg/chargeback"
+	"github.com/kubecost/kubectl-cost/pkg/cmd/display"
+	"github.com/kubecost/kubectl-cost/pkg/cmd/utilities"
+	"github.com/kubecost/kubectl-cost/pkg/period"
+	"github.com/kubecost/kubectl-cost/pkg/query"
+	"github.com/opencost/opencost/core/pkg/log"
+)
+
+var reportChargebackExample = `
+    # Show last month's chargeback report per team as markdown.
+    %[1]s cost report chargeback --by label:team
+
+    # Write the September 2026 report as HTML, sharing the cost of kube-system.
+    %[1]s cost report chargeback --by label:team --period 2026-09 -o html --share-namespaces kube-system > chargeback.html
+
+    # Export line items per namespace of the last quarter for a spreadsheet.
+    %[1]s cost report chargeback --by label:team --period last-quarter -o csv
+`
+
+// ChargebackOptions holds the options of the chargeback report.
+type ChargebackOptions struct {
+	by     string
+	output string
+
+	CostOptions
+}
+
+func (o *ChargebackOptions) Validate() error {
+	if o.calendarPeriod == nil {
+		return fmt.Errorf("--period is required, e.g. last-month")
+	}
+	if o.by == "" {
+		return fmt.Errorf("--by is required, e.g. label:team")
+	}
+	if strings.Contains(o.by, ",") {
+		return fmt.Errorf("--by must be a single aggregation, got '%s'", o.by)
+	}
+	if o.by == "cluster" || o.by == "namespace" {
+		return fmt.Errorf("--by cannot be '%s', line items are already per cluster and namespace", o.by)
+	}
+	if !slices.Contains(display.ChargebackReportFormats, o.output) {
+		return fmt.Errorf("--output must be one of: %s", strings.Join(display.ChargebackReportFormats, ", "))
+	}
+	return o.CostOptions.Validate()
+}
+
+func newCmdReport(streams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "generate reports, such as a monthly chargeback report",
+		RunE: func(c *cobra.Command, args []string) error {
+			return fmt.Errorf("please use a subcommand")
+		},
+	}
+
+	cmd.AddCommand(newCmdReportChargeback(streams))
+
+	return cmd
+}
+
+func newCmdReportChargeback(streams genericclioptions.IOStreams) *cobra.Command {
+	kubeO := utilities.NewKubeOptions(streams)
+	reportO := &ChargebackOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "chargeback",
+		Short:   "invoice-style report of what each team, or other group of namespaces, cost in a calendar month",
+		Example: fmt.Sprintf(reportChargebackExample, "kubectl"),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := kubeO.Complete(c, args); err != nil {
+				return err
+			}
+			if err := kubeO.Validate(); err != nil {
+				return err
+			}
+
+			if err := reportO.CostOptions.Complete(kubeO.RestConfig); err != nil {
+				return fmt.Errorf("completing options: %s", err)
+			}
+			if err := reportO.Validate(); err != nil {
+				return err
+			}
+
+			return runReportChargeback(kubeO, reportO)
+		},
+	}
+
+	cmd.Flags().StringVar(&reportO.by, "by", "", "What to charge back to, e.g. label:team, annotation:owner or department.")
+	cmd.Flags().StringVar(&reportO.period, "period", "last-month", fmt.Sprintf("The calendar period to report on: %s. A period in progress is reported to date.", period.Formats))
+	cmd.Flags().StringVarP(&reportO.output, "output", "o", "markdown", fmt.Sprintf("The output format, one of: %s.", strings.Join(display.ChargebackReportFormats, ", ")))
+	addShareOptionsFlags(cmd, &reportO.CostOptions)
+	query.AddQueryBackendOptionsFlags(cmd, &reportO.QueryBackendOptions)
+	utilities.AddKubeOptionsFlags(cmd, kubeO)
+
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func runReportChargeback(ko *utilities.KubeOptions, o *ChargebackOptions) error {
+	currencyCode, err := query.QueryCurrencyCode(query.CurrencyCodeParameters{
+		Ctx:                 context.Background(),
+		QueryBackendOptions: o.QueryBackendOptions,
+	})
+	if err != nil {
+		log.Debugf("failed to get currency code, displaying as empty string: %s", err)
+		currencyCode = ""
+	}
+
+	// Idle is queried by cluster and unshared, so that the report can share
+	// it in proportion to compute cost and show it separately.
+	o.includeIdle = true
+	params := map[string]string{
+		"window":    o.window,
+		"aggregate": fmt.Sprintf("cluster,%s,namespace", o.by),
+	}
+	o.addIdleQueryParams(params)
+	o.addShareQueryParams(params)
+
+	allocations, err := queryAccumulatedAllocations(o.QueryBackendOptions, params)
+	if err != nil {
+		return err
+	}
+
+	report, err := chargeback.Build(*o.calendarPeriod, o.by, allocations)
+	if err != nil {
+		return err
+	}
+
+	return display.WriteChargebackReport(ko.Out, o.output, report, currencyCode)
+}
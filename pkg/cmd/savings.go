@@ -3,10 +3,13 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/kubecost/kubectl-cost/pkg/cmd/display"
 	"github.com/kubecost/kubectl-cost/pkg/cmd/utilities"
 	"github.com/kubecost/kubectl-cost/pkg/query"
+	"github.com/kubecost/kubectl-cost/pkg/rightsizing"
 
 	"github.com/opencost/opencost/core/pkg/log"
 
@@ -16,11 +19,172 @@ import (
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 )
 
+var savingsExample = `
+    # Show request sizing recommendations based on the last week.
+    %[1]s cost savings --window 7d
+
+    # Show the 10 largest savings in the prod namespace, sizing requests for
+    # the 95th percentile of usage at 60%% utilization.
+    %[1]s cost savings -n prod --algorithm quantile --quantile 0.95 --target-cpu-util 0.6 --target-ram-util 0.6 --top 10
+
+    # Write the recommendations and their total savings as JSON.
+    %[1]s cost savings -o json
+
+    # Write a patch per controller which sets the recommended requests,
+    # with 20%% more CPU than recommended and CPU limits of twice the
+    # new requests.
+    %[1]s cost savings --emit-patches patches/ --cpu-margin 20 --cpu-limit-ratio 2
+
+    # Write the recommendations as a Kustomize Component, to be added to
+    # the components of an overlay.
+    %[1]s cost savings -o kustomize --emit-patches overlays/prod/rightsizing
+
+    # Apply the recommendations to the controllers in the cluster, and undo
+    # that again.
+    %[1]s cost savings apply -n prod --min-savings 5
+    %[1]s cost savings revert -n prod
+`
+
 // SavingsOptions contains options specific to savings queries.
 type SavingsOptions struct {
-	window string
+	recommendationOptions
+
+	// Only recommendations for this namespace are shown, if set.
+	namespace string
+
+	// The minimum monthly savings of a recommendation for it to be shown.
+	minSavings float64
+
+	// The format to write recommendations in.
+	output string
+
+	// A directory to write the recommendations to as patches.
+	emitPatches string
+
+	rightsizingOptions
 
 	query.QueryBackendOptions
+	display.SavingsDisplayOptions
+}
+
+// recommendationOptions are the parameters of the request sizing API, which
+// tune how recommendations are computed.
+type recommendationOptions struct {
+	window string
+
+	// Target utilizations as fractions, e.g. 0.8.
+	targetCPUUtil float64
+	targetRAMUtil float64
+
+	// One of max or quantile, and the quantile to use for the latter.
+	algorithm string
+	quantile  float64
+
+	// A filter in the Kubecost filter language, e.g. namespace:"prod".
+	filter string
+}
+
+func addRecommendationFlags(cmd *cobra.Command, o *recommendationOptions) {
+	cmd.Flags().StringVarP(&o.window, "window", "w", "2d", "The window of data to use for the savings recommendation. See https://github.com/kubecost/docs/blob/master/allocation.md#querying for a detailed explanation of what can be passed here.")
+	cmd.Flags().Float64Var(&o.targetCPUUtil, "target-cpu-util", 0, "The CPU utilization to size requests for, as a fraction between 0 and 1, e.g. 0.8. Lower values leave more headroom. Defaults to the Kubecost default.")
+	cmd.Flags().Float64Var(&o.targetRAMUtil, "target-ram-util", 0, "The RAM utilization to size requests for, as a fraction between 0 and 1. Defaults to the Kubecost default.")
+	cmd.Flags().StringVar(&o.algorithm, "algorithm", "", "How usage is aggregated over the window, one of: max, quantile. Defaults to the Kubecost default.")
+	cmd.Flags().Float64Var(&o.quantile, "quantile", 0, "The usage quantile to size requests for with --algorithm quantile, as a fraction between 0 and 1, e.g. 0.95.")
+	cmd.Flags().StringVar(&o.filter, "filter", "", `A filter in the Kubecost filter language to select the containers to recommend requests for, e.g. 'label[team]:"payments"'.`)
+}
+
+func (o recommendationOptions) validate() error {
+	for flag, util := range map[string]float64{"--target-cpu-util": o.targetCPUUtil, "--target-ram-util": o.targetRAMUtil} {
+		if util < 0 || util > 1 {
+			return fmt.Errorf("%s must be a fraction between 0 and 1, got %g", flag, util)
+		}
+	}
+	switch o.algorithm {
+	case "", "max":
+		if o.quantile != 0 {
+			return fmt.Errorf("--quantile requires --algorithm quantile")
+		}
+	case "quantile":
+		if o.quantile <= 0 || o.quantile > 1 {
+			return fmt.Errorf("--algorithm quantile requires --quantile to be set to a fraction between 0 and 1, e.g. 0.95")
+		}
+	default:
+		return fmt.Errorf("unsupported algorithm '%s', must be one of: max, quantile", o.algorithm)
+	}
+	return nil
+}
+
+// queryParams returns the request sizing API parameters for o. Parameters
+// which aren't set are left out, so that Kubecost's defaults apply. If
+// namespace is set, the filter is narrowed to it.
+func (o recommendationOptions) queryParams(namespace string) map[string]string {
+	params := map[string]string{
+		"window": o.window,
+	}
+	if o.targetCPUUtil > 0 {
+		params["targetCPUUtilization"] = strconv.FormatFloat(o.targetCPUUtil, 'f', -1, 64)
+	}
+	if o.targetRAMUtil > 0 {
+		params["targetRAMUtilization"] = strconv.FormatFloat(o.targetRAMUtil, 'f', -1, 64)
+	}
+	if o.algorithm != "" {
+		params["algorithmCPU"] = o.algorithm
+		params["algorithmRAM"] = o.algorithm
+	}
+	if o.algorithm == "quantile" {
+		params["qCPU"] = strconv.FormatFloat(o.quantile, 'f', -1, 64)
+		params["qRAM"] = strconv.FormatFloat(o.quantile, 'f', -1, 64)
+	}
+
+	// The user's filter is parenthesized, so that e.g. an OR filter is not
+	// split by the namespace filter joined to it.
+	var filters []string
+	if o.filter != "" {
+		filters = append(filters, "("+o.filter+")")
+	}
+	if namespace != "" {
+		filters = append(filters, fmt.Sprintf(`namespace:"%s"`, namespace))
+	}
+	if len(filters) > 0 {
+		params["filter"] = strings.Join(filters, "+")
+	}
+	return params
+}
+
+// rightsizingOptions control how recommendations are turned into new
+// resources, for patches and for changes applied to the cluster.
+type rightsizingOptions struct {
+	// Margins, in percent, and limit ratios applied to recommendations.
+	cpuMargin     float64
+	ramMargin     float64
+	cpuLimitRatio float64
+	ramLimitRatio float64
+}
+
+func addRightsizingFlags(cmd *cobra.Command, o *rightsizingOptions) {
+	cmd.Flags().Float64Var(&o.cpuMargin, "cpu-margin", 0, "A safety margin in percent added to recommended CPU requests, e.g. 20 for 20% more than recommended.")
+	cmd.Flags().Float64Var(&o.ramMargin, "ram-margin", 0, "A safety margin in percent added to recommended RAM requests.")
+	cmd.Flags().Float64Var(&o.cpuLimitRatio, "cpu-limit-ratio", 0, "If set, also set CPU limits to this multiple of the new CPU requests, e.g. 2. Otherwise, limits are unchanged, so make sure they are not below the new requests; `savings apply` raises limits which are below them.")
+	cmd.Flags().Float64Var(&o.ramLimitRatio, "ram-limit-ratio", 0, "If set, also set RAM limits to this multiple of the new RAM requests.")
+}
+
+func (o rightsizingOptions) validate() error {
+	if o.cpuMargin < 0 || o.ramMargin < 0 {
+		return fmt.Errorf("--cpu-margin and --ram-margin cannot be negative")
+	}
+	if (o.cpuLimitRatio != 0 && o.cpuLimitRatio < 1) || (o.ramLimitRatio != 0 && o.ramLimitRatio < 1) {
+		return fmt.Errorf("--cpu-limit-ratio and --ram-limit-ratio must be at least 1, limits cannot be lower than requests")
+	}
+	return nil
+}
+
+func (o rightsizingOptions) options() rightsizing.Options {
+	return rightsizing.Options{
+		CPUMargin:     o.cpuMargin / 100,
+		RAMMargin:     o.ramMargin / 100,
+		CPULimitRatio: o.cpuLimitRatio,
+		RAMLimitRatio: o.ramLimitRatio,
+	}
 }
 
 func newCmdCostSavings(
@@ -30,8 +194,9 @@ func newCmdCostSavings(
 	savingsO := &SavingsOptions{}
 
 	cmd := &cobra.Command{
-		Use:   "savings",
-		Short: "Show container request sizing recommendations and estimated monthly savings from right-sizing.",
+		Use:     "savings",
+		Short:   "Show container request sizing recommendations and estimated monthly savings from right-sizing.",
+		Example: fmt.Sprintf(savingsExample, "kubectl"),
 		RunE: func(c *cobra.Command, args []string) error {
 			if err := kubeO.Complete(c, args); err != nil {
 				return fmt.Errorf("complete k8s options: %s", err)
@@ -50,17 +215,53 @@ func newCmdCostSavings(
 			return runCostSavings(kubeO, savingsO)
 		},
 	}
-	cmd.Flags().StringVarP(&savingsO.window, "window", "w", "2d", "The window of data to use for the savings recommendation. See https://github.com/kubecost/docs/blob/master/allocation.md#querying for a detailed explanation of what can be passed here.")
+	addRecommendationFlags(cmd, &savingsO.recommendationOptions)
+	cmd.Flags().StringVarP(&savingsO.namespace, "namespace", "n", "", "Only show recommendations for this namespace.")
+	cmd.Flags().Float64Var(&savingsO.minSavings, "min-savings", 0, "Only show recommendations which save at least this much per month.")
+	display.AddSavingsDisplayOptionsFlags(cmd, &savingsO.SavingsDisplayOptions)
+
+	cmd.Flags().StringVarP(&savingsO.output, "output", "o", "table", "The output format, one of: table, json, yaml, csv, markdown, kustomize. JSON and YAML include the savings totals. 'kustomize' writes the recommendations to --emit-patches as a Kustomize Component instead of showing a table.")
+	cmd.Flags().StringVar(&savingsO.emitPatches, "emit-patches", "", "A directory to write a strategic-merge patch to for each controller, which sets the recommended requests of its containers. Only the recommendations which are shown, after --min-savings, --sort-by and --top, are written.")
+	addRightsizingFlags(cmd, &savingsO.rightsizingOptions)
 
 	query.AddQueryBackendOptionsFlags(cmd, &savingsO.QueryBackendOptions)
 	utilities.AddKubeOptionsFlags(cmd, kubeO)
 
+	cmd.AddCommand(newCmdSavingsApply(streams))
+	cmd.AddCommand(newCmdSavingsRevert(streams))
+	cmd.AddCommand(newCmdSavingsAbandoned(streams))
+	cmd.AddCommand(newCmdSavingsUnclaimedVolumes(streams))
+	cmd.AddCommand(newCmdSavingsNodes(streams))
+
 	cmd.SilenceUsage = true
 
 	return cmd
 }
 
 func (savingsO *SavingsOptions) Validate() error {
+	switch savingsO.output {
+	case "table", "json", "yaml", "csv", "markdown":
+	case "kustomize":
+		if savingsO.emitPatches == "" {
+			return fmt.Errorf("-o kustomize requires --emit-patches to be set to the directory to write to")
+		}
+	default:
+		return fmt.Errorf("unsupported output format '%s', must be one of: table, json, yaml, csv, markdown, kustomize", savingsO.output)
+	}
+
+	if savingsO.minSavings < 0 {
+		return fmt.Errorf("--min-savings cannot be negative")
+	}
+	if err := savingsO.recommendationOptions.validate(); err != nil {
+		return err
+	}
+	if err := savingsO.rightsizingOptions.validate(); err != nil {
+		return err
+	}
+	if err := savingsO.SavingsDisplayOptions.Validate(); err != nil {
+		return err
+	}
+
 	if err := savingsO.QueryBackendOptions.Validate(); err != nil {
 		return fmt.Errorf("validating query options: %s", err)
 	}
@@ -85,17 +286,69 @@ func runCostSavings(ko *utilities.KubeOptions, so *SavingsOptions) error {
 		currencyCode = ""
 	}
 
-	recs, err := query.QuerySavings(query.SavingsParameters{
+	allRecs, err := query.QuerySavings(query.SavingsParameters{
 		Ctx:                 context.Background(),
 		QueryBackendOptions: so.QueryBackendOptions,
-		QueryParams: map[string]string{
-			"window": so.window,
-		},
+		QueryParams:         so.queryParams(so.namespace),
 	})
 	if err != nil {
 		return fmt.Errorf("querying savings API: %s", err)
 	}
 
-	display.WriteSavingsTable(ko.Out, recs, currencyCode)
+	recs := so.selectRecommendations(allRecs)
+
+	if so.emitPatches != "" {
+		if err := writeSavingsPatches(ko, so, recs); err != nil {
+			return err
+		}
+		if so.output == "kustomize" {
+			return nil
+		}
+	}
+
+	if so.output != "table" {
+		return display.WriteSavingsReport(ko.Out, so.output, recs, currencyCode, so.SavingsDisplayOptions)
+	}
+
+	display.WriteSavingsTable(ko.Out, recs, currencyCode, so.SavingsDisplayOptions)
+	return nil
+}
+
+// selectRecommendations returns the recommendations which save at least
+// --min-savings, sorted and limited by --sort-by and --top. Patches are
+// written for the same recommendations as are shown.
+func (so *SavingsOptions) selectRecommendations(allRecs []query.RequestSizingRecommendation) []query.RequestSizingRecommendation {
+	var recs []query.RequestSizingRecommendation
+	for _, rec := range allRecs {
+		if rec.MonthlySavings.CPU+rec.MonthlySavings.Memory >= so.minSavings {
+			recs = append(recs, rec)
+		}
+	}
+	return display.SortSavings(recs, so.SavingsDisplayOptions)
+}
+
+// writeSavingsPatches writes the recommendations as patches, or as a
+// Kustomize Component, to the --emit-patches directory.
+func writeSavingsPatches(ko *utilities.KubeOptions, so *SavingsOptions, recs []query.RequestSizingRecommendation) error {
+	changes, skipped, err := rightsizing.Changes(recs, so.rightsizingOptions.options())
+	if err != nil {
+		return fmt.Errorf("building patches: %s", err)
+	}
+
+	for _, rec := range skipped {
+		fmt.Fprintf(ko.ErrOut, "Note: no patch for container %s of %s/%s/%s, which is not managed by a supported controller\n", rec.ContainerName, rec.Namespace, rec.ControllerKind, rec.ControllerName)
+	}
+
+	var paths []string
+	if so.output == "kustomize" {
+		paths, err = rightsizing.WriteKustomizeComponent(so.emitPatches, changes)
+	} else {
+		paths, err = rightsizing.WritePatches(so.emitPatches, changes)
+	}
+	if err != nil {
+		return fmt.Errorf("writing patches: %s", err)
+	}
+
+	fmt.Fprintf(ko.ErrOut, "Wrote %d files to %s\n", len(paths), so.emitPatches)
 	return nil
 }
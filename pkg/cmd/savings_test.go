@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/kubecost/kubectl-cost/pkg/cmd/display"
+	"github.com/kubecost/kubectl-cost/pkg/cmd/utilities"
+	"github.com/kubecost/kubectl-cost/pkg/query"
+)
+
+func TestRecommendationQueryParamsFilter(t *testing.T) {
+	cases := []struct {
+		name      string
+		filter    string
+		namespace string
+		expected  string
+	}{
+		{"none", "", "", ""},
+		{"filter", `controllerKind:"deployment"`, "", `(controllerKind:"deployment")`},
+		{"namespace", "", "prod", `namespace:"prod"`},
+		{"or filter with namespace", `label[team]:"a" | label[team]:"b"`, "prod", `(label[team]:"a" | label[team]:"b")+namespace:"prod"`},
+	}
+
+	for _, c := range cases {
+		params := recommendationOptions{window: "2d", filter: c.filter}.queryParams(c.namespace)
+		got, ok := params["filter"]
+		if c.expected == "" && ok {
+			t.Errorf("%s: expected no filter, got %q", c.name, got)
+		} else if got != c.expected {
+			t.Errorf("%s: expected filter %q, got %q", c.name, c.expected, got)
+		}
+	}
+}
+
+func TestSavingsPatchesFollowTop(t *testing.T) {
+	rec := func(name string, savings float64) query.RequestSizingRecommendation {
+		r := query.RequestSizingRecommendation{Namespace: "prod", ControllerKind: "deployment", ControllerName: name, ContainerName: "app"}
+		r.RecommendedRequest.CPU = "100m"
+		r.MonthlySavings.CPU = savings
+		return r
+	}
+
+	dir := t.TempDir()
+	so := &SavingsOptions{
+		minSavings:            2,
+		emitPatches:           dir,
+		SavingsDisplayOptions: display.SavingsDisplayOptions{Top: 2},
+	}
+	recs := so.selectRecommendations([]query.RequestSizingRecommendation{
+		rec("small", 1), rec("medium", 5), rec("large", 20), rec("big", 10),
+	})
+
+	var names []string
+	for _, r := range recs {
+		names = append(names, r.ControllerName)
+	}
+	if strings.Join(names, ",") != "large,big" {
+		t.Errorf("expected the top 2 recommendations, got %v", names)
+	}
+
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	if err := writeSavingsPatches(utilities.NewKubeOptions(streams), so, recs); err != nil {
+		t.Fatalf("writing patches: %s", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written []string
+	for _, f := range files {
+		written = append(written, filepath.Base(f))
+	}
+	if strings.Join(written, ",") != "prod-deployment-big.yaml,prod-deployment-large.yaml" {
+		t.Errorf("expected patches for the shown recommendations, got %v", written)
+	}
+}
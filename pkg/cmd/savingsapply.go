@@ -0,0 +1,412 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kubecost/kubectl-cost/pkg/cmd/display"
+	"github.com/kubecost/kubectl-cost/pkg/cmd/utilities"
+	"github.com/kubecost/kubectl-cost/pkg/query"
+	"github.com/kubecost/kubectl-cost/pkg/rightsizing"
+
+	"github.com/opencost/opencost/core/pkg/log"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// liveChangeOptions are the options shared by commands which change
+// controllers in the cluster.
+type liveChangeOptions struct {
+	namespace     string
+	allNamespaces bool
+
+	// One of none, client or server, like kubectl's --dry-run.
+	dryRun string
+
+	// Skips the confirmation prompt.
+	yes bool
+}
+
+func addLiveChangeFlags(cmd *cobra.Command, o *liveChangeOptions) {
+	cmd.Flags().StringVarP(&o.namespace, "namespace", "n", "", "The namespace of the controllers to change. Defaults to the namespace of the current context.")
+	cmd.Flags().BoolVarP(&o.allNamespaces, "all-namespaces", "A", false, "Change controllers in all namespaces.")
+	cmd.Flags().StringVar(&o.dryRun, "dry-run", "none", "One of none, client or server. 'client' only shows the changes, 'server' also submits them to the API server for validation without persisting them.")
+	cmd.Flags().BoolVarP(&o.yes, "yes", "y", false, "Apply the changes without asking for confirmation.")
+}
+
+func (o *liveChangeOptions) validate() error {
+	switch o.dryRun {
+	case "none", "client", "server":
+	default:
+		return fmt.Errorf("unsupported --dry-run value '%s', must be one of: none, client, server", o.dryRun)
+	}
+	return nil
+}
+
+// complete defaults the namespace to that of the current context. An empty
+// namespace afterwards means all namespaces.
+func (o *liveChangeOptions) complete(ko *utilities.KubeOptions) error {
+	if o.allNamespaces {
+		if o.namespace != "" {
+			return fmt.Errorf("--namespace and --all-namespaces cannot be used together")
+		}
+		return nil
+	}
+	if o.namespace == "" {
+		o.namespace = ko.DefaultNamespace
+	}
+	if o.namespace == "" {
+		o.namespace = "default"
+	}
+	return nil
+}
+
+// liveChange is a patch to a single controller in the cluster.
+type liveChange struct {
+	kind      string
+	namespace string
+	name      string
+
+	// note is shown next to the controller in the diff.
+	note       string
+	containers []rightsizing.ContainerDiff
+	patch      []byte
+}
+
+// applyLiveChanges shows the diff of changes and, after confirmation, patches
+// the controllers.
+func applyLiveChanges(ko *utilities.KubeOptions, clientset kubernetes.Interface, o liveChangeOptions, changes []liveChange) error {
+	for _, c := range changes {
+		display.WriteResourceDiff(ko.Out, c.kind, c.namespace, c.name, c.note, c.containers)
+	}
+
+	if o.dryRun == "client" {
+		fmt.Fprintf(ko.Out, "\n%d controllers would be changed (dry run)\n", len(changes))
+		return nil
+	}
+
+	if o.dryRun == "none" && !o.yes {
+		fmt.Fprintf(ko.Out, "\nChange %d controllers? [y/N]: ", len(changes))
+		answer, err := bufio.NewReader(ko.In).ReadString('\n')
+		if err != nil && answer == "" {
+			return fmt.Errorf("reading confirmation: %s", err)
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+		default:
+			fmt.Fprintf(ko.Out, "Aborted, nothing was changed\n")
+			return nil
+		}
+	}
+
+	suffix := ""
+	if o.dryRun == "server" {
+		suffix = " (server dry run)"
+	}
+	for _, c := range changes {
+		if err := rightsizing.Patch(context.Background(), clientset, c.kind, c.namespace, c.name, c.patch, o.dryRun == "server"); err != nil {
+			return err
+		}
+		fmt.Fprintf(ko.Out, "%s %s/%s changed%s\n", strings.ToLower(c.kind), c.namespace, c.name, suffix)
+	}
+	return nil
+}
+
+var savingsApplyExample = `
+    # Show the changes which the recommendations for the prod namespace
+    # would make, without changing anything.
+    %[1]s cost savings apply -n prod --dry-run=client
+
+    # Apply the recommendations which save at least 5 a month per
+    # controller, after confirmation.
+    %[1]s cost savings apply -n prod --min-savings 5
+`
+
+// SavingsApplyOptions contains options specific to applying recommendations.
+type SavingsApplyOptions struct {
+	recommendationOptions
+
+	// The minimum monthly savings of a controller for its recommendations
+	// to be applied.
+	minSavings float64
+
+	rightsizingOptions
+	liveChangeOptions
+
+	query.QueryBackendOptions
+}
+
+func newCmdSavingsApply(
+	streams genericclioptions.IOStreams,
+) *cobra.Command {
+	kubeO := utilities.NewKubeOptions(streams)
+	applyO := &SavingsApplyOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "apply",
+		Short:   "Set the requests of live Deployments, StatefulSets and DaemonSets to the recommended requests.",
+		Example: fmt.Sprintf(savingsApplyExample, "kubectl"),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := kubeO.Complete(c, args); err != nil {
+				return fmt.Errorf("complete k8s options: %s", err)
+			}
+			if err := kubeO.Validate(); err != nil {
+				return fmt.Errorf("validate k8s options: %s", err)
+			}
+
+			if err := applyO.Complete(kubeO); err != nil {
+				return fmt.Errorf("complete: %s", err)
+			}
+			if err := applyO.Validate(); err != nil {
+				return fmt.Errorf("validate: %s", err)
+			}
+
+			return runSavingsApply(kubeO, applyO)
+		},
+	}
+	addRecommendationFlags(cmd, &applyO.recommendationOptions)
+	cmd.Flags().Float64Var(&applyO.minSavings, "min-savings", 0, "Only change controllers whose recommendations save at least this much per month.")
+
+	addRightsizingFlags(cmd, &applyO.rightsizingOptions)
+	addLiveChangeFlags(cmd, &applyO.liveChangeOptions)
+	query.AddQueryBackendOptionsFlags(cmd, &applyO.QueryBackendOptions)
+	utilities.AddKubeOptionsFlags(cmd, kubeO)
+
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func (applyO *SavingsApplyOptions) Validate() error {
+	if applyO.minSavings < 0 {
+		return fmt.Errorf("--min-savings cannot be negative")
+	}
+	if err := applyO.recommendationOptions.validate(); err != nil {
+		return err
+	}
+	if err := applyO.rightsizingOptions.validate(); err != nil {
+		return err
+	}
+	if err := applyO.liveChangeOptions.validate(); err != nil {
+		return err
+	}
+
+	if err := applyO.QueryBackendOptions.Validate(); err != nil {
+		return fmt.Errorf("validating query options: %s", err)
+	}
+
+	return nil
+}
+
+func (applyO *SavingsApplyOptions) Complete(ko *utilities.KubeOptions) error {
+	if err := applyO.liveChangeOptions.complete(ko); err != nil {
+		return err
+	}
+
+	if err := applyO.QueryBackendOptions.Complete(ko.RestConfig); err != nil {
+		return fmt.Errorf("complete backend opts: %s", err)
+	}
+	return nil
+}
+
+func runSavingsApply(ko *utilities.KubeOptions, ao *SavingsApplyOptions) error {
+	ctx := context.Background()
+
+	currencyCode, err := query.QueryCurrencyCode(query.CurrencyCodeParameters{
+		Ctx:                 ctx,
+		QueryBackendOptions: ao.QueryBackendOptions,
+	})
+	if err != nil {
+		log.Debugf("failed to get currency code, displaying as empty string: %s", err)
+		currencyCode = ""
+	}
+
+	clusterID, err := query.QueryClusterID(query.ClusterInfoParameters{
+		Ctx:                 ctx,
+		QueryBackendOptions: ao.QueryBackendOptions,
+	})
+	if err != nil {
+		return fmt.Errorf("acquiring cluster ID from service: %s", err)
+	}
+
+	allRecs, err := query.QuerySavings(query.SavingsParameters{
+		Ctx:                 ctx,
+		QueryBackendOptions: ao.QueryBackendOptions,
+		QueryParams:         ao.queryParams(ao.namespace),
+	})
+	if err != nil {
+		return fmt.Errorf("querying savings API: %s", err)
+	}
+
+	// Only recommendations for the cluster of the current context can be
+	// applied to it.
+	var recs []query.RequestSizingRecommendation
+	savings := map[string]float64{}
+	for _, rec := range allRecs {
+		if rec.ClusterID != "" && rec.ClusterID != clusterID {
+			continue
+		}
+		if ao.namespace != "" && rec.Namespace != ao.namespace {
+			continue
+		}
+		recs = append(recs, rec)
+		key := fmt.Sprintf("%s/%s/%s", rec.Namespace, strings.ToLower(rec.ControllerKind), rec.ControllerName)
+		savings[key] += rec.MonthlySavings.CPU + rec.MonthlySavings.Memory
+	}
+
+	changes, skipped, err := rightsizing.Changes(recs, ao.rightsizingOptions.options())
+	if err != nil {
+		return fmt.Errorf("building changes: %s", err)
+	}
+	for _, rec := range skipped {
+		fmt.Fprintf(ko.ErrOut, "Note: skipping container %s of %s/%s/%s, which is not managed by a supported controller\n", rec.ContainerName, rec.Namespace, rec.ControllerKind, rec.ControllerName)
+	}
+
+	clientset, err := kubernetes.NewForConfig(ko.RestConfig)
+	if err != nil {
+		return fmt.Errorf("creating clientset: %s", err)
+	}
+
+	var liveChanges []liveChange
+	for _, c := range changes {
+		ref := fmt.Sprintf("%s/%s/%s", c.Namespace, strings.ToLower(c.Kind), c.Name)
+		if !rightsizing.CanApplyLive(c.Kind) {
+			fmt.Fprintf(ko.ErrOut, "Note: skipping %s, only Deployments, StatefulSets and DaemonSets can be changed in the cluster, use 'savings --emit-patches' instead\n", ref)
+			continue
+		}
+		if savings[ref] < ao.minSavings {
+			continue
+		}
+
+		live, err := rightsizing.GetLiveController(ctx, clientset, c.Kind, c.Namespace, c.Name)
+		if k8serrors.IsNotFound(err) {
+			fmt.Fprintf(ko.ErrOut, "Note: skipping %s, which no longer exists\n", ref)
+			continue
+		} else if err != nil {
+			return fmt.Errorf("getting %s: %s", ref, err)
+		}
+
+		before := map[string]corev1.ResourceRequirements{}
+		for _, res := range c.Containers {
+			before[res.Name] = live.Containers[res.Name]
+		}
+		containers := rightsizing.Diff(before, c.Resources(live))
+		if len(containers) == 0 {
+			continue
+		}
+
+		patch, err := rightsizing.ApplyPatch(c, live)
+		if err != nil {
+			return err
+		}
+		liveChanges = append(liveChanges, liveChange{
+			kind:       c.Kind,
+			namespace:  c.Namespace,
+			name:       c.Name,
+			note:       fmt.Sprintf("saves %.2f %s/mo", savings[ref], currencyCode),
+			containers: containers,
+			patch:      patch,
+		})
+	}
+
+	if len(liveChanges) == 0 {
+		fmt.Fprintf(ko.Out, "No changes to apply\n")
+		return nil
+	}
+
+	return applyLiveChanges(ko, clientset, ao.liveChangeOptions, liveChanges)
+}
+
+var savingsRevertExample = `
+    # Restore the requests which controllers in the prod namespace had
+    # before recommendations were applied to them.
+    %[1]s cost savings revert -n prod
+`
+
+// SavingsRevertOptions contains options specific to reverting applied
+// recommendations.
+type SavingsRevertOptions struct {
+	liveChangeOptions
+}
+
+func newCmdSavingsRevert(
+	streams genericclioptions.IOStreams,
+) *cobra.Command {
+	kubeO := utilities.NewKubeOptions(streams)
+	revertO := &SavingsRevertOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "revert",
+		Short:   "Restore the requests which live controllers had before 'savings apply' changed them.",
+		Example: fmt.Sprintf(savingsRevertExample, "kubectl"),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := kubeO.Complete(c, args); err != nil {
+				return fmt.Errorf("complete k8s options: %s", err)
+			}
+			if err := kubeO.Validate(); err != nil {
+				return fmt.Errorf("validate k8s options: %s", err)
+			}
+
+			if err := revertO.liveChangeOptions.complete(kubeO); err != nil {
+				return fmt.Errorf("complete: %s", err)
+			}
+			if err := revertO.liveChangeOptions.validate(); err != nil {
+				return fmt.Errorf("validate: %s", err)
+			}
+
+			return runSavingsRevert(kubeO, revertO)
+		},
+	}
+
+	addLiveChangeFlags(cmd, &revertO.liveChangeOptions)
+	utilities.AddKubeOptionsFlags(cmd, kubeO)
+
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func runSavingsRevert(ko *utilities.KubeOptions, ro *SavingsRevertOptions) error {
+	clientset, err := kubernetes.NewForConfig(ko.RestConfig)
+	if err != nil {
+		return fmt.Errorf("creating clientset: %s", err)
+	}
+
+	controllers, err := rightsizing.ListRevertable(context.Background(), clientset, ro.namespace)
+	if err != nil {
+		return err
+	}
+
+	var liveChanges []liveChange
+	for _, live := range controllers {
+		previous, err := live.PreviousResources()
+		if err != nil {
+			return err
+		}
+		patch, err := rightsizing.RevertPatch(live)
+		if err != nil {
+			return err
+		}
+		liveChanges = append(liveChanges, liveChange{
+			kind:       live.Kind,
+			namespace:  live.Namespace,
+			name:       live.Name,
+			containers: rightsizing.Diff(live.Containers, previous),
+			patch:      patch,
+		})
+	}
+
+	if len(liveChanges) == 0 {
+		fmt.Fprintf(ko.Out, "No controllers with applied recommendations found\n")
+		return nil
+	}
+
+	return applyLiveChanges(ko, clientset, ro.liveChangeOptions, liveChanges)
+}
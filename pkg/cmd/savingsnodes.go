@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/kubecost/kubectl-cost/pkg/capacity"
+	"github.com/kubecost/kubectl-cost/pkg/cmd/display"
+	"github.com/kubecost/kubectl-cost/pkg/cmd/utilities"
+	"github.com/kubecost/kubectl-cost/pkg/query"
+
+	"github.com/opencost/opencost/core/pkg/log"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/rest"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+var savingsNodesExample = `
+    # Show how much of each node pool's capacity is requested, and what
+    # running the same requests on fewer or smaller nodes would save.
+    %[1]s cost savings nodes
+
+    # Leave more headroom, and keep at least 3 nodes per pool.
+    %[1]s cost savings nodes --target-utilization 0.6 --min-nodes 3
+`
+
+// SavingsNodesOptions contains options specific to node consolidation
+// recommendations.
+type SavingsNodesOptions struct {
+	window string
+
+	// Nodes with CPU and RAM requests below this fraction of their capacity
+	// are listed as under-utilized.
+	underutilized float64
+
+	capacity.ConsolidationOptions
+
+	query.QueryBackendOptions
+}
+
+func newCmdSavingsNodes(
+	streams genericclioptions.IOStreams,
+) *cobra.Command {
+	kubeO := utilities.NewKubeOptions(streams)
+	nodesO := &SavingsNodesOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "nodes",
+		Short:   "Show under-utilized nodes and node pools, and what consolidating them onto fewer or smaller nodes would save.",
+		Example: fmt.Sprintf(savingsNodesExample, "kubectl"),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := kubeO.Complete(c, args); err != nil {
+				return fmt.Errorf("complete k8s options: %s", err)
+			}
+			if err := kubeO.Validate(); err != nil {
+				return fmt.Errorf("validate k8s options: %s", err)
+			}
+
+			if err := nodesO.Complete(kubeO.RestConfig); err != nil {
+				return fmt.Errorf("complete: %s", err)
+			}
+			if err := nodesO.Validate(); err != nil {
+				return fmt.Errorf("validate: %s", err)
+			}
+
+			return runSavingsNodes(kubeO, nodesO)
+		},
+	}
+	cmd.Flags().StringVarP(&nodesO.window, "window", "w", "7d", "The window of cost and request data to consider. See https://github.com/kubecost/docs/blob/master/allocation.md#querying for a detailed explanation of what can be passed here.")
+	cmd.Flags().Float64Var(&nodesO.TargetUtilization, "target-utilization", 0.8, "The fraction of the CPU and RAM of recommended nodes which may be requested, leaving the rest as headroom.")
+	cmd.Flags().IntVar(&nodesO.MinNodes, "min-nodes", 1, "The smallest number of nodes to recommend for a pool.")
+	cmd.Flags().Float64Var(&nodesO.underutilized, "underutilized", 0.5, "List nodes whose CPU and RAM requests are both below this fraction of their capacity as under-utilized.")
+
+	query.AddQueryBackendOptionsFlags(cmd, &nodesO.QueryBackendOptions)
+	utilities.AddKubeOptionsFlags(cmd, kubeO)
+
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func (nodesO *SavingsNodesOptions) Validate() error {
+	if nodesO.TargetUtilization <= 0 || nodesO.TargetUtilization > 1 {
+		return fmt.Errorf("--target-utilization must be a fraction between 0 and 1, got %g", nodesO.TargetUtilization)
+	}
+	if nodesO.MinNodes < 1 {
+		return fmt.Errorf("--min-nodes must be at least 1")
+	}
+	if nodesO.underutilized < 0 || nodesO.underutilized > 1 {
+		return fmt.Errorf("--underutilized must be a fraction between 0 and 1, got %g", nodesO.underutilized)
+	}
+
+	if err := nodesO.QueryBackendOptions.Validate(); err != nil {
+		return fmt.Errorf("validating query options: %s", err)
+	}
+
+	return nil
+}
+
+func (nodesO *SavingsNodesOptions) Complete(restConfig *rest.Config) error {
+	if err := nodesO.QueryBackendOptions.Complete(restConfig); err != nil {
+		return fmt.Errorf("complete backend opts: %s", err)
+	}
+	return nil
+}
+
+func runSavingsNodes(ko *utilities.KubeOptions, no *SavingsNodesOptions) error {
+	currencyCode, err := query.QueryCurrencyCode(query.CurrencyCodeParameters{
+		Ctx:                 context.Background(),
+		QueryBackendOptions: no.QueryBackendOptions,
+	})
+	if err != nil {
+		log.Debugf("failed to get currency code, displaying as empty string: %s", err)
+		currencyCode = ""
+	}
+
+	nodes, err := queryNodeCapacity(no.QueryBackendOptions, no.window)
+	if err != nil {
+		return err
+	}
+
+	recs := capacity.Recommend(capacity.Pools(nodes), capacity.Shapes(nodes), no.ConsolidationOptions)
+	display.WriteNodeConsolidationTable(ko.Out, recs, currencyCode)
+
+	var underutilized []capacity.Node
+	for _, n := range nodes {
+		if n.CPURequestUtilization() < no.underutilized && n.RAMRequestUtilization() < no.underutilized {
+			underutilized = append(underutilized, n)
+		}
+	}
+	if len(underutilized) > 0 {
+		sort.SliceStable(underutilized, func(i, j int) bool {
+			return underutilized[i].MonthlyRate > underutilized[j].MonthlyRate
+		})
+		fmt.Fprintf(ko.Out, "\nUnder-utilized nodes, with less than %.0f%% of CPU and RAM requested:\n", no.underutilized*100)
+		display.WriteNodeUtilizationTable(ko.Out, underutilized, currencyCode)
+	}
+
+	fmt.Fprintf(ko.Out, "\nRecommendations are based on total requests. Pods must fit the recommended node type, and DaemonSet pods are added to every node.\n")
+	return nil
+}
+
+// queryNodeCapacity joins the node assets of window with the requests and
+// usage of the allocations on each node.
+func queryNodeCapacity(qo query.QueryBackendOptions, window string) ([]capacity.Node, error) {
+	nodeSets, err := query.QueryAssets(query.AssetParameters{
+		Ctx:                 context.Background(),
+		Window:              window,
+		Accumulate:          "true",
+		FilterTypes:         "Node",
+		QueryBackendOptions: qo,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("querying node assets: %s", err)
+	}
+	var assets []query.AssetNode
+	for _, set := range nodeSets {
+		for _, n := range set {
+			assets = append(assets, n)
+		}
+	}
+	if len(assets) == 0 {
+		return nil, fmt.Errorf("no node assets in window '%s'", window)
+	}
+
+	allocations, err := queryAccumulatedAllocations(qo, map[string]string{
+		"window":    window,
+		"aggregate": "cluster,node",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return capacity.Join(assets, allocations), nil
+}
@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubecost/kubectl-cost/pkg/cmd/display"
+	"github.com/kubecost/kubectl-cost/pkg/cmd/utilities"
+	"github.com/kubecost/kubectl-cost/pkg/query"
+	"github.com/kubecost/kubectl-cost/pkg/waste"
+
+	"github.com/opencost/opencost/core/pkg/log"
+	"github.com/opencost/opencost/core/pkg/opencost"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+var savingsAbandonedExample = `
+    # List workloads which used less than 10m CPU and sent or received less
+    # than 500 bytes per second on average over the last week.
+    %[1]s cost savings abandoned
+
+    # Use stricter thresholds for the dev namespace.
+    %[1]s cost savings abandoned -n dev --max-cpu 0.005 --max-traffic 100
+`
+
+// SavingsAbandonedOptions contains options specific to finding abandoned
+// workloads.
+type SavingsAbandonedOptions struct {
+	window    string
+	namespace string
+
+	waste.AbandonedOptions
+
+	query.QueryBackendOptions
+}
+
+func newCmdSavingsAbandoned(
+	streams genericclioptions.IOStreams,
+) *cobra.Command {
+	kubeO := utilities.NewKubeOptions(streams)
+	abandonedO := &SavingsAbandonedOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "abandoned",
+		Short:   "List workloads with negligible CPU usage and network traffic, which may no longer be needed.",
+		Example: fmt.Sprintf(savingsAbandonedExample, "kubectl"),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := kubeO.Complete(c, args); err != nil {
+				return fmt.Errorf("complete k8s options: %s", err)
+			}
+			if err := kubeO.Validate(); err != nil {
+				return fmt.Errorf("validate k8s options: %s", err)
+			}
+
+			if err := abandonedO.Complete(kubeO.RestConfig); err != nil {
+				return fmt.Errorf("complete: %s", err)
+			}
+			if err := abandonedO.Validate(); err != nil {
+				return fmt.Errorf("validate: %s", err)
+			}
+
+			return runSavingsAbandoned(kubeO, abandonedO)
+		},
+	}
+	cmd.Flags().StringVarP(&abandonedO.window, "window", "w", "7d", "The window of usage data to consider. See https://github.com/kubecost/docs/blob/master/allocation.md#querying for a detailed explanation of what can be passed here.")
+	cmd.Flags().StringVarP(&abandonedO.namespace, "namespace", "n", "", "Only consider workloads in this namespace.")
+	cmd.Flags().Float64Var(&abandonedO.MaxCPUCores, "max-cpu", 0.01, "The highest average CPU usage, in cores, of a workload considered abandoned.")
+	cmd.Flags().Float64Var(&abandonedO.MaxTrafficBytesPerSecond, "max-traffic", 500, "The highest average network traffic, sent and received, in bytes per second of a workload considered abandoned.")
+
+	query.AddQueryBackendOptionsFlags(cmd, &abandonedO.QueryBackendOptions)
+	utilities.AddKubeOptionsFlags(cmd, kubeO)
+
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func (abandonedO *SavingsAbandonedOptions) Validate() error {
+	if abandonedO.MaxCPUCores < 0 || abandonedO.MaxTrafficBytesPerSecond < 0 {
+		return fmt.Errorf("--max-cpu and --max-traffic cannot be negative")
+	}
+
+	if err := abandonedO.QueryBackendOptions.Validate(); err != nil {
+		return fmt.Errorf("validating query options: %s", err)
+	}
+
+	return nil
+}
+
+func (abandonedO *SavingsAbandonedOptions) Complete(restConfig *rest.Config) error {
+	if err := abandonedO.QueryBackendOptions.Complete(restConfig); err != nil {
+		return fmt.Errorf("complete backend opts: %s", err)
+	}
+	return nil
+}
+
+func runSavingsAbandoned(ko *utilities.KubeOptions, ao *SavingsAbandonedOptions) error {
+	currencyCode, err := query.QueryCurrencyCode(query.CurrencyCodeParameters{
+		Ctx:                 context.Background(),
+		QueryBackendOptions: ao.QueryBackendOptions,
+	})
+	if err != nil {
+		log.Debugf("failed to get currency code, displaying as empty string: %s", err)
+		currencyCode = ""
+	}
+
+	allocations, err := queryAccumulatedAllocations(ao.QueryBackendOptions, map[string]string{
+		"window":           ao.window,
+		"aggregate":        "cluster,namespace,controllerKind,controller",
+		"filterNamespaces": ao.namespace,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !waste.HasNetworkData(allocations) {
+		fmt.Fprintf(ko.ErrOut, "Note: no network traffic is recorded in window '%s', so only CPU usage is considered. Enable Kubecost's network cost monitoring to take traffic into account.\n", ao.window)
+	}
+
+	display.WriteAbandonedTable(ko.Out, waste.FindAbandoned(allocations, ao.AbandonedOptions), currencyCode)
+	return nil
+}
+
+// queryAccumulatedAllocations queries allocations accumulated over the whole
+// window.
+func queryAccumulatedAllocations(qo query.QueryBackendOptions, params map[string]string) (map[string]opencost.Allocation, error) {
+	params["accumulate"] = "true"
+	allocations, err := query.QueryAllocation(query.AllocationParameters{
+		Ctx:                 context.Background(),
+		QueryParams:         params,
+		QueryBackendOptions: qo,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query allocation API: %s", err)
+	}
+	if len(allocations) == 0 {
+		return nil, fmt.Errorf("no allocation data in window '%s'", params["window"])
+	}
+	return allocations[0], nil
+}
+
+var savingsUnclaimedVolumesExample = `
+    # List persistent volumes which no running pod mounts, and what they
+    # cost.
+    %[1]s cost savings unclaimed-volumes
+`
+
+// SavingsUnclaimedVolumesOptions contains options specific to finding
+// unclaimed volumes.
+type SavingsUnclaimedVolumesOptions struct {
+	window string
+
+	query.QueryBackendOptions
+}
+
+func newCmdSavingsUnclaimedVolumes(
+	streams genericclioptions.IOStreams,
+) *cobra.Command {
+	kubeO := utilities.NewKubeOptions(streams)
+	volumesO := &SavingsUnclaimedVolumesOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "unclaimed-volumes",
+		Short:   "List persistent volumes which are not mounted by any running pod, with their monthly cost.",
+		Example: fmt.Sprintf(savingsUnclaimedVolumesExample, "kubectl"),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := kubeO.Complete(c, args); err != nil {
+				return fmt.Errorf("complete k8s options: %s", err)
+			}
+			if err := kubeO.Validate(); err != nil {
+				return fmt.Errorf("validate k8s options: %s", err)
+			}
+
+			if err := volumesO.Complete(kubeO.RestConfig); err != nil {
+				return fmt.Errorf("complete: %s", err)
+			}
+			if err := volumesO.Validate(); err != nil {
+				return fmt.Errorf("validate: %s", err)
+			}
+
+			return runSavingsUnclaimedVolumes(kubeO, volumesO)
+		},
+	}
+	cmd.Flags().StringVarP(&volumesO.window, "window", "w", "7d", "The window of cost and usage data to consider. Volumes which pods used during the window are marked as such. See https://github.com/kubecost/docs/blob/master/allocation.md#querying for a detailed explanation of what can be passed here.")
+
+	query.AddQueryBackendOptionsFlags(cmd, &volumesO.QueryBackendOptions)
+	utilities.AddKubeOptionsFlags(cmd, kubeO)
+
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func (volumesO *SavingsUnclaimedVolumesOptions) Validate() error {
+	if err := volumesO.QueryBackendOptions.Validate(); err != nil {
+		return fmt.Errorf("validating query options: %s", err)
+	}
+
+	return nil
+}
+
+func (volumesO *SavingsUnclaimedVolumesOptions) Complete(restConfig *rest.Config) error {
+	if err := volumesO.QueryBackendOptions.Complete(restConfig); err != nil {
+		return fmt.Errorf("complete backend opts: %s", err)
+	}
+	return nil
+}
+
+func runSavingsUnclaimedVolumes(ko *utilities.KubeOptions, vo *SavingsUnclaimedVolumesOptions) error {
+	ctx := context.Background()
+
+	currencyCode, err := query.QueryCurrencyCode(query.CurrencyCodeParameters{
+		Ctx:                 ctx,
+		QueryBackendOptions: vo.QueryBackendOptions,
+	})
+	if err != nil {
+		log.Debugf("failed to get currency code, displaying as empty string: %s", err)
+		currencyCode = ""
+	}
+
+	// Volumes are read from the cluster of the current context, so only its
+	// usage and disks are relevant.
+	clusterID, err := query.QueryClusterID(query.ClusterInfoParameters{
+		Ctx:                 ctx,
+		QueryBackendOptions: vo.QueryBackendOptions,
+	})
+	if err != nil {
+		return fmt.Errorf("acquiring cluster ID from service: %s", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(ko.RestConfig)
+	if err != nil {
+		return fmt.Errorf("creating clientset: %s", err)
+	}
+	pvs, err := clientset.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing persistent volumes: %s", err)
+	}
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing pods: %s", err)
+	}
+
+	allocations, err := queryAccumulatedAllocations(vo.QueryBackendOptions, map[string]string{
+		"window":    vo.window,
+		"aggregate": "cluster,namespace",
+	})
+	if err != nil {
+		return err
+	}
+
+	diskSets, err := query.QueryDiskAssets(query.AssetParameters{
+		Ctx:                 ctx,
+		Window:              vo.window,
+		Accumulate:          "true",
+		QueryBackendOptions: vo.QueryBackendOptions,
+	})
+	if err != nil {
+		return fmt.Errorf("querying disk assets: %s", err)
+	}
+	var disks []query.AssetDisk
+	for _, set := range diskSets {
+		for _, d := range set {
+			if d.Properties.Cluster == clusterID {
+				disks = append(disks, d)
+			}
+		}
+	}
+
+	volumes := waste.FindUnclaimedVolumes(pvs.Items, pods.Items, waste.UsedVolumes(allocations, clusterID), waste.NewVolumeCosts(disks))
+	display.WriteUnclaimedVolumesTable(ko.Out, volumes, currencyCode)
+	return nil
+}
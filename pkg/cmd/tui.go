@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -13,10 +14,13 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/kubecost/kubectl-cost/pkg/cmd/display"
 	"github.com/kubecost/kubectl-cost/pkg/cmd/utilities"
+	"github.com/kubecost/kubectl-cost/pkg/period"
 	"github.com/kubecost/kubectl-cost/pkg/query"
 	"github.com/opencost/opencost/core/pkg/log"
 	"github.com/opencost/opencost/core/pkg/opencost"
 	"github.com/rivo/tview"
+	"github.com/rs/zerolog"
+	zlog "github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
 
@@ -208,11 +212,115 @@ func buildWindowDropdown(windowIndex *int, requeryData func()) *tview.DropDown {
 	return windowDropdown
 }
 
+// spinnerFrames are cycled through in the status bar while a query is in
+// progress.
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// tuiStatus is what the TUI's status bar shows. It is updated by queries
+// running in the background and read whenever the status bar is drawn.
+type tuiStatus struct {
+	mu sync.Mutex
+
+	// backend describes the service queried and the kube context.
+	backend string
+
+	querying   bool
+	queryStart time.Time
+	queryTook  time.Duration
+
+	rows        int
+	lastUpdated time.Time
+
+	lastErr   string
+	lastErrAt time.Time
+}
+
+func (s *tuiStatus) startQuery(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.querying = true
+	s.queryStart = now
+}
+
+func (s *tuiStatus) finishQuery(rows int, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.querying = false
+	s.queryTook = now.Sub(s.queryStart)
+	s.rows = rows
+	s.lastUpdated = now
+	s.lastErr = ""
+}
+
+func (s *tuiStatus) failQuery(err string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.querying = false
+	s.lastErr = err
+	s.lastErrAt = now
+}
+
+func (s *tuiStatus) isQuerying() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.querying
+}
+
+// render returns the two lines of the status bar, with tview color tags. The
+// first is the query state and the backend, the second the last error or,
+// if there is none, the key bindings.
+func (s *tuiStatus) render(now time.Time) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var state string
+	switch {
+	case s.querying:
+		elapsed := now.Sub(s.queryStart)
+		frame := spinnerFrames[int(elapsed/(100*time.Millisecond))%len(spinnerFrames)]
+		state = fmt.Sprintf("[yellow]%c Querying... %.1fs[-]", frame, elapsed.Seconds())
+	case s.lastUpdated.IsZero():
+		state = "No data"
+	default:
+		state = fmt.Sprintf("[green]%d rows[-], updated %s in %.1fs", s.rows, s.lastUpdated.Format("15:04:05"), s.queryTook.Seconds())
+	}
+
+	second := "[gray]Ctrl-L: toggle logs  Ctrl-C: quit[-]"
+	if s.lastErr != "" {
+		second = fmt.Sprintf("[red]Error at %s: %s[-]", s.lastErrAt.Format("15:04:05"), tview.Escape(s.lastErr))
+	}
+
+	return fmt.Sprintf("%s | %s\n%s", state, tview.Escape(s.backend), second)
+}
+
 func runTUI(ko *utilities.KubeOptions, do display.AllocationDisplayOptions, qo query.QueryBackendOptions) error {
 	app := tview.NewApplication()
 
 	table := tview.NewTable()
 
+	// Logs written to the terminal would corrupt the TUI, so they are
+	// written to a log pane instead while it runs.
+	// Drawing on every line would flood the app with draws when logging a
+	// lot, so changes are drawn by the ticker below instead.
+	var logChanged atomic.Bool
+	logView := tview.NewTextView().SetMaxLines(1000).ScrollToEnd()
+	logView.SetChangedFunc(func() { logChanged.Store(true) })
+	logView.SetTitle(" Logs ").SetBorder(true)
+	defer func(logger zerolog.Logger) {
+		zlog.Logger = logger
+	}(zlog.Logger)
+	zlog.Logger = zlog.Output(zerolog.ConsoleWriter{Out: logView, TimeFormat: "15:04:05", NoColor: true})
+
+	backend := qo.Describe()
+	if ko.CurrentContext != "" {
+		backend = fmt.Sprintf("%s, context %s", backend, ko.CurrentContext)
+	}
+	status := &tuiStatus{backend: backend}
+	statusBar := tview.NewTextView().SetDynamicColors(true).SetWrap(false)
+	updateStatusBar := func() {
+		statusBar.SetText(status.render(time.Now()))
+	}
+
 	var allocations map[string]opencost.Allocation
 	var allocMutex sync.Mutex
 	var lastUpdated time.Time
@@ -242,7 +350,7 @@ func runTUI(ko *utilities.KubeOptions, do display.AllocationDisplayOptions, qo q
 		// table here. This TUI library needs us to build tables from a 2D array.
 		// The CSV-rendered (string) go-pretty table, nicely sorted and everything,
 		// is parsed into a 2D array and then the TUI table is built from that.
-		tWriter := display.MakeAllocationTable(aggregation, allocations, do, currencyCode, true)
+		tWriter := display.MakeAllocationTable(aggregation, allocations, do, currencyCode, period.DefaultMonthly())
 		serializedTable := tWriter.RenderCSV()
 
 		err := setTableFromCSV(table, serializedTable)
@@ -259,9 +367,8 @@ func runTUI(ko *utilities.KubeOptions, do display.AllocationDisplayOptions, qo q
 		// large window queries. If a user selects a large window on a large
 		// cluster without this, they will think the UI has crashed when it
 		// is merely dealing with blocking IO, waiting on the kubecost API
-		// and prometheus to aggregate a huge amount of data.
-		//
-		// TODO: Display an indication to the user that a query is in progress
+		// and prometheus to aggregate a huge amount of data. The status bar
+		// shows that a query is in progress.
 		go func() {
 			// Cancel before the lock so that a previously started query
 			// crashes out. This should prevent selecting a huge window
@@ -273,6 +380,8 @@ func runTUI(ko *utilities.KubeOptions, do display.AllocationDisplayOptions, qo q
 			defer allocMutex.Unlock()
 
 			queryContext, queryCancel = context.WithCancel(context.Background())
+			status.startQuery(time.Now())
+			app.QueueUpdateDraw(updateStatusBar)
 
 			// TODO: use flags for service name
 			queriedAllocs, err := query.QueryAllocation(query.AllocationParameters{
@@ -288,17 +397,23 @@ func runTUI(ko *utilities.KubeOptions, do display.AllocationDisplayOptions, qo q
 
 			if err != nil && strings.Contains(err.Error(), "context canceled") {
 				// do nothing, because the context got canceled to favor a more
-				// recent window request from the user
+				// recent window request from the user, which is now querying
 			} else if err != nil {
 				log.Errorf("failed to query agg cost model: %s", err)
+				status.failQuery(fmt.Sprintf("failed to query agg cost model: %s", err), time.Now())
+				app.QueueUpdateDraw(updateStatusBar)
 			} else if len(queriedAllocs) == 0 {
 				log.Errorf("Allocation response was empty. Not updating the table.")
+				status.failQuery("Allocation response was empty. Not updating the table.", time.Now())
+				app.QueueUpdateDraw(updateStatusBar)
 			} else {
 				allocations = queriedAllocs[0]
 
 				lastUpdated = time.Now()
+				status.finishQuery(len(allocations), lastUpdated)
 				app.QueueUpdateDraw(func() {
 					redrawTable()
+					updateStatusBar()
 				})
 			}
 		}()
@@ -344,9 +459,48 @@ func runTUI(ko *utilities.KubeOptions, do display.AllocationDisplayOptions, qo q
 
 	optionsFlex.AddItem(dropDownFlex, 0, 1, true)
 
-	fb := tview.NewFlex().AddItem(table, 0, 1, false).AddItem(optionsFlex, 8, 1, true)
+	// The log pane is hidden until toggled, by giving it no space.
+	fb := tview.NewFlex().
+		AddItem(table, 0, 1, false).
+		AddItem(logView, 0, 0, false).
+		AddItem(optionsFlex, 8, 1, true).
+		AddItem(statusBar, 2, 0, false)
 	fb.SetDirection(tview.FlexRow)
 
+	showLogs := false
+	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() != tcell.KeyCtrlL {
+			return event
+		}
+		showLogs = !showLogs
+		if showLogs {
+			fb.ResizeItem(logView, 10, 0)
+		} else {
+			fb.ResizeItem(logView, 0, 0)
+		}
+		return nil
+	})
+
+	// Animate the spinner while a query is in progress, and draw new log
+	// lines at most once per tick.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if logChanged.Swap(false) || status.isQuerying() {
+					app.QueueUpdateDraw(updateStatusBar)
+				}
+			}
+		}
+	}()
+
+	updateStatusBar()
 	requeryData()
 
 	if err := app.SetRoot(fb, true).Run(); err != nil {
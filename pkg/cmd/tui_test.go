@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTUIStatusRender(t *testing.T) {
+	start := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name   string
+		update func(s *tuiStatus)
+		now    time.Time
+
+		// first and second are substrings expected on each line of the
+		// status bar.
+		first, second string
+	}{
+		{
+			name:   "no data",
+			update: func(s *tuiStatus) {},
+			now:    start,
+			first:  "No data | kubecost",
+			second: "Ctrl-L: toggle logs",
+		},
+		{
+			name: "querying",
+			update: func(s *tuiStatus) {
+				s.startQuery(start)
+			},
+			now:    start.Add(1250 * time.Millisecond),
+			first:  "[yellow]⠹ Querying... 1.2s[-] | kubecost",
+			second: "Ctrl-L: toggle logs",
+		},
+		{
+			name: "finished",
+			update: func(s *tuiStatus) {
+				s.startQuery(start)
+				s.finishQuery(42, start.Add(1500*time.Millisecond))
+			},
+			now:    start.Add(time.Minute),
+			first:  "[green]42 rows[-], updated 12:00:01 in 1.5s | kubecost",
+			second: "Ctrl-L: toggle logs",
+		},
+		{
+			name: "failed",
+			update: func(s *tuiStatus) {
+				s.startQuery(start)
+				s.failQuery("connection refused [503]", start.Add(2*time.Second))
+			},
+			now:    start.Add(time.Minute),
+			first:  "No data | kubecost",
+			second: "[red]Error at 12:00:02: connection refused [503[][-]",
+		},
+		{
+			name: "failed after data",
+			update: func(s *tuiStatus) {
+				s.startQuery(start)
+				s.finishQuery(3, start.Add(time.Second))
+				s.startQuery(start.Add(time.Minute))
+				s.failQuery("empty response", start.Add(61*time.Second))
+			},
+			now:    start.Add(2 * time.Minute),
+			first:  "[green]3 rows[-], updated 12:00:01 in 1.0s",
+			second: "[red]Error at 12:01:01: empty response[-]",
+		},
+		{
+			name: "error cleared by a later query",
+			update: func(s *tuiStatus) {
+				s.startQuery(start)
+				s.failQuery("timeout", start.Add(time.Second))
+				s.startQuery(start.Add(time.Minute))
+				s.finishQuery(7, start.Add(61*time.Second))
+			},
+			now:    start.Add(2 * time.Minute),
+			first:  "[green]7 rows[-], updated 12:01:01 in 1.0s",
+			second: "Ctrl-L: toggle logs",
+		},
+	}
+
+	for _, c := range cases {
+		s := &tuiStatus{backend: "kubecost"}
+		c.update(s)
+		if s.isQuerying() != (c.name == "querying") {
+			t.Errorf("%s: unexpected querying state %t", c.name, s.isQuerying())
+		}
+
+		lines := strings.Split(s.render(c.now), "\n")
+		if len(lines) != 2 {
+			t.Errorf("%s: expected 2 lines, got %q", c.name, lines)
+			continue
+		}
+		if !strings.Contains(lines[0], c.first) {
+			t.Errorf("%s: expected first line to contain %q, got %q", c.name, c.first, lines[0])
+		}
+		if !strings.Contains(lines[1], c.second) {
+			t.Errorf("%s: expected second line to contain %q, got %q", c.name, c.second, lines[1])
+		}
+	}
+}
@@ -28,6 +28,10 @@ type KubeOptions struct {
 	// in the workload spec.
 	DefaultNamespace string
 
+	// CurrentContext is the kubeconfig context in use, which may be empty if
+	// the client is configured without a kubeconfig, e.g. in-cluster.
+	CurrentContext string
+
 	genericclioptions.IOStreams
 }
 
@@ -56,6 +60,12 @@ func (o *KubeOptions) Complete(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("retrieving default namespace: %s", err)
 	}
 
+	if o.configFlags.Context != nil && *o.configFlags.Context != "" {
+		o.CurrentContext = *o.configFlags.Context
+	} else if rawConfig, err := o.configFlags.ToRawKubeConfigLoader().RawConfig(); err == nil {
+		o.CurrentContext = rawConfig.CurrentContext
+	}
+
 	return nil
 }
 
@@ -0,0 +1,346 @@
+// Package explain breaks a single workload's cost down into what it is
+// derived from: the resources each container requested and used, the rates
+// they were priced at, its volumes, network, shared and idle costs, and the
+// nodes it ran on.
+package explain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opencost/opencost/core/pkg/opencost"
+
+	"github.com/kubecost/kubectl-cost/pkg/pricing"
+)
+
+// kinds maps the kinds of workloads, and their kubectl short names, to the
+// controller kinds of Kubecost's allocations. A pod has no controller kind.
+var kinds = map[string]string{
+	"deployment":  "deployment",
+	"deploy":      "deployment",
+	"statefulset": "statefulset",
+	"sts":         "statefulset",
+	"daemonset":   "daemonset",
+	"ds":          "daemonset",
+	"replicaset":  "replicaset",
+	"rs":          "replicaset",
+	"job":         "job",
+	"pod":         "",
+	"po":          "",
+}
+
+// Workload is a controller or a bare pod.
+type Workload struct {
+	// Kind is the controller kind, which is empty for a pod.
+	Kind string
+	Name string
+}
+
+// ParseWorkload parses a workload given as kind/name, e.g. deployment/api.
+func ParseWorkload(s string) (Workload, error) {
+	kind, name, ok := strings.Cut(s, "/")
+	if !ok || name == "" {
+		return Workload{}, fmt.Errorf("workload '%s' must be given as kind/name, e.g. deployment/api", s)
+	}
+	k, ok := kinds[strings.ToLower(kind)]
+	if !ok {
+		return Workload{}, fmt.Errorf("unsupported kind '%s', must be one of: deployment, statefulset, daemonset, replicaset, job, pod", kind)
+	}
+	return Workload{Kind: k, Name: name}, nil
+}
+
+// IsPod is whether the workload is a bare pod rather than a controller.
+func (w Workload) IsPod() bool {
+	return w.Kind == ""
+}
+
+func (w Workload) String() string {
+	if w.IsPod() {
+		return "pod/" + w.Name
+	}
+	return w.Kind + "/" + w.Name
+}
+
+// QueryParams are the Allocation API filters which select the workload.
+func (w Workload) QueryParams(namespace string) map[string]string {
+	params := map[string]string{"filterNamespaces": namespace}
+	if w.IsPod() {
+		params["filterPods"] = w.Name
+	} else {
+		params["filterControllerKinds"] = w.Kind
+		params["filterControllers"] = w.Name
+	}
+	return params
+}
+
+// Resource is what one resource of a container was requested, used, and
+// charged for, in core-hours, GiB-hours or GPU-hours.
+type Resource struct {
+	RequestHours float64
+	UsageHours   float64
+
+	// Hours are the hours charged for, which are at least the requested
+	// hours.
+	Hours float64
+	Cost  float64
+}
+
+// Rate is the cost per hour charged for, or 0 if none were.
+func (r Resource) Rate() float64 {
+	if r.Hours <= 0 {
+		return 0
+	}
+	return r.Cost / r.Hours
+}
+
+func (r *Resource) add(o Resource) {
+	r.RequestHours += o.RequestHours
+	r.UsageHours += o.UsageHours
+	r.Hours += o.Hours
+	r.Cost += o.Cost
+}
+
+// Container is one container of one pod of the workload.
+type Container struct {
+	Cluster   string
+	Node      string
+	Pod       string
+	Container string
+
+	CPU Resource
+	RAM Resource
+	GPU Resource
+}
+
+// Volume is a persistent volume which the workload's pods mounted.
+type Volume struct {
+	Cluster  string
+	Name     string
+	GiBHours float64
+	Cost     float64
+}
+
+// Node is a node which the workload ran on, with the rates its resources
+// were charged at and what the workload's containers on it cost.
+type Node struct {
+	Cluster string
+	Name    string
+	Pods    int
+
+	CPU Resource
+	RAM Resource
+	GPU Resource
+}
+
+// Cost is the cost of the workload's CPU, RAM and GPU on the node.
+func (n Node) Cost() float64 {
+	return n.CPU.Cost + n.RAM.Cost + n.GPU.Cost
+}
+
+// Explanation is everything behind a workload's cost over a window.
+type Explanation struct {
+	Workload  Workload
+	Namespace string
+
+	Containers []Container
+	Volumes    []Volume
+	Nodes      []Node
+
+	CPUCost          float64
+	RAMCost          float64
+	GPUCost          float64
+	PVCost           float64
+	NetworkCost      float64
+	LoadBalancerCost float64
+	SharedCost       float64
+
+	// IdleCost is the workload's share of the idle cost of its clusters, or
+	// nodes, in proportion to its CPU, RAM and GPU cost. It is 0 if idle
+	// cost wasn't queried.
+	IdleCost float64
+
+	// Minutes is the duration of the window the workload ran in.
+	Minutes float64
+}
+
+// TotalCost is the sum of all costs, including the share of idle cost.
+func (e Explanation) TotalCost() float64 {
+	return e.CPUCost + e.RAMCost + e.GPUCost + e.PVCost + e.NetworkCost + e.LoadBalancerCost + e.SharedCost + e.IdleCost
+}
+
+// Explain explains the cost of a workload from its allocations aggregated
+// by cluster, node, pod and container, accumulated over a window.
+//
+// Idle cost is shared from clusterAllocations, which are aggregated by
+// cluster, or by cluster and node if idle is computed by node, and include
+// idle allocations. If clusterAllocations is nil, the idle share is 0.
+func Explain(w Workload, namespace string, allocations, clusterAllocations map[string]opencost.Allocation) (Explanation, error) {
+	e := Explanation{Workload: w, Namespace: namespace}
+
+	volumes := map[opencost.PVKey]*Volume{}
+	nodes := map[string]*Node{}
+	pods := map[string]map[string]bool{}
+
+	for _, alloc := range allocations {
+		if alloc.Properties == nil || alloc.IsIdle() || alloc.IsUnallocated() || alloc.IsUnmounted() {
+			continue
+		}
+		props := alloc.Properties
+		hours := alloc.Minutes() / 60
+
+		c := Container{
+			Cluster:   props.Cluster,
+			Node:      props.Node,
+			Pod:       props.Pod,
+			Container: props.Container,
+			CPU: Resource{
+				RequestHours: alloc.CPUCoreRequestAverage * hours,
+				UsageHours:   alloc.CPUCoreUsageAverage * hours,
+				Hours:        alloc.CPUCoreHours,
+				Cost:         alloc.CPUTotalCost(),
+			},
+			RAM: Resource{
+				RequestHours: alloc.RAMBytesRequestAverage / pricing.BytesPerGiB * hours,
+				UsageHours:   alloc.RAMBytesUsageAverage / pricing.BytesPerGiB * hours,
+				Hours:        alloc.RAMByteHours / pricing.BytesPerGiB,
+				Cost:         alloc.RAMTotalCost(),
+			},
+			GPU: Resource{
+				RequestHours: alloc.GPURequestAverage * hours,
+				UsageHours:   alloc.GPUUsageAverage * hours,
+				Hours:        alloc.GPUHours,
+				Cost:         alloc.GPUTotalCost(),
+			},
+		}
+		e.Containers = append(e.Containers, c)
+
+		e.CPUCost += c.CPU.Cost
+		e.RAMCost += c.RAM.Cost
+		e.GPUCost += c.GPU.Cost
+		e.PVCost += alloc.PVTotalCost()
+		e.NetworkCost += alloc.NetworkTotalCost()
+		e.LoadBalancerCost += alloc.LoadBalancerTotalCost()
+		e.SharedCost += alloc.SharedTotalCost()
+		e.Minutes = max(e.Minutes, alloc.Minutes())
+
+		for key, pv := range alloc.PVs {
+			if pv == nil {
+				continue
+			}
+			v, ok := volumes[key]
+			if !ok {
+				v = &Volume{Cluster: key.Cluster, Name: key.Name}
+				volumes[key] = v
+			}
+			v.GiBHours += pv.ByteHours / pricing.BytesPerGiB
+			v.Cost += pv.Cost + pv.Adjustment
+		}
+
+		nodeKey := c.Cluster + "/" + c.Node
+		n, ok := nodes[nodeKey]
+		if !ok {
+			n = &Node{Cluster: c.Cluster, Name: c.Node}
+			nodes[nodeKey] = n
+			pods[nodeKey] = map[string]bool{}
+		}
+		n.CPU.add(c.CPU)
+		n.RAM.add(c.RAM)
+		n.GPU.add(c.GPU)
+		pods[nodeKey][c.Pod] = true
+	}
+
+	if len(e.Containers) == 0 {
+		return e, fmt.Errorf("no cost data for %s in namespace '%s'", w, namespace)
+	}
+
+	if clusterAllocations != nil {
+		e.IdleCost = idleShare(e.Containers, clusterAllocations)
+	}
+
+	sort.Slice(e.Containers, func(i, j int) bool {
+		a, b := e.Containers[i], e.Containers[j]
+		return strings.Join([]string{a.Cluster, a.Pod, a.Container, a.Node}, "/") < strings.Join([]string{b.Cluster, b.Pod, b.Container, b.Node}, "/")
+	})
+
+	for _, v := range volumes {
+		e.Volumes = append(e.Volumes, *v)
+	}
+	sort.Slice(e.Volumes, func(i, j int) bool {
+		return e.Volumes[i].Cluster+"/"+e.Volumes[i].Name < e.Volumes[j].Cluster+"/"+e.Volumes[j].Name
+	})
+
+	for key, n := range nodes {
+		n.Pods = len(pods[key])
+		e.Nodes = append(e.Nodes, *n)
+	}
+	sort.Slice(e.Nodes, func(i, j int) bool {
+		if e.Nodes[i].Cost() != e.Nodes[j].Cost() {
+			return e.Nodes[i].Cost() > e.Nodes[j].Cost()
+		}
+		return e.Nodes[i].Cluster+"/"+e.Nodes[i].Name < e.Nodes[j].Cluster+"/"+e.Nodes[j].Name
+	})
+
+	return e, nil
+}
+
+// idleShare shares the idle CPU, RAM and GPU cost of each cluster, or node,
+// in proportion to the containers' cost of each resource, as Kubecost's
+// weighted idle sharing does.
+func idleShare(containers []Container, clusterAllocations map[string]opencost.Allocation) float64 {
+	type totals struct {
+		idle, allocated [3]float64
+	}
+	byKey := map[string]*totals{}
+	get := func(cluster, node string) *totals {
+		key := cluster + "/" + node
+		if _, ok := byKey[key]; !ok {
+			byKey[key] = &totals{}
+		}
+		return byKey[key]
+	}
+
+	byNode := false
+	for _, alloc := range clusterAllocations {
+		if alloc.Properties != nil && alloc.IsIdle() && alloc.Properties.Node != "" {
+			byNode = true
+		}
+	}
+
+	for _, alloc := range clusterAllocations {
+		if alloc.Properties == nil {
+			continue
+		}
+		node := ""
+		if byNode {
+			node = alloc.Properties.Node
+		}
+		t := get(alloc.Properties.Cluster, node)
+		costs := [3]float64{alloc.CPUTotalCost(), alloc.RAMTotalCost(), alloc.GPUTotalCost()}
+		for i := range costs {
+			if alloc.IsIdle() {
+				t.idle[i] += costs[i]
+			} else {
+				t.allocated[i] += costs[i]
+			}
+		}
+	}
+
+	var share float64
+	for _, c := range containers {
+		node := ""
+		if byNode {
+			node = c.Node
+		}
+		t, ok := byKey[c.Cluster+"/"+node]
+		if !ok {
+			continue
+		}
+		for i, cost := range []float64{c.CPU.Cost, c.RAM.Cost, c.GPU.Cost} {
+			if t.allocated[i] > 0 {
+				share += t.idle[i] * cost / t.allocated[i]
+			}
+		}
+	}
+	return share
+}
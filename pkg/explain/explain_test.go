@@ -0,0 +1,139 @@
+package explain
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/core/pkg/opencost"
+
+	"github.com/kubecost/kubectl-cost/pkg/pricing"
+)
+
+var start = time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+func container(cluster, node, pod, name string, cpu, ram float64) opencost.Allocation {
+	return opencost.Allocation{
+		Name: cluster + "/" + node + "/" + pod + "/" + name,
+		Properties: &opencost.AllocationProperties{
+			Cluster:   cluster,
+			Node:      node,
+			Pod:       pod,
+			Container: name,
+		},
+		Start:                  start,
+		End:                    start.Add(10 * time.Hour),
+		CPUCoreRequestAverage:  0.5,
+		CPUCoreUsageAverage:    0.25,
+		CPUCoreHours:           5,
+		CPUCost:                cpu,
+		RAMBytesRequestAverage: 2 * pricing.BytesPerGiB,
+		RAMBytesUsageAverage:   pricing.BytesPerGiB,
+		RAMByteHours:           20 * pricing.BytesPerGiB,
+		RAMCost:                ram,
+	}
+}
+
+func TestParseWorkload(t *testing.T) {
+	cases := map[string]Workload{
+		"deployment/api": {Kind: "deployment", Name: "api"},
+		"sts/db":         {Kind: "statefulset", Name: "db"},
+		"pod/api-123":    {Name: "api-123"},
+	}
+	for s, expected := range cases {
+		w, err := ParseWorkload(s)
+		if err != nil || w != expected {
+			t.Errorf("%s: expected %+v, got %+v (%v)", s, expected, w, err)
+		}
+	}
+	if w, _ := ParseWorkload("deploy/api"); w.String() != "deployment/api" {
+		t.Errorf("unexpected name %s", w)
+	}
+
+	for _, s := range []string{"api", "deployment/", "service/api"} {
+		if _, err := ParseWorkload(s); err == nil {
+			t.Errorf("expected an error for '%s'", s)
+		}
+	}
+
+	if p := (Workload{Name: "api-123"}).QueryParams("prod"); p["filterPods"] != "api-123" || p["filterNamespaces"] != "prod" {
+		t.Errorf("unexpected pod filters %v", p)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	web := container("one", "node-a", "api-1", "api", 1, 0.5)
+	web.PVs = opencost.PVAllocations{{Cluster: "one", Name: "pvc-1"}: {ByteHours: 10 * pricing.BytesPerGiB, Cost: 0.3}}
+	web.NetworkCost = 0.2
+	web.SharedCost = 0.4
+	allocations := map[string]opencost.Allocation{
+		web.Name: web,
+		"b":      container("one", "node-a", "api-1", "sidecar", 0.1, 0.1),
+		"c":      container("one", "node-b", "api-2", "api", 2, 1),
+	}
+
+	clusterAllocations := map[string]opencost.Allocation{
+		"one":          {Name: "one", Properties: &opencost.AllocationProperties{Cluster: "one"}, CPUCost: 31, RAMCost: 16.4},
+		"one/__idle__": {Name: "one/__idle__", Properties: &opencost.AllocationProperties{Cluster: "one"}, CPUCost: 10, RAMCost: 8.2},
+	}
+
+	e, err := Explain(Workload{Kind: "deployment", Name: "api"}, "prod", allocations, clusterAllocations)
+	if err != nil {
+		t.Fatalf("explaining: %s", err)
+	}
+
+	if len(e.Containers) != 3 || e.Containers[0].Container != "api" || e.Containers[0].Pod != "api-1" {
+		t.Errorf("unexpected containers %+v", e.Containers)
+	}
+	cpu := e.Containers[0].CPU
+	if cpu.RequestHours != 5 || cpu.UsageHours != 2.5 || cpu.Rate() != 0.2 {
+		t.Errorf("unexpected CPU %+v", cpu)
+	}
+	if ram := e.Containers[0].RAM; ram.RequestHours != 20 || ram.UsageHours != 10 || ram.Hours != 20 {
+		t.Errorf("unexpected RAM %+v", ram)
+	}
+
+	if len(e.Nodes) != 2 || e.Nodes[0].Name != "node-b" || e.Nodes[1].Pods != 1 || e.Nodes[1].CPU.Cost != 1.1 {
+		t.Errorf("unexpected nodes %+v", e.Nodes)
+	}
+	if len(e.Volumes) != 1 || e.Volumes[0].GiBHours != 10 || e.Volumes[0].Cost != 0.3 {
+		t.Errorf("unexpected volumes %+v", e.Volumes)
+	}
+
+	// The workload has 3.1 of 31 CPU cost and 1.6 of 16.4 RAM cost.
+	if math.Abs(e.IdleCost-(1+0.8)) > 1e-9 {
+		t.Errorf("expected an idle share of 1.8, got %f", e.IdleCost)
+	}
+	if math.Abs(e.TotalCost()-(3.1+1.6+0.3+0.2+0.4+1.8)) > 1e-9 {
+		t.Errorf("unexpected total %f", e.TotalCost())
+	}
+	if e.Minutes != 600 {
+		t.Errorf("expected 600 minutes, got %f", e.Minutes)
+	}
+}
+
+func TestExplainIdleByNode(t *testing.T) {
+	allocations := map[string]opencost.Allocation{
+		"a": container("one", "node-a", "api-1", "api", 1, 0),
+		"b": container("one", "node-b", "api-2", "api", 1, 0),
+	}
+	clusterAllocations := map[string]opencost.Allocation{
+		"one/node-a":          {Properties: &opencost.AllocationProperties{Cluster: "one", Node: "node-a"}, CPUCost: 2},
+		"one/node-a/__idle__": {Name: "one/node-a/__idle__", Properties: &opencost.AllocationProperties{Cluster: "one", Node: "node-a"}, CPUCost: 4},
+		"one/node-b":          {Properties: &opencost.AllocationProperties{Cluster: "one", Node: "node-b"}, CPUCost: 1},
+	}
+
+	e, err := Explain(Workload{Kind: "deployment", Name: "api"}, "prod", allocations, clusterAllocations)
+	if err != nil {
+		t.Fatalf("explaining: %s", err)
+	}
+	if e.IdleCost != 2 {
+		t.Errorf("expected half of node-a's idle cost, got %f", e.IdleCost)
+	}
+}
+
+func TestExplainNoData(t *testing.T) {
+	if _, err := Explain(Workload{Kind: "deployment", Name: "api"}, "prod", nil, nil); err == nil {
+		t.Errorf("expected an error without allocations")
+	}
+}
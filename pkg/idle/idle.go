@@ -0,0 +1,146 @@
+// Package idle reports and distributes the cost of capacity which no workload
+// is allocated.
+package idle
+
+import (
+	"sort"
+
+	"github.com/opencost/opencost/core/pkg/opencost"
+)
+
+// ShareEvenly distributes the CPU, GPU and RAM cost of each idle allocation
+// evenly among the other allocations of its cluster, and removes the idle
+// allocations. Idle allocations of clusters without other allocations are
+// kept.
+func ShareEvenly(allocations map[string]opencost.Allocation) map[string]opencost.Allocation {
+	byCluster := map[string][]string{}
+	var idle []string
+	for key, alloc := range allocations {
+		if alloc.IsIdle() {
+			idle = append(idle, key)
+			continue
+		}
+		byCluster[cluster(alloc)] = append(byCluster[cluster(alloc)], key)
+	}
+
+	shared := make(map[string]opencost.Allocation, len(allocations))
+	for key, alloc := range allocations {
+		if !alloc.IsIdle() {
+			shared[key] = alloc
+		}
+	}
+
+	sort.Strings(idle)
+	for _, key := range idle {
+		idleAlloc := allocations[key]
+		recipients := byCluster[cluster(idleAlloc)]
+		if len(recipients) == 0 {
+			shared[key] = idleAlloc
+			continue
+		}
+
+		n := float64(len(recipients))
+		for _, r := range recipients {
+			alloc := shared[r]
+			alloc.CPUCost += idleAlloc.CPUTotalCost() / n
+			alloc.GPUCost += idleAlloc.GPUTotalCost() / n
+			alloc.RAMCost += idleAlloc.RAMTotalCost() / n
+			shared[r] = alloc
+		}
+	}
+	return shared
+}
+
+func cluster(alloc opencost.Allocation) string {
+	if alloc.Properties == nil {
+		return ""
+	}
+	return alloc.Properties.Cluster
+}
+
+// Cost is the idle cost of a cluster, or of a node if Node is set, over a
+// window.
+type Cost struct {
+	Cluster string
+	Node    string
+
+	CPUCost float64
+	GPUCost float64
+	RAMCost float64
+
+	// AllocatedCost is the CPU, GPU and RAM cost of the cluster or node
+	// which allocations account for.
+	AllocatedCost float64
+
+	// Minutes is the duration of the idle allocation.
+	Minutes float64
+}
+
+// TotalCost is the sum of the idle CPU, GPU and RAM cost.
+func (c Cost) TotalCost() float64 {
+	return c.CPUCost + c.GPUCost + c.RAMCost
+}
+
+// Fraction is the share of the cluster or node's CPU, GPU and RAM cost which
+// is idle.
+func (c Cost) Fraction() float64 {
+	total := c.TotalCost() + c.AllocatedCost
+	if total <= 0 {
+		return 0
+	}
+	return c.TotalCost() / total
+}
+
+// Costs returns the idle costs in allocations which are aggregated by cluster,
+// or by cluster and node with idle by node, sorted by descending total cost.
+func Costs(allocations map[string]opencost.Allocation) []Cost {
+	byKey := map[string]*Cost{}
+	get := func(alloc opencost.Allocation) *Cost {
+		c := Cost{}
+		if alloc.Properties != nil {
+			c.Cluster = alloc.Properties.Cluster
+			c.Node = alloc.Properties.Node
+		}
+		key := c.Cluster + "/" + c.Node
+		if _, ok := byKey[key]; !ok {
+			byKey[key] = &c
+		}
+		return byKey[key]
+	}
+
+	for _, alloc := range allocations {
+		if !alloc.IsIdle() {
+			continue
+		}
+		c := get(alloc)
+		c.CPUCost += alloc.CPUTotalCost()
+		c.GPUCost += alloc.GPUTotalCost()
+		c.RAMCost += alloc.RAMTotalCost()
+		c.Minutes = alloc.Minutes()
+	}
+
+	// Allocated cost is only of interest where there is idle cost.
+	for _, alloc := range allocations {
+		if alloc.IsIdle() || alloc.Properties == nil {
+			continue
+		}
+		for _, key := range []string{alloc.Properties.Cluster + "/" + alloc.Properties.Node, alloc.Properties.Cluster + "/"} {
+			if c, ok := byKey[key]; ok {
+				c.AllocatedCost += alloc.CPUTotalCost() + alloc.GPUTotalCost() + alloc.RAMTotalCost()
+				break
+			}
+		}
+	}
+
+	var costs []Cost
+	for _, c := range byKey {
+		costs = append(costs, *c)
+	}
+	sort.Slice(costs, func(i, j int) bool {
+		if costs[i].TotalCost() != costs[j].TotalCost() {
+			return costs[i].TotalCost() > costs[j].TotalCost()
+		}
+		return costs[i].Cluster+"/"+costs[i].Node < costs[j].Cluster+"/"+costs[j].Node
+	})
+	return costs
+}
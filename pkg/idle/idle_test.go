@@ -0,0 +1,61 @@
+package idle
+
+import (
+	"math"
+	"testing"
+
+	"github.com/opencost/opencost/core/pkg/opencost"
+)
+
+func alloc(name, cluster, node string, cpu, ram float64) opencost.Allocation {
+	return opencost.Allocation{
+		Name:       name,
+		Properties: &opencost.AllocationProperties{Cluster: cluster, Node: node},
+		CPUCost:    cpu,
+		RAMCost:    ram,
+	}
+}
+
+func TestShareEvenly(t *testing.T) {
+	allocations := map[string]opencost.Allocation{
+		"one/__idle__": alloc("one/__idle__", "one", "", 2, 1),
+		"one/web":      alloc("one/web", "one", "", 1, 1),
+		"one/db":       alloc("one/db", "one", "", 3, 0),
+		"two/__idle__": alloc("two/__idle__", "two", "", 5, 0),
+	}
+
+	shared := ShareEvenly(allocations)
+	if _, ok := shared["one/__idle__"]; ok {
+		t.Errorf("expected idle of cluster one to be shared, got %+v", shared)
+	}
+	if _, ok := shared["two/__idle__"]; !ok {
+		t.Errorf("expected idle of cluster two to be kept, as it has nothing to share with")
+	}
+
+	web := shared["one/web"]
+	if web.CPUCost != 2 || web.RAMCost != 1.5 {
+		t.Errorf("expected web to get half of the idle cost, got CPU %f and RAM %f", web.CPUCost, web.RAMCost)
+	}
+	if allocations["one/web"].CPUCost != 1 {
+		t.Errorf("expected the input to be unchanged")
+	}
+}
+
+func TestCosts(t *testing.T) {
+	allocations := map[string]opencost.Allocation{
+		"one/node-a/__idle__": alloc("one/node-a/__idle__", "one", "node-a", 1, 1),
+		"one/node-b/__idle__": alloc("one/node-b/__idle__", "one", "node-b", 3, 1),
+		"one/node-a":          alloc("one/node-a", "one", "node-a", 4, 2),
+	}
+
+	costs := Costs(allocations)
+	if len(costs) != 2 || costs[0].Node != "node-b" {
+		t.Fatalf("expected node-b first, got %+v", costs)
+	}
+	if costs[0].TotalCost() != 4 || costs[0].Fraction() != 1 {
+		t.Errorf("expected node-b to be entirely idle, got %+v", costs[0])
+	}
+	if math.Abs(costs[1].Fraction()-0.25) > 1e-9 {
+		t.Errorf("expected a quarter of node-a to be idle, got %f", costs[1].Fraction())
+	}
+}
@@ -0,0 +1,110 @@
+package manifests
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Autoscaler is the part of a HorizontalPodAutoscaler which determines the
+// replica count of its target. Both autoscaling/v1 and autoscaling/v2
+// HorizontalPodAutoscalers are represented.
+type Autoscaler struct {
+	Namespace string
+	Name      string
+
+	TargetKind string
+	TargetName string
+
+	MinReplicas int32
+	MaxReplicas int32
+
+	// CurrentReplicas is the replica count last observed by the autoscaler,
+	// or nil if it isn't known, e.g. because it has not been deployed.
+	CurrentReplicas *int32
+}
+
+// AutoscalerFromObject reads obj if it is a HorizontalPodAutoscaler.
+func AutoscalerFromObject(obj *unstructured.Unstructured) (Autoscaler, bool, error) {
+	if obj.GetKind() != "HorizontalPodAutoscaler" {
+		return Autoscaler{}, false, nil
+	}
+
+	// The fields used here are identical in v1 and v2.
+	var hpa autoscalingv2.HorizontalPodAutoscaler
+	if err := fromUnstructured(obj, &hpa); err != nil {
+		return Autoscaler{}, false, fmt.Errorf("HorizontalPodAutoscaler %s/%s: %s", obj.GetNamespace(), obj.GetName(), err)
+	}
+	return autoscalerFromHPA(hpa, false), true, nil
+}
+
+// autoscalerFromHPA converts hpa. The current replica count is only taken
+// from the status of HPAs which were observed in the cluster.
+func autoscalerFromHPA(hpa autoscalingv2.HorizontalPodAutoscaler, live bool) Autoscaler {
+	a := Autoscaler{
+		Namespace:   hpa.Namespace,
+		Name:        hpa.Name,
+		TargetKind:  hpa.Spec.ScaleTargetRef.Kind,
+		TargetName:  hpa.Spec.ScaleTargetRef.Name,
+		MinReplicas: 1,
+		MaxReplicas: hpa.Spec.MaxReplicas,
+	}
+	if hpa.Spec.MinReplicas != nil {
+		a.MinReplicas = *hpa.Spec.MinReplicas
+	}
+	if live {
+		current := hpa.Status.CurrentReplicas
+		a.CurrentReplicas = &current
+	}
+	return a
+}
+
+// Targets returns true if the autoscaler scales the given workload.
+func (a Autoscaler) Targets(kind, namespace, name string) bool {
+	return a.Namespace == namespace && a.TargetName == name && strings.EqualFold(a.TargetKind, kind)
+}
+
+// FetchLiveAutoscalers lists the HorizontalPodAutoscalers in the given
+// namespaces of the cluster.
+func FetchLiveAutoscalers(ctx context.Context, clientset kubernetes.Interface, namespaces []string) ([]Autoscaler, error) {
+	var result []Autoscaler
+	for _, ns := range namespaces {
+		hpas, err := clientset.AutoscalingV2().HorizontalPodAutoscalers(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("listing HorizontalPodAutoscalers in namespace '%s': %s", ns, err)
+		}
+		for _, hpa := range hpas.Items {
+			result = append(result, autoscalerFromHPA(hpa, true))
+		}
+	}
+	return result, nil
+}
+
+// SpecReplicas returns the replica count set in a workload's spec, which is
+// what its prediction is based on. Kinds without replicas, like Pod, return
+// false.
+func SpecReplicas(obj *unstructured.Unstructured) (int32, bool) {
+	switch obj.GetKind() {
+	case "Deployment", "StatefulSet", "ReplicaSet", "Rollout":
+	default:
+		return 0, false
+	}
+
+	// Decoded YAML and JSON represent numbers as float64 rather than int64.
+	r, found, _ := unstructured.NestedFieldNoCopy(obj.Object, "spec", "replicas")
+	if !found {
+		return 1, true
+	}
+	switch r := r.(type) {
+	case int64:
+		return int32(r), true
+	case float64:
+		return int32(r), true
+	}
+	return 0, false
+}
@@ -0,0 +1,43 @@
+package manifests
+
+import (
+	"testing"
+)
+
+func TestAutoscalerFromObject(t *testing.T) {
+	objs, err := Decode("test", []byte(`apiVersion: autoscaling/v1
+kind: HorizontalPodAutoscaler
+metadata:
+  name: web
+  namespace: prod
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: web
+  maxReplicas: 10
+  targetCPUUtilizationPercentage: 80
+---
+`+deployment("web")))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	a, ok, err := AutoscalerFromObject(objs[0])
+	if err != nil || !ok {
+		t.Fatalf("expected an autoscaler, got ok=%t, err=%v", ok, err)
+	}
+	if a.MinReplicas != 1 || a.MaxReplicas != 10 || a.CurrentReplicas != nil {
+		t.Errorf("unexpected replicas: min %d, max %d, current %v", a.MinReplicas, a.MaxReplicas, a.CurrentReplicas)
+	}
+	if !a.Targets("deployment", "prod", "web") || a.Targets("StatefulSet", "prod", "web") {
+		t.Errorf("unexpected targets for %s %s", a.TargetKind, a.TargetName)
+	}
+
+	if _, ok, _ := AutoscalerFromObject(objs[1]); ok {
+		t.Errorf("expected a Deployment not to be an autoscaler")
+	}
+	if replicas, ok := SpecReplicas(objs[1]); !ok || replicas != 2 {
+		t.Errorf("expected 2 replicas, got %d (%t)", replicas, ok)
+	}
+}
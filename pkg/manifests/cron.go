@@ -0,0 +1,37 @@
+package manifests
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// cronRunsPerMonth returns the average number of times a CronJob schedule
+// fires per month, where a month is a twelfth of a (non-leap) year. Counting
+// over a whole year gives a sensible average for schedules like "@monthly" or
+// "0 0 * * 1" which don't divide evenly into months.
+//
+// The schedule is parsed as the CronJob controller parses it, including
+// macros like "@daily" and a "CRON_TZ=" or "TZ=" prefix. Schedules without a
+// time zone are evaluated in UTC.
+func cronRunsPerMonth(schedule string) (float64, error) {
+	s, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return 0, fmt.Errorf("schedule '%s': %s", schedule, err)
+	}
+	if spec, ok := s.(*cron.SpecSchedule); ok && spec.Location == time.Local {
+		spec.Location = time.UTC
+	}
+
+	start := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+
+	// Next returns the zero time if the schedule never fires, e.g. on
+	// February 30th.
+	runs := 0
+	for t := s.Next(start.Add(-time.Nanosecond)); !t.IsZero() && t.Before(end); t = s.Next(t) {
+		runs++
+	}
+	return float64(runs) / 12, nil
+}
@@ -0,0 +1,112 @@
+package manifests
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// Decode parses a stream of YAML documents, or a JSON document, into objects.
+// Lists (e.g. v1/List) are flattened into their items. source is used to give
+// context in error messages, e.g. a file name.
+//
+// Every document is validated and all invalid documents are reported in the
+// returned error, so that a user can fix all of their mistakes at once.
+func Decode(source string, data []byte) ([]*unstructured.Unstructured, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+
+	var objs []*unstructured.Unstructured
+	var errs []error
+	for doc := 1; ; doc++ {
+		b, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			errs = append(errs, fmt.Errorf("%s: document %d: %s", source, doc, err))
+			break
+		}
+
+		docObjs, err := decodeDocument(b)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: document %d: %s", source, doc, err))
+			continue
+		}
+		objs = append(objs, docObjs...)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return objs, nil
+}
+
+func decodeDocument(b []byte) ([]*unstructured.Unstructured, error) {
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+
+	// Empty documents, e.g. from a leading "---" or a Helm template which
+	// rendered nothing, are skipped.
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	obj := &unstructured.Unstructured{Object: raw}
+	if !obj.IsList() {
+		if err := validate(obj); err != nil {
+			return nil, err
+		}
+		return []*unstructured.Unstructured{obj}, nil
+	}
+
+	list, err := obj.ToList()
+	if err != nil {
+		return nil, fmt.Errorf("reading list: %s", err)
+	}
+
+	var objs []*unstructured.Unstructured
+	var errs []error
+	for i := range list.Items {
+		if err := validate(&list.Items[i]); err != nil {
+			errs = append(errs, fmt.Errorf("item %d: %s", i, err))
+			continue
+		}
+		objs = append(objs, &list.Items[i])
+	}
+	return objs, errors.Join(errs...)
+}
+
+// validate checks that obj is a well-formed object. Objects of kinds known to
+// client-go are also checked against their schema, e.g. that a Deployment's
+// replicas is a number.
+func validate(obj *unstructured.Unstructured) error {
+	if obj.GetAPIVersion() == "" {
+		return fmt.Errorf("missing 'apiVersion'")
+	}
+	if obj.GetKind() == "" {
+		return fmt.Errorf("missing 'kind'")
+	}
+	if obj.GetName() == "" {
+		return fmt.Errorf("%s is missing 'metadata.name'", obj.GetKind())
+	}
+
+	typed, err := scheme.Scheme.New(obj.GroupVersionKind())
+	if runtime.IsNotRegisteredError(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("%s %s: %s", obj.GetKind(), obj.GetName(), err)
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructuredWithValidation(obj.Object, typed, true); err != nil {
+		return fmt.Errorf("%s %s is invalid: %s", obj.GetKind(), obj.GetName(), err)
+	}
+	return nil
+}
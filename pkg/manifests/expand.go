@@ -0,0 +1,361 @@
+package manifests
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opencost/opencost/core/pkg/util/timeutil"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+)
+
+// expandableKinds are the kinds which the speccost API doesn't support, but
+// which Expand can convert into an equivalent Deployment.
+var expandableKinds = map[string]bool{
+	"DaemonSet":  true,
+	"Job":        true,
+	"CronJob":    true,
+	"ReplicaSet": true,
+	"Rollout":    true,
+}
+
+// daemonSetTolerations are the tolerations which the DaemonSet controller adds
+// to every DaemonSet pod, so that they run on nodes with these conditions.
+var daemonSetTolerations = []corev1.Toleration{
+	{Key: corev1.TaintNodeNotReady, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	{Key: corev1.TaintNodeUnreachable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	{Key: corev1.TaintNodeDiskPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodeMemoryPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodePIDPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodeUnschedulable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+}
+
+// Expansion records how a workload of a kind which the speccost API doesn't
+// support was converted into an equivalent Deployment in the same namespace.
+type Expansion struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+
+	// EquivalentName is the name of the equivalent Deployment, as returned by
+	// EquivalentName.
+	EquivalentName string
+
+	// Factor scales the prediction for the equivalent Deployment to the cost
+	// of the original workload, e.g. the fraction of a month that a Job's
+	// pods run for.
+	Factor float64
+
+	// Assumptions describe the conversion so that users can judge the
+	// prediction.
+	Assumptions []string
+}
+
+// ExpandOptions supply the information which is needed to convert workloads
+// but isn't part of their definition.
+type ExpandOptions struct {
+	// ListNodes returns the nodes of the cluster which the workloads will be
+	// deployed to. It is only called if there is a DaemonSet to expand.
+	ListNodes func() ([]corev1.Node, error)
+
+	// JobDuration is the assumed run time of each pod of a Job or CronJob.
+	JobDuration time.Duration
+}
+
+// Expand converts objects of kinds which the speccost API doesn't support
+// into equivalent Deployments. Other objects are returned unchanged. The
+// returned expansions describe each conversion.
+func Expand(objs []*unstructured.Unstructured, opts ExpandOptions) ([]*unstructured.Unstructured, []Expansion, error) {
+	var nodes []corev1.Node
+	nodesListed := false
+	listNodes := func() ([]corev1.Node, error) {
+		if nodesListed {
+			return nodes, nil
+		}
+		if opts.ListNodes == nil {
+			return nil, fmt.Errorf("the cluster's nodes are required to predict a DaemonSet")
+		}
+		var err error
+		nodes, err = opts.ListNodes()
+		if err != nil {
+			return nil, fmt.Errorf("listing nodes: %s", err)
+		}
+		nodesListed = true
+		return nodes, nil
+	}
+
+	var result []*unstructured.Unstructured
+	var expansions []Expansion
+
+	for _, obj := range objs {
+		if !expandableKinds[obj.GetKind()] {
+			result = append(result, obj)
+			continue
+		}
+
+		var d *appsv1.Deployment
+		var exp *Expansion
+		var err error
+
+		switch obj.GetKind() {
+		case "DaemonSet":
+			d, exp, err = expandDaemonSet(obj, listNodes)
+		case "Job":
+			d, exp, err = expandJob(obj, opts.JobDuration)
+		case "CronJob":
+			d, exp, err = expandCronJob(obj, opts.JobDuration)
+		case "ReplicaSet":
+			d, exp, err = expandReplicaSet(obj)
+		case "Rollout":
+			d, exp, err = expandRollout(obj)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s %s/%s: %s", obj.GetKind(), obj.GetNamespace(), obj.GetName(), err)
+		}
+
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(d)
+		if err != nil {
+			return nil, nil, fmt.Errorf("converting %s %s/%s to a Deployment: %s", obj.GetKind(), obj.GetNamespace(), obj.GetName(), err)
+		}
+		equivalent := &unstructured.Unstructured{Object: u}
+		stripServerFields(equivalent)
+
+		exp.APIVersion = obj.GetAPIVersion()
+		exp.Kind = obj.GetKind()
+		exp.Namespace = obj.GetNamespace()
+		exp.Name = obj.GetName()
+		exp.EquivalentName = d.Name
+
+		result = append(result, equivalent)
+		expansions = append(expansions, *exp)
+	}
+
+	return result, expansions, nil
+}
+
+// EquivalentName is the name of the Deployment which a workload of the given
+// kind and name is converted to. It includes the kind, so that predictions
+// for it can't be confused with those for a Deployment of the same name,
+// which may be predicted alongside it or exist in the cluster.
+func EquivalentName(kind, name string) string {
+	return fmt.Sprintf("kubectl-cost-%s-%s", strings.ToLower(kind), name)
+}
+
+// equivalentDeployment builds a Deployment which runs replicas copies of
+// template, in the same namespace as obj and named by EquivalentName.
+func equivalentDeployment(obj *unstructured.Unstructured, template corev1.PodTemplateSpec, replicas int32) *appsv1.Deployment {
+	// Pods of a Deployment must always be restarted.
+	template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      EquivalentName(obj.GetKind(), obj.GetName()),
+			Namespace: obj.GetNamespace(),
+			Labels:    obj.GetLabels(),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: template.Labels},
+			Template: template,
+		},
+	}
+}
+
+func fromUnstructured(obj *unstructured.Unstructured, into interface{}) error {
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, into); err != nil {
+		return fmt.Errorf("decoding: %s", err)
+	}
+	return nil
+}
+
+func expandDaemonSet(obj *unstructured.Unstructured, listNodes func() ([]corev1.Node, error)) (*appsv1.Deployment, *Expansion, error) {
+	var ds appsv1.DaemonSet
+	if err := fromUnstructured(obj, &ds); err != nil {
+		return nil, nil, err
+	}
+
+	nodes, err := listNodes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	count, err := daemonSetNodeCount(ds.Spec.Template.Spec, nodes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return equivalentDeployment(obj, ds.Spec.Template, int32(count)), &Expansion{
+		Factor: 1,
+		Assumptions: []string{
+			fmt.Sprintf("1 pod on each of the %d of %d current nodes which match its node selector, affinity and tolerations", count, len(nodes)),
+		},
+	}, nil
+}
+
+// daemonSetNodeCount returns the number of nodes which a DaemonSet with the
+// given pod spec would run a pod on.
+func daemonSetNodeCount(spec corev1.PodSpec, nodes []corev1.Node) (int, error) {
+	tolerations := append(append([]corev1.Toleration{}, spec.Tolerations...), daemonSetTolerations...)
+	if spec.HostNetwork {
+		tolerations = append(tolerations, corev1.Toleration{
+			Key:      corev1.TaintNodeNetworkUnavailable,
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		})
+	}
+
+	affinity := nodeaffinity.GetRequiredNodeAffinity(&corev1.Pod{Spec: spec})
+	count := 0
+	for i := range nodes {
+		match, err := affinity.Match(&nodes[i])
+		if err != nil {
+			return 0, fmt.Errorf("matching node affinity: %s", err)
+		}
+		if !match {
+			continue
+		}
+
+		_, untolerated := corev1helpers.FindMatchingUntoleratedTaint(nodes[i].Spec.Taints, tolerations, func(t *corev1.Taint) bool {
+			return t.Effect == corev1.TaintEffectNoSchedule || t.Effect == corev1.TaintEffectNoExecute
+		})
+		if !untolerated {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// jobPods returns the number of pods that run to complete a Job and how many
+// of them run at the same time.
+func jobPods(spec batchv1.JobSpec) (total, concurrent int32) {
+	parallelism := int32(1)
+	if spec.Parallelism != nil {
+		parallelism = *spec.Parallelism
+	}
+
+	// Without completions, a Job is a work queue which finishes once any of
+	// its parallel pods succeeds, so every pod runs once.
+	total = parallelism
+	if spec.Completions != nil {
+		total = *spec.Completions
+	}
+
+	concurrent = parallelism
+	if total < concurrent {
+		concurrent = total
+	}
+	return total, concurrent
+}
+
+func expandJob(obj *unstructured.Unstructured, duration time.Duration) (*appsv1.Deployment, *Expansion, error) {
+	var job batchv1.Job
+	if err := fromUnstructured(obj, &job); err != nil {
+		return nil, nil, err
+	}
+
+	exp := &Expansion{}
+	if job.Spec.Suspend != nil && *job.Spec.Suspend {
+		exp.Assumptions = append(exp.Assumptions, "suspended, so it runs no pods")
+		return equivalentDeployment(obj, job.Spec.Template, 1), exp, nil
+	}
+
+	total, concurrent := jobPods(job.Spec)
+	exp.Factor = float64(total) * duration.Hours() / timeutil.HoursPerMonth
+	exp.Assumptions = append(exp.Assumptions,
+		fmt.Sprintf("runs once this month, as %d pods (%d at a time) which each run for %s", total, concurrent, duration),
+	)
+	return equivalentDeployment(obj, job.Spec.Template, 1), exp, nil
+}
+
+func expandCronJob(obj *unstructured.Unstructured, duration time.Duration) (*appsv1.Deployment, *Expansion, error) {
+	var cj batchv1.CronJob
+	if err := fromUnstructured(obj, &cj); err != nil {
+		return nil, nil, err
+	}
+
+	template := cj.Spec.JobTemplate.Spec.Template
+	exp := &Expansion{}
+	if cj.Spec.Suspend != nil && *cj.Spec.Suspend {
+		exp.Assumptions = append(exp.Assumptions, "suspended, so it runs no pods")
+		return equivalentDeployment(obj, template, 1), exp, nil
+	}
+
+	runs, err := cronRunsPerMonth(cj.Spec.Schedule)
+	if err != nil {
+		return nil, nil, err
+	}
+	total, concurrent := jobPods(cj.Spec.JobTemplate.Spec)
+
+	exp.Factor = runs * float64(total) * duration.Hours() / timeutil.HoursPerMonth
+	exp.Assumptions = append(exp.Assumptions,
+		fmt.Sprintf("runs %.1f times per month (schedule '%s'), each as %d pods (%d at a time) which each run for %s", runs, cj.Spec.Schedule, total, concurrent, duration),
+	)
+
+	// Unless runs are allowed to overlap, at most one Job runs at a time.
+	if cj.Spec.ConcurrencyPolicy != batchv1.AllowConcurrent && cj.Spec.ConcurrencyPolicy != "" && exp.Factor > float64(concurrent) {
+		exp.Factor = float64(concurrent)
+		exp.Assumptions = append(exp.Assumptions,
+			fmt.Sprintf("runs would overlap, but the %s concurrency policy limits it to %d pods at all times", cj.Spec.ConcurrencyPolicy, concurrent),
+		)
+	}
+
+	return equivalentDeployment(obj, template, 1), exp, nil
+}
+
+func expandReplicaSet(obj *unstructured.Unstructured) (*appsv1.Deployment, *Expansion, error) {
+	var rs appsv1.ReplicaSet
+	if err := fromUnstructured(obj, &rs); err != nil {
+		return nil, nil, err
+	}
+
+	replicas := int32(1)
+	if rs.Spec.Replicas != nil {
+		replicas = *rs.Spec.Replicas
+	}
+	return equivalentDeployment(obj, rs.Spec.Template, replicas), &Expansion{
+		Factor:      1,
+		Assumptions: []string{fmt.Sprintf("predicted as a Deployment with %d replicas", replicas)},
+	}, nil
+}
+
+// expandRollout converts an Argo Rollout. Rollouts are a CRD, so they are read
+// from the unstructured object rather than a typed one.
+func expandRollout(obj *unstructured.Unstructured) (*appsv1.Deployment, *Expansion, error) {
+	if _, found, _ := unstructured.NestedMap(obj.Object, "spec", "workloadRef"); found {
+		return nil, nil, fmt.Errorf("Rollouts which reference a workload (spec.workloadRef) are not supported, predict the referenced workload instead")
+	}
+
+	templateObj, found, err := unstructured.NestedMap(obj.Object, "spec", "template")
+	if err != nil || !found {
+		return nil, nil, fmt.Errorf("Rollout has no spec.template")
+	}
+	var template corev1.PodTemplateSpec
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(templateObj, &template); err != nil {
+		return nil, nil, fmt.Errorf("decoding spec.template: %s", err)
+	}
+
+	replicas, ok := SpecReplicas(obj)
+	if !ok {
+		return nil, nil, fmt.Errorf("spec.replicas is not a number")
+	}
+
+	return equivalentDeployment(obj, template, replicas), &Expansion{
+		Factor: 1,
+		Assumptions: []string{
+			fmt.Sprintf("steady state of %d replicas, excluding extra pods which run during a canary or blue-green update", replicas),
+		},
+	}, nil
+}
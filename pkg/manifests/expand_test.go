@@ -0,0 +1,184 @@
+package manifests
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCronRunsPerMonth(t *testing.T) {
+	cases := []struct {
+		schedule string
+		expected float64
+	}{
+		{"*/15 * * * *", 4 * 24 * 365 / 12.0},
+		{"@hourly", 24 * 365 / 12.0},
+		{"0 2 * * *", 365 / 12.0},
+		{"CRON_TZ=UTC 0 2 * * *", 365 / 12.0},
+		{"@monthly", 1},
+		{"0 0 1 1 *", 1 / 12.0},
+		// 2023 has 53 Sundays and 52 of every other day.
+		{"0 9 * * mon-fri", 5 * 52 / 12.0},
+		{"0 0 * * sun", 53 / 12.0},
+		{"0 0 30 2 *", 0},
+		// Restricting both day fields matches either of them. May 1 is the
+		// only Monday which is also the first of a month.
+		{"0 0 1 * 1", (12 + 52 - 1) / 12.0},
+	}
+
+	for _, c := range cases {
+		got, err := cronRunsPerMonth(c.schedule)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", c.schedule, err)
+			continue
+		}
+		if math.Abs(got-c.expected) > 1e-9 {
+			t.Errorf("%s: expected %f runs per month, got %f", c.schedule, c.expected, got)
+		}
+	}
+
+	for _, invalid := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "0 0 * foo *"} {
+		if _, err := cronRunsPerMonth(invalid); err == nil {
+			t.Errorf("%s: expected an error", invalid)
+		}
+	}
+}
+
+func TestDaemonSetNodeCount(t *testing.T) {
+	node := func(name string, labels map[string]string, taints ...corev1.Taint) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Spec:       corev1.NodeSpec{Taints: taints},
+		}
+	}
+	gpuTaint := corev1.Taint{Key: "gpu", Value: "true", Effect: corev1.TaintEffectNoSchedule}
+	nodes := []corev1.Node{
+		node("a", map[string]string{"pool": "default"}),
+		node("b", map[string]string{"pool": "default"},
+			corev1.Taint{Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule}),
+		node("c", map[string]string{"pool": "gpu"}, gpuTaint),
+	}
+
+	cases := []struct {
+		name     string
+		spec     corev1.PodSpec
+		expected int
+	}{
+		{"no constraints", corev1.PodSpec{}, 2},
+		{"node selector", corev1.PodSpec{NodeSelector: map[string]string{"pool": "gpu"}}, 0},
+		{
+			"toleration",
+			corev1.PodSpec{Tolerations: []corev1.Toleration{{Key: "gpu", Operator: corev1.TolerationOpExists}}},
+			3,
+		},
+		{
+			"affinity",
+			corev1.PodSpec{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+						MatchExpressions: []corev1.NodeSelectorRequirement{{
+							Key: "pool", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"gpu"},
+						}},
+					}},
+				},
+			}}},
+			2,
+		},
+	}
+
+	for _, c := range cases {
+		got, err := daemonSetNodeCount(c.spec, nodes)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+		} else if got != c.expected {
+			t.Errorf("%s: expected %d nodes, got %d", c.name, c.expected, got)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	objs, err := Decode("test", []byte(deployment("web")+`---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: report
+  namespace: default
+spec:
+  schedule: "0 2 * * *"
+  jobTemplate:
+    spec:
+      completions: 4
+      parallelism: 2
+      template:
+        spec:
+          restartPolicy: Never
+          containers:
+          - name: report
+---
+apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: api
+  namespace: default
+spec:
+  replicas: 3
+  template:
+    metadata:
+      labels:
+        app: api
+    spec:
+      containers:
+      - name: api
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	result, expansions, err := Expand(objs, ExpandOptions{JobDuration: 30 * time.Minute})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(result) != 3 || len(expansions) != 2 {
+		t.Fatalf("expected 3 objects and 2 expansions, got %d and %d", len(result), len(expansions))
+	}
+	for _, obj := range result {
+		if obj.GetKind() != "Deployment" {
+			t.Errorf("%s: expected a Deployment, got %s", obj.GetName(), obj.GetKind())
+		}
+	}
+
+	// Converted workloads are named by their kind, so that they can't
+	// collide with Deployments.
+	for i, expected := range []string{"web", "kubectl-cost-cronjob-report", "kubectl-cost-rollout-api"} {
+		if result[i].GetName() != expected {
+			t.Errorf("expected object %d to be named %s, got %s", i, expected, result[i].GetName())
+		}
+	}
+
+	cron := expansions[0]
+	if cron.APIVersion != "batch/v1" || cron.Kind != "CronJob" || cron.Name != "report" || cron.EquivalentName != "kubectl-cost-cronjob-report" {
+		t.Errorf("unexpected expansion %+v", cron)
+	}
+	// 365/12 runs per month of 4 pods for half an hour each.
+	if expected := 365 / 12.0 * 4 * 0.5 / 730; math.Abs(cron.Factor-expected) > 1e-9 {
+		t.Errorf("expected factor %f, got %f", expected, cron.Factor)
+	}
+
+	replicas, _, _ := unstructured.NestedInt64(result[2].Object, "spec", "replicas")
+	if expansions[1].Factor != 1 || replicas != 3 {
+		t.Errorf("expected the Rollout as 3 replicas, got %d with factor %f", replicas, expansions[1].Factor)
+	}
+
+	ds, err := Decode("test", []byte("apiVersion: apps/v1\nkind: DaemonSet\nmetadata:\n  name: agent\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, _, err := Expand(ds, ExpandOptions{}); err == nil {
+		t.Errorf("expected an error expanding a DaemonSet without nodes")
+	}
+}
@@ -0,0 +1,345 @@
+package manifests
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// manifestExtensions are the file extensions which are read when a directory
+// is given as input, matching kubectl.
+var manifestExtensions = map[string]bool{
+	".json": true,
+	".yaml": true,
+	".yml":  true,
+}
+
+// FileOptions describes a set of manifest files with the same semantics as
+// the -f and -R flags of "kubectl apply".
+type FileOptions struct {
+	// Filenames are files, directories or glob patterns. "-" means Stdin.
+	Filenames []string
+
+	// Recursive enables reading directories recursively. Otherwise, only
+	// files directly inside a directory are read.
+	Recursive bool
+
+	Stdin io.Reader
+}
+
+// Read reads all objects defined in the files. Every file is read before an
+// error is returned, and the error describes every invalid document.
+func (o FileOptions) Read() ([]*unstructured.Unstructured, error) {
+	return o.read(osSource{})
+}
+
+// ReadAtRevision is like Read, but reads the files as they exist at the given
+// git revision (e.g. "origin/main") of the repository containing the current
+// directory. Files and directories which don't exist at that revision are
+// treated as empty.
+func (o FileOptions) ReadAtRevision(revision string) ([]*unstructured.Unstructured, error) {
+	return o.read(gitSource{revision: revision})
+}
+
+func (o FileOptions) read(src fileSource) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	var errs []error
+
+	for _, name := range o.Filenames {
+		if name == "-" {
+			if _, ok := src.(osSource); !ok {
+				errs = append(errs, fmt.Errorf("stdin cannot be read at a git revision"))
+				continue
+			}
+			b, err := io.ReadAll(o.Stdin)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("reading from stdin: %s", err))
+				continue
+			}
+			stdinObjs, err := Decode("stdin", b)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			objs = append(objs, stdinObjs...)
+			continue
+		}
+
+		files, err := expand(src, name, o.Recursive)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, file := range files {
+			b, err := src.readFile(file)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("reading '%s': %s", file, err))
+				continue
+			}
+
+			fileObjs, err := Decode(file, b)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			objs = append(objs, fileObjs...)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return objs, nil
+}
+
+// expand resolves a filename argument into the list of files it refers to.
+// Like kubectl, a name is only treated as a glob pattern if no file of that
+// name exists.
+func expand(src fileSource, name string, recursive bool) ([]string, error) {
+	isDir, err := src.stat(name)
+	if errors.Is(err, fs.ErrNotExist) && strings.ContainsAny(name, "*?[") {
+		matches, err := src.glob(name)
+		if err != nil {
+			return nil, fmt.Errorf("expanding pattern '%s': %s", name, err)
+		}
+		if len(matches) == 0 && src.mustExist() {
+			return nil, fmt.Errorf("no files match the pattern '%s'", name)
+		}
+
+		var files []string
+		for _, match := range matches {
+			matchFiles, err := expand(src, match, recursive)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, matchFiles...)
+		}
+		return files, nil
+	} else if errors.Is(err, fs.ErrNotExist) && !src.mustExist() {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if !isDir {
+		return []string{name}, nil
+	}
+
+	all, err := src.list(name, recursive)
+	if err != nil {
+		return nil, fmt.Errorf("listing directory '%s': %s", name, err)
+	}
+
+	var files []string
+	for _, file := range all {
+		if manifestExtensions[filepath.Ext(file)] {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// fileSource abstracts where manifest files are read from so that the working
+// tree and a git revision can be treated identically.
+type fileSource interface {
+	// stat reports whether path is a directory. It returns an error wrapping
+	// fs.ErrNotExist if path does not exist.
+	stat(path string) (isDir bool, err error)
+
+	// list returns the files inside dir, descending into subdirectories if
+	// recursive is true.
+	list(dir string, recursive bool) ([]string, error)
+
+	// glob returns the paths matching pattern, as filepath.Glob.
+	glob(pattern string) ([]string, error)
+
+	readFile(path string) ([]byte, error)
+
+	// mustExist is true if referring to a path which doesn't exist is an
+	// error rather than an empty input.
+	mustExist() bool
+}
+
+// osSource reads files from the local file system.
+type osSource struct{}
+
+func (osSource) stat(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	return info.IsDir(), nil
+}
+
+func (osSource) list(dir string, recursive bool) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	return files, err
+}
+
+func (osSource) glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+func (osSource) readFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+func (osSource) mustExist() bool {
+	return true
+}
+
+// gitSource reads files as they exist at a revision of the git repository
+// containing the current directory. Paths are relative to the current
+// directory, as they would be for osSource.
+type gitSource struct {
+	revision string
+}
+
+// object returns the "<rev>:./<path>" syntax which git resolves relative to
+// the current directory.
+func (g gitSource) object(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		path, err = filepath.Rel(wd, path)
+		if err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%s:./%s", g.revision, filepath.ToSlash(filepath.Clean(path))), nil
+}
+
+func (g gitSource) stat(path string) (bool, error) {
+	obj, err := g.object(path)
+	if err != nil {
+		return false, err
+	}
+
+	out, err := git("cat-file", "-t", obj)
+	if err != nil {
+		// cat-file fails for paths which don't exist at the revision, but
+		// also for invalid revisions. Only the former is "not found".
+		if _, revErr := git("rev-parse", "--verify", "--quiet", g.revision+"^{commit}"); revErr != nil {
+			return false, fmt.Errorf("'%s' is not a valid git revision", g.revision)
+		}
+		return false, fmt.Errorf("'%s' at revision '%s': %w", path, g.revision, fs.ErrNotExist)
+	}
+	return strings.TrimSpace(out) == "tree", nil
+}
+
+func (g gitSource) list(dir string, recursive bool) ([]string, error) {
+	obj, err := g.object(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"ls-tree", "-z"}
+	if recursive {
+		args = append(args, "-r")
+	}
+	out, err := git(append(args, obj)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range strings.Split(out, "\x00") {
+		// Each entry is "<mode> <type> <object>\t<name>"
+		meta, name, ok := strings.Cut(entry, "\t")
+		if !ok || !strings.Contains(meta, " blob ") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, filepath.FromSlash(name)))
+	}
+	return files, nil
+}
+
+func (g gitSource) glob(pattern string) ([]string, error) {
+	// Patterns can't match across directories, so only the part of the tree
+	// below the pattern's first non-literal directory has to be searched.
+	root := pattern
+	for strings.ContainsAny(root, "*?[") {
+		root = filepath.Dir(root)
+	}
+
+	if _, err := g.stat(root); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	files, err := g.list(root, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	for _, file := range files {
+		// Directories can match too, e.g. "k8s/*" matches "k8s/apps".
+		for path := file; path != root && path != "."; path = filepath.Dir(path) {
+			if ok, err := filepath.Match(pattern, path); err != nil {
+				return nil, err
+			} else if ok {
+				if len(matches) == 0 || matches[len(matches)-1] != path {
+					matches = append(matches, path)
+				}
+				break
+			}
+		}
+	}
+	return matches, nil
+}
+
+func (g gitSource) readFile(path string) ([]byte, error) {
+	obj, err := g.object(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// cat-file rather than show, so that textconv and filters configured
+	// for the repository can't change the contents.
+	out, err := git("cat-file", "blob", obj)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
+
+func (gitSource) mustExist() bool {
+	return false
+}
+
+func git(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("running 'git %s': %s: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,337 @@
+package manifests
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const testDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: %s
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: app
+        resources:
+          requests:
+            cpu: 100m
+`
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func deployment(name string) string {
+	return strings.Replace(testDeployment, "%s", name, 1)
+}
+
+func names(t *testing.T, o FileOptions) []string {
+	t.Helper()
+	objs, err := o.Read()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var result []string
+	for _, obj := range objs {
+		result = append(result, obj.GetName())
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestDecode_MultiDocumentAndList(t *testing.T) {
+	input := "---\n" + deployment("a") + "---\n# only a comment\n---\n" + `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: b
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: c
+  spec:
+    containers:
+    - name: app
+`
+
+	objs, err := Decode("test.yaml", []byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var got []string
+	for _, obj := range objs {
+		got = append(got, obj.GetKind()+"/"+obj.GetName())
+	}
+	want := "Deployment/a,ConfigMap/b,Pod/c"
+	if strings.Join(got, ",") != want {
+		t.Errorf("expected %s, got %s", want, strings.Join(got, ","))
+	}
+}
+
+func TestDecode_JSONList(t *testing.T) {
+	input := `{"apiVersion": "v1", "kind": "List", "items": [
+		{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "a"}, "spec": {"containers": [{"name": "app"}]}}
+	]}`
+
+	objs, err := Decode("test.json", []byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(objs) != 1 || objs[0].GetName() != "a" {
+		t.Errorf("expected a single Pod 'a', got %v", objs)
+	}
+}
+
+func TestDecode_ReportsEveryInvalidDocument(t *testing.T) {
+	input := deployment("ok") + "---\n" +
+		"kind: Deployment\nmetadata:\n  name: no-api-version\n" + "---\n" +
+		strings.Replace(deployment("bad-replicas"), "replicas: 2", "replicas: two", 1) + "---\n" +
+		"apiVersion: v1\nkind: Pod\nmetadata: [\n"
+
+	_, err := Decode("test.yaml", []byte(input))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	for _, want := range []string{
+		"test.yaml: document 2: missing 'apiVersion'",
+		"test.yaml: document 3: Deployment bad-replicas is invalid",
+		"test.yaml: document 4:",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got:\n%s", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "document 1") {
+		t.Errorf("expected document 1 to be valid, got:\n%s", err)
+	}
+}
+
+func TestFileOptions_Read(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yaml"), deployment("a"))
+	writeFile(t, filepath.Join(dir, "b.yml"), deployment("b"))
+	writeFile(t, filepath.Join(dir, "README.md"), "not a manifest")
+	writeFile(t, filepath.Join(dir, "nested", "c.yaml"), deployment("c"))
+	writeFile(t, filepath.Join(dir, "nested", "deeper", "d.json"), `{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "d"}}`)
+	other := t.TempDir()
+	writeFile(t, filepath.Join(other, "e.yaml"), deployment("e"))
+
+	cases := map[string]struct {
+		opts FileOptions
+		want string
+	}{
+		"single file": {
+			opts: FileOptions{Filenames: []string{filepath.Join(dir, "a.yaml")}},
+			want: "a",
+		},
+		"directory": {
+			opts: FileOptions{Filenames: []string{dir}},
+			want: "a,b",
+		},
+		"recursive directory": {
+			opts: FileOptions{Filenames: []string{dir}, Recursive: true},
+			want: "a,b,c,d",
+		},
+		"repeated": {
+			opts: FileOptions{Filenames: []string{filepath.Join(dir, "a.yaml"), other}},
+			want: "a,e",
+		},
+		"glob": {
+			opts: FileOptions{Filenames: []string{filepath.Join(dir, "*.y*ml")}},
+			want: "a,b",
+		},
+		"glob matching directories": {
+			opts: FileOptions{Filenames: []string{filepath.Join(dir, "nest*")}, Recursive: true},
+			want: "c,d",
+		},
+		"stdin": {
+			opts: FileOptions{Filenames: []string{"-"}, Stdin: strings.NewReader(deployment("s"))},
+			want: "s",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := strings.Join(names(t, c.opts), ",")
+			if got != c.want {
+				t.Errorf("expected %s, got %s", c.want, got)
+			}
+		})
+	}
+}
+
+func TestFileOptions_Read_Missing(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{filepath.Join(dir, "missing.yaml"), filepath.Join(dir, "*.yaml")} {
+		_, err := FileOptions{Filenames: []string{name}}.Read()
+		if err == nil {
+			t.Errorf("expected an error reading %s", name)
+		}
+	}
+}
+
+// gitRepo creates a git repository with a commit tagged "base" and a later
+// commit, and changes into it for the duration of the test.
+func gitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %s: %s", strings.Join(args, " "), err, out)
+		}
+	}
+
+	run("init", "-q")
+	writeFile(t, filepath.Join(dir, "k8s", "a.yaml"), deployment("a"))
+	writeFile(t, filepath.Join(dir, "k8s", "b.yml"), deployment("b"))
+	writeFile(t, filepath.Join(dir, "k8s", "README.md"), "not a manifest")
+	writeFile(t, filepath.Join(dir, "k8s", "nested", "c.yaml"), deployment("c"))
+	run("add", "-A")
+	run("commit", "-q", "-m", "base")
+	run("tag", "base")
+
+	// Head removes b and adds d, and the working tree differs from both.
+	run("rm", "-q", filepath.Join("k8s", "b.yml"))
+	writeFile(t, filepath.Join(dir, "k8s", "d.yaml"), deployment("d"))
+	run("add", "-A")
+	run("commit", "-q", "-m", "head")
+	writeFile(t, filepath.Join(dir, "k8s", "e.yaml"), deployment("e"))
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return dir
+}
+
+func TestFileOptions_ReadAtRevision(t *testing.T) {
+	dir := gitRepo(t)
+
+	cases := map[string]struct {
+		opts     FileOptions
+		revision string
+		want     string
+	}{
+		"directory": {
+			opts:     FileOptions{Filenames: []string{"k8s"}},
+			revision: "base",
+			want:     "a,b",
+		},
+		"recursive directory": {
+			opts:     FileOptions{Filenames: []string{"k8s"}, Recursive: true},
+			revision: "base",
+			want:     "a,b,c",
+		},
+		"directory at head": {
+			opts:     FileOptions{Filenames: []string{"k8s"}},
+			revision: "HEAD",
+			want:     "a,d",
+		},
+		"absolute path": {
+			opts:     FileOptions{Filenames: []string{filepath.Join(dir, "k8s", "b.yml")}},
+			revision: "base",
+			want:     "b",
+		},
+		"glob": {
+			opts:     FileOptions{Filenames: []string{filepath.Join("k8s", "*.y*ml")}},
+			revision: "base",
+			want:     "a,b",
+		},
+		"glob matching directories": {
+			opts:     FileOptions{Filenames: []string{filepath.Join("k8s", "nest*")}, Recursive: true},
+			revision: "base",
+			want:     "c",
+		},
+		"file missing at revision": {
+			opts:     FileOptions{Filenames: []string{filepath.Join("k8s", "d.yaml")}},
+			revision: "base",
+			want:     "",
+		},
+		"file only in the working tree": {
+			opts:     FileOptions{Filenames: []string{filepath.Join("k8s", "e.yaml")}},
+			revision: "HEAD",
+			want:     "",
+		},
+		"glob in directory missing at revision": {
+			opts:     FileOptions{Filenames: []string{filepath.Join("missing", "*.yaml")}},
+			revision: "base",
+			want:     "",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			objs, err := c.opts.ReadAtRevision(c.revision)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			var got []string
+			for _, obj := range objs {
+				got = append(got, obj.GetName())
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != c.want {
+				t.Errorf("expected %s, got %s", c.want, strings.Join(got, ","))
+			}
+		})
+	}
+}
+
+func TestFileOptions_ReadAtRevision_Errors(t *testing.T) {
+	gitRepo(t)
+
+	cases := map[string]struct {
+		opts     FileOptions
+		revision string
+		err      string
+	}{
+		"bad revision": {
+			opts:     FileOptions{Filenames: []string{"k8s"}},
+			revision: "no-such-branch",
+			err:      "'no-such-branch' is not a valid git revision",
+		},
+		"stdin": {
+			opts:     FileOptions{Filenames: []string{"-"}, Stdin: strings.NewReader(deployment("s"))},
+			revision: "base",
+			err:      "stdin cannot be read at a git revision",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := c.opts.ReadAtRevision(c.revision)
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("expected error containing %q, got %v", c.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package manifests
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WorkloadRef identifies a single live workload in the cluster, e.g. the
+// "deployment/api" in "kubectl cost predict deployment/api".
+type WorkloadRef struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// ParseWorkloadRef parses a TYPE/NAME argument in the style of kubectl. Kind
+// aliases like "deploy" and "sts" are normalized to their canonical kind.
+func ParseWorkloadRef(s string, namespace string) (WorkloadRef, error) {
+	split := strings.Split(s, "/")
+	if len(split) != 2 || split[0] == "" || split[1] == "" {
+		return WorkloadRef{}, fmt.Errorf("'%s' is not of the form TYPE/NAME, e.g. 'deployment/api'", s)
+	}
+
+	kind, err := normalizeKind(split[0])
+	if err != nil {
+		return WorkloadRef{}, err
+	}
+
+	return WorkloadRef{
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      split[1],
+	}, nil
+}
+
+func (r WorkloadRef) String() string {
+	return fmt.Sprintf("%s/%s/%s", r.Namespace, strings.ToLower(r.Kind), r.Name)
+}
+
+func normalizeKind(s string) (string, error) {
+	switch strings.ToLower(s) {
+	case "deployment", "deployments", "deploy":
+		return "Deployment", nil
+	case "statefulset", "statefulsets", "sts":
+		return "StatefulSet", nil
+	case "pod", "pods", "po":
+		return "Pod", nil
+	}
+	return "", fmt.Errorf("unsupported workload type '%s', must be one of: deployment, statefulset, pod", s)
+}
+
+// Edits are proposed changes to a workload which are applied in memory before
+// the workload is submitted for prediction.
+type Edits struct {
+	// Replicas, if non-nil, replaces the replica count of the workload.
+	Replicas *int32
+
+	// Requests are merged into the resource requests of the containers
+	// selected by Container.
+	Requests corev1.ResourceList
+
+	// Container selects the container that Requests apply to. If empty,
+	// Requests apply to every container in the pod template.
+	Container string
+}
+
+// IsEmpty returns true if applying the edits would be a no-op.
+func (e Edits) IsEmpty() bool {
+	return e.Replicas == nil && len(e.Requests) == 0
+}
+
+// EditableResources are the resources whose requests Edits can set.
+var EditableResources = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, "nvidia.com/gpu"}
+
+// ParseRequests parses resource requests given as name=quantity, e.g.
+// cpu=500m. Names must be one of EditableResources.
+func ParseRequests(requests map[string]string) (corev1.ResourceList, error) {
+	list := corev1.ResourceList{}
+	for name, value := range requests {
+		if !slices.Contains(EditableResources, corev1.ResourceName(name)) {
+			return nil, fmt.Errorf("unsupported resource '%s', must be one of: cpu, memory, nvidia.com/gpu", name)
+		}
+		qty, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s=%s: %s", name, value, err)
+		}
+		list[corev1.ResourceName(name)] = qty
+	}
+	return list, nil
+}
+
+// FetchLiveWorkload retrieves the referenced workload from the cluster,
+// applies the given edits to it, and returns it as an object which is ready
+// to be serialized and submitted for prediction.
+func FetchLiveWorkload(ctx context.Context, clientset kubernetes.Interface, ref WorkloadRef, edits Edits) (*unstructured.Unstructured, error) {
+	var obj runtime.Object
+
+	switch ref.Kind {
+	case "Deployment":
+		d, err := clientset.AppsV1().Deployments(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("getting deployment: %s", err)
+		}
+		d.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind(ref.Kind))
+		d.Status = appsv1.DeploymentStatus{}
+		obj = d
+	case "StatefulSet":
+		s, err := clientset.AppsV1().StatefulSets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("getting statefulset: %s", err)
+		}
+		s.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind(ref.Kind))
+		s.Status = appsv1.StatefulSetStatus{}
+		obj = s
+	case "Pod":
+		p, err := clientset.CoreV1().Pods(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("getting pod: %s", err)
+		}
+		p.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind(ref.Kind))
+		p.Status = corev1.PodStatus{}
+		obj = p
+	default:
+		return nil, fmt.Errorf("unsupported workload kind '%s'", ref.Kind)
+	}
+
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, fmt.Errorf("converting %s to unstructured: %s", ref, err)
+	}
+	result := &unstructured.Unstructured{Object: u}
+	stripServerFields(result)
+
+	if err := ApplyEdits(result, edits); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ApplyEdits applies edits to a Deployment, StatefulSet or Pod.
+func ApplyEdits(obj *unstructured.Unstructured, edits Edits) error {
+	var podSpecPath []string
+	switch obj.GetKind() {
+	case "Deployment", "StatefulSet":
+		podSpecPath = []string{"spec", "template", "spec"}
+	case "Pod":
+		podSpecPath = []string{"spec"}
+	default:
+		return fmt.Errorf("unsupported workload kind '%s'", obj.GetKind())
+	}
+
+	if edits.Replicas != nil {
+		if obj.GetKind() == "Pod" {
+			return fmt.Errorf("cannot set replicas on a Pod")
+		}
+		if err := unstructured.SetNestedField(obj.Object, int64(*edits.Replicas), "spec", "replicas"); err != nil {
+			return fmt.Errorf("setting replicas: %s", err)
+		}
+	}
+
+	return setRequests(obj, append(podSpecPath, "containers"), edits.Requests, edits.Container)
+}
+
+// setRequests merges requests into the resource requests of the named
+// container, or all containers if containerName is empty.
+func setRequests(obj *unstructured.Unstructured, containersPath []string, requests corev1.ResourceList, containerName string) error {
+	if len(requests) == 0 {
+		return nil
+	}
+
+	containers, _, err := unstructured.NestedSlice(obj.Object, containersPath...)
+	if err != nil {
+		return fmt.Errorf("reading containers: %s", err)
+	}
+
+	found := false
+	for i, c := range containers {
+		container, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if containerName != "" && container["name"] != containerName {
+			continue
+		}
+		found = true
+
+		for name, qty := range requests {
+			if err := unstructured.SetNestedField(container, qty.String(), "resources", "requests", string(name)); err != nil {
+				return fmt.Errorf("setting %s request of container '%s': %s", name, container["name"], err)
+			}
+		}
+		containers[i] = container
+	}
+
+	if !found {
+		if containerName == "" {
+			return fmt.Errorf("pod template has no containers")
+		}
+		return fmt.Errorf("no container named '%s' in pod template", containerName)
+	}
+	return unstructured.SetNestedSlice(obj.Object, containers, containersPath...)
+}
+
+// expandableResources are the API resources of the kinds which Expand
+// converts.
+var expandableResources = map[string]string{
+	"DaemonSet":  "daemonsets",
+	"Job":        "jobs",
+	"CronJob":    "cronjobs",
+	"ReplicaSet": "replicasets",
+	"Rollout":    "rollouts",
+}
+
+// FetchLiveExpanded retrieves the live version of a workload which Expand
+// converted, or nil if there is none in the cluster.
+func FetchLiveExpanded(ctx context.Context, client dynamic.Interface, exp Expansion) (*unstructured.Unstructured, error) {
+	resource, ok := expandableResources[exp.Kind]
+	if !ok {
+		return nil, fmt.Errorf("unsupported workload kind '%s'", exp.Kind)
+	}
+	gv, err := schema.ParseGroupVersion(exp.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("parsing apiVersion '%s': %s", exp.APIVersion, err)
+	}
+
+	// A kind whose CRD isn't installed, like Rollout, isn't found either.
+	obj, err := client.Resource(gv.WithResource(resource)).Namespace(exp.Namespace).Get(ctx, exp.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting %s %s/%s: %s", strings.ToLower(exp.Kind), exp.Namespace, exp.Name, err)
+	}
+	stripServerFields(obj)
+	return obj, nil
+}
+
+// stripServerFields removes fields which are set by the API server and are
+// irrelevant (or noisy) for prediction.
+func stripServerFields(u *unstructured.Unstructured) {
+	u.SetManagedFields(nil)
+	u.SetResourceVersion("")
+	u.SetUID("")
+	u.SetGeneration(0)
+	u.SetSelfLink("")
+	unstructured.RemoveNestedField(u.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(u.Object, "status")
+}
@@ -0,0 +1,315 @@
+package manifests
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseRequests(t *testing.T) {
+	cases := []struct {
+		name     string
+		requests map[string]string
+		expected corev1.ResourceList
+		err      string
+	}{
+		{
+			name:     "cpu, memory and gpu",
+			requests: map[string]string{"cpu": "500m", "memory": "1Gi", "nvidia.com/gpu": "1"},
+			expected: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+				"nvidia.com/gpu":      resource.MustParse("1"),
+			},
+		},
+		{
+			name:     "misspelled resource",
+			requests: map[string]string{"cpus": "1"},
+			err:      "unsupported resource 'cpus'",
+		},
+		{
+			name:     "invalid quantity",
+			requests: map[string]string{"memory": "lots"},
+			err:      "memory=lots",
+		},
+	}
+
+	for _, c := range cases {
+		got, err := ParseRequests(c.requests)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s: expected error containing %q, got %v", c.name, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+			continue
+		}
+		if len(got) != len(c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, got)
+		}
+		for name, qty := range c.expected {
+			gotQty := got[name]
+			if gotQty.Cmp(qty) != 0 {
+				t.Errorf("%s: expected %s=%s, got %s", c.name, name, qty.String(), gotQty.String())
+			}
+		}
+	}
+}
+
+func workloadFixture(kind string, containers ...map[string]interface{}) *unstructured.Unstructured {
+	podSpec := map[string]interface{}{}
+	var cs []interface{}
+	for _, c := range containers {
+		cs = append(cs, c)
+	}
+	podSpec["containers"] = cs
+
+	obj := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": "api", "namespace": "prod"},
+	}
+	if kind == "Pod" {
+		obj["apiVersion"] = "v1"
+		obj["spec"] = podSpec
+	} else {
+		obj["spec"] = map[string]interface{}{
+			"replicas": int64(2),
+			"template": map[string]interface{}{"spec": podSpec},
+		}
+	}
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func containerFixture(name string, resources map[string]interface{}) map[string]interface{} {
+	c := map[string]interface{}{"name": name, "image": name + ":latest"}
+	if resources != nil {
+		c["resources"] = resources
+	}
+	return c
+}
+
+func TestApplyEdits(t *testing.T) {
+	replicas := int32(5)
+
+	cases := []struct {
+		name  string
+		obj   *unstructured.Unstructured
+		edits Edits
+
+		// containersPath is where the containers of obj are.
+		containersPath []string
+		replicas       int64
+		requests       map[string]map[string]interface{}
+		err            string
+	}{
+		{
+			name:           "no edits",
+			obj:            workloadFixture("Deployment", containerFixture("app", nil)),
+			containersPath: []string{"spec", "template", "spec", "containers"},
+			replicas:       2,
+			requests:       map[string]map[string]interface{}{"app": nil},
+		},
+		{
+			name:           "replicas",
+			obj:            workloadFixture("StatefulSet", containerFixture("app", nil)),
+			edits:          Edits{Replicas: &replicas},
+			containersPath: []string{"spec", "template", "spec", "containers"},
+			replicas:       5,
+			requests:       map[string]map[string]interface{}{"app": nil},
+		},
+		{
+			name: "requests of all containers, keeping other requests",
+			obj: workloadFixture("Deployment",
+				containerFixture("app", map[string]interface{}{
+					"requests": map[string]interface{}{"cpu": "100m", "memory": "128Mi"},
+					"limits":   map[string]interface{}{"memory": "256Mi"},
+				}),
+				containerFixture("sidecar", nil),
+			),
+			edits:          Edits{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}},
+			containersPath: []string{"spec", "template", "spec", "containers"},
+			replicas:       2,
+			requests: map[string]map[string]interface{}{
+				"app":     {"cpu": "500m", "memory": "128Mi"},
+				"sidecar": {"cpu": "500m"},
+			},
+		},
+		{
+			name: "requests of one container",
+			obj: workloadFixture("Pod",
+				containerFixture("app", nil),
+				containerFixture("sidecar", nil),
+			),
+			edits: Edits{
+				Requests:  corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+				Container: "sidecar",
+			},
+			containersPath: []string{"spec", "containers"},
+			requests: map[string]map[string]interface{}{
+				"app":     nil,
+				"sidecar": {"memory": "1Gi"},
+			},
+		},
+		{
+			name: "missing container",
+			obj:  workloadFixture("Deployment", containerFixture("app", nil)),
+			edits: Edits{
+				Requests:  corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				Container: "sidecar",
+			},
+			err: "no container named 'sidecar'",
+		},
+		{
+			name:  "replicas of a pod",
+			obj:   workloadFixture("Pod", containerFixture("app", nil)),
+			edits: Edits{Replicas: &replicas},
+			err:   "cannot set replicas on a Pod",
+		},
+		{
+			name: "unsupported kind",
+			obj:  workloadFixture("DaemonSet", containerFixture("app", nil)),
+			err:  "unsupported workload kind 'DaemonSet'",
+		},
+	}
+
+	for _, c := range cases {
+		err := ApplyEdits(c.obj, c.edits)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s: expected error containing %q, got %v", c.name, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+			continue
+		}
+
+		if c.obj.GetKind() != "Pod" {
+			got, _, _ := unstructured.NestedInt64(c.obj.Object, "spec", "replicas")
+			if got != c.replicas {
+				t.Errorf("%s: expected %d replicas, got %d", c.name, c.replicas, got)
+			}
+		}
+
+		containers, _, _ := unstructured.NestedSlice(c.obj.Object, c.containersPath...)
+		for _, container := range containers {
+			name := container.(map[string]interface{})["name"].(string)
+			got, _, _ := unstructured.NestedMap(container.(map[string]interface{}), "resources", "requests")
+			if len(got) == 0 && len(c.requests[name]) == 0 {
+				continue
+			}
+			if !reflect.DeepEqual(got, c.requests[name]) {
+				t.Errorf("%s: expected requests %v of container '%s', got %v", c.name, c.requests[name], name, got)
+			}
+		}
+	}
+}
+
+func TestApplyEditsKeepsLimits(t *testing.T) {
+	obj := workloadFixture("Deployment", containerFixture("app", map[string]interface{}{
+		"limits": map[string]interface{}{"memory": "256Mi"},
+	}))
+	err := ApplyEdits(obj, Edits{Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+	limit, _, _ := unstructured.NestedString(containers[0].(map[string]interface{}), "resources", "limits", "memory")
+	if limit != "256Mi" {
+		t.Errorf("expected the memory limit to be kept, got %q", limit)
+	}
+}
+
+func TestFetchLiveWorkload(t *testing.T) {
+	replicas := int32(3)
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "prod", ResourceVersion: "42"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+			},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: 3},
+	})
+
+	newReplicas := int32(6)
+	ref := WorkloadRef{Kind: "Deployment", Namespace: "prod", Name: "api"}
+	obj, err := FetchLiveWorkload(context.Background(), clientset, ref, Edits{
+		Replicas: &newReplicas,
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if obj.GetKind() != "Deployment" || obj.GetAPIVersion() != "apps/v1" {
+		t.Errorf("expected an apps/v1 Deployment, got %s %s", obj.GetAPIVersion(), obj.GetKind())
+	}
+	if obj.GetResourceVersion() != "" {
+		t.Errorf("expected the resource version to be stripped, got %q", obj.GetResourceVersion())
+	}
+	if _, found := obj.Object["status"]; found {
+		t.Errorf("expected the status to be stripped")
+	}
+
+	spec, n, err := PodSpec(obj)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if n != 6 {
+		t.Errorf("expected 6 replicas, got %d", n)
+	}
+	if cpu := spec.Containers[0].Resources.Requests[corev1.ResourceCPU]; cpu.String() != "250m" {
+		t.Errorf("expected a CPU request of 250m, got %s", cpu.String())
+	}
+
+	if _, err := FetchLiveWorkload(context.Background(), clientset, WorkloadRef{Kind: "Deployment", Namespace: "prod", Name: "web"}, Edits{}); err == nil {
+		t.Errorf("expected an error getting a missing deployment")
+	}
+}
+
+func TestFetchLiveExpanded(t *testing.T) {
+	ds := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "DaemonSet",
+		"metadata":   map[string]interface{}{"name": "agent", "namespace": "monitoring", "resourceVersion": "7"},
+		"status":     map[string]interface{}{"numberReady": int64(3)},
+	}}
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), ds)
+
+	obj, err := FetchLiveExpanded(context.Background(), client, Expansion{APIVersion: "apps/v1", Kind: "DaemonSet", Namespace: "monitoring", Name: "agent"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if obj == nil || obj.GetName() != "agent" {
+		t.Fatalf("expected the live DaemonSet, got %v", obj)
+	}
+	if _, found := obj.Object["status"]; found || obj.GetResourceVersion() != "" {
+		t.Errorf("expected server fields to be stripped, got %v", obj.Object)
+	}
+
+	obj, err = FetchLiveExpanded(context.Background(), client, Expansion{APIVersion: "apps/v1", Kind: "DaemonSet", Namespace: "monitoring", Name: "other"})
+	if err != nil || obj != nil {
+		t.Errorf("expected nothing for a missing DaemonSet, got %v, %v", obj, err)
+	}
+
+	if _, err := FetchLiveExpanded(context.Background(), client, Expansion{APIVersion: "apps/v1", Kind: "Deployment", Name: "agent"}); err == nil {
+		t.Errorf("expected an error fetching a kind which isn't expanded")
+	}
+}
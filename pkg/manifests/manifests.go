@@ -0,0 +1,110 @@
+// Package manifests handles Kubernetes object definitions on the client side,
+// turning user input into workload specs which can be submitted to the
+// Kubecost prediction APIs.
+package manifests
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// predictableKinds are the kinds which the speccost API is able to predict.
+var predictableKinds = map[string]bool{
+	"Deployment":  true,
+	"StatefulSet": true,
+	"Pod":         true,
+}
+
+// Encode serializes objects as a multi-document YAML stream, the format
+// accepted by the speccost API.
+func Encode(objs []*unstructured.Unstructured) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, obj := range objs {
+		b, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling %s %s: %s", obj.GetKind(), obj.GetName(), err)
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(b)
+	}
+	return buf.Bytes(), nil
+}
+
+// FilterPredictable splits objs into those which can be predicted, either
+// directly or after Expand, and a count of the skipped objects by kind.
+func FilterPredictable(objs []*unstructured.Unstructured) ([]*unstructured.Unstructured, map[string]int) {
+	var kept []*unstructured.Unstructured
+	skipped := map[string]int{}
+
+	for _, obj := range objs {
+		if predictableKinds[obj.GetKind()] || expandableKinds[obj.GetKind()] {
+			kept = append(kept, obj)
+		} else {
+			skipped[obj.GetKind()]++
+		}
+	}
+
+	return kept, skipped
+}
+
+// SkippedSummary formats the result of FilterPredictable for display, e.g.
+// "ConfigMap (2), Service (1)".
+func SkippedSummary(skipped map[string]int) string {
+	kinds := make([]string, 0, len(skipped))
+	for kind := range skipped {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	parts := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		parts = append(parts, fmt.Sprintf("%s (%d)", kind, skipped[kind]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SetDefaultNamespace sets the namespace of each object which doesn't have one.
+func SetDefaultNamespace(objs []*unstructured.Unstructured, namespace string) {
+	for _, obj := range objs {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(namespace)
+		}
+	}
+}
+
+// PodSpec returns the pod spec of a predictable workload, and the number of
+// pods it runs.
+func PodSpec(obj *unstructured.Unstructured) (corev1.PodSpec, int32, error) {
+	switch obj.GetKind() {
+	case "Deployment":
+		var d appsv1.Deployment
+		if err := fromUnstructured(obj, &d); err != nil {
+			return corev1.PodSpec{}, 0, err
+		}
+		replicas, _ := SpecReplicas(obj)
+		return d.Spec.Template.Spec, replicas, nil
+	case "StatefulSet":
+		var s appsv1.StatefulSet
+		if err := fromUnstructured(obj, &s); err != nil {
+			return corev1.PodSpec{}, 0, err
+		}
+		replicas, _ := SpecReplicas(obj)
+		return s.Spec.Template.Spec, replicas, nil
+	case "Pod":
+		var p corev1.Pod
+		if err := fromUnstructured(obj, &p); err != nil {
+			return corev1.PodSpec{}, 0, err
+		}
+		return p.Spec, 1, nil
+	}
+	return corev1.PodSpec{}, 0, fmt.Errorf("unsupported workload kind '%s'", obj.GetKind())
+}
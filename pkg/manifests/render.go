@@ -0,0 +1,68 @@
+package manifests
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// RenderKustomize builds the kustomization in dir, equivalent to running
+// "kubectl kustomize dir".
+func RenderKustomize(dir string) ([]*unstructured.Unstructured, error) {
+	opts := krusty.MakeDefaultOptions()
+	opts.Reorder = krusty.ReorderOptionLegacy
+
+	resMap, err := krusty.MakeKustomizer(opts).Run(filesys.MakeFsOnDisk(), dir)
+	if err != nil {
+		return nil, fmt.Errorf("building kustomization '%s': %s", dir, err)
+	}
+
+	b, err := resMap.AsYaml()
+	if err != nil {
+		return nil, fmt.Errorf("serializing kustomization '%s': %s", dir, err)
+	}
+
+	return Decode(dir, b)
+}
+
+// HelmOptions configures local rendering of a Helm chart.
+type HelmOptions struct {
+	// Chart is a path to a chart directory or archive, or a chart reference
+	// which the local Helm installation can resolve.
+	Chart       string
+	ReleaseName string
+	Namespace   string
+	ValuesFiles []string
+}
+
+// RenderHelm renders a chart locally by running "helm template". It requires
+// the helm binary to be on the PATH.
+func RenderHelm(ctx context.Context, o HelmOptions) ([]*unstructured.Unstructured, error) {
+	args := []string{"template"}
+	if o.ReleaseName != "" {
+		args = append(args, o.ReleaseName)
+	}
+	args = append(args, o.Chart)
+	if o.Namespace != "" {
+		args = append(args, "--namespace", o.Namespace)
+	}
+	for _, v := range o.ValuesFiles {
+		args = append(args, "--values", v)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "helm", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("running 'helm %s': %s: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return Decode(o.Chart, stdout.Bytes())
+}
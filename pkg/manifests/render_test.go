@@ -0,0 +1,122 @@
+package manifests
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderKustomize(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "base", "kustomization.yaml"), `resources:
+- deployment.yaml
+- service.yaml
+configMapGenerator:
+- name: config
+  literals:
+  - LEVEL=info
+`)
+	writeFile(t, filepath.Join(dir, "base", "deployment.yaml"), deployment("api"))
+	writeFile(t, filepath.Join(dir, "base", "service.yaml"), `apiVersion: v1
+kind: Service
+metadata:
+  name: api
+spec:
+  ports:
+  - port: 80
+`)
+	writeFile(t, filepath.Join(dir, "overlay", "kustomization.yaml"), `namespace: prod
+namePrefix: prod-
+resources:
+- ../base
+replicas:
+- name: api
+  count: 5
+`)
+
+	objs, err := RenderKustomize(filepath.Join(dir, "overlay"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	workloads, skipped := FilterPredictable(objs)
+	if len(workloads) != 1 {
+		t.Fatalf("expected 1 workload, got %d", len(workloads))
+	}
+	w := workloads[0]
+	if w.GetKind() != "Deployment" || w.GetName() != "prod-api" || w.GetNamespace() != "prod" {
+		t.Errorf("expected Deployment prod/prod-api, got %s %s/%s", w.GetKind(), w.GetNamespace(), w.GetName())
+	}
+	_, replicas, err := PodSpec(w)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if replicas != 5 {
+		t.Errorf("expected the overlay's 5 replicas, got %d", replicas)
+	}
+
+	if got := SkippedSummary(skipped); got != "ConfigMap (1), Service (1)" {
+		t.Errorf("unexpected skipped kinds %q", got)
+	}
+
+	if _, err := RenderKustomize(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("expected an error building a missing kustomization")
+	}
+}
+
+func TestRenderHelm(t *testing.T) {
+	if _, err := exec.LookPath("helm"); err != nil {
+		t.Skip("helm is not installed")
+	}
+
+	chart := filepath.Join(t.TempDir(), "api")
+	writeFile(t, filepath.Join(chart, "Chart.yaml"), "apiVersion: v2\nname: api\nversion: 0.1.0\n")
+	writeFile(t, filepath.Join(chart, "values.yaml"), "replicas: 2\n")
+	writeFile(t, filepath.Join(chart, "templates", "deployment.yaml"), `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}
+  namespace: {{ .Release.Namespace }}
+spec:
+  replicas: {{ .Values.replicas }}
+  template:
+    spec:
+      containers:
+      - name: app
+`)
+	writeFile(t, filepath.Join(chart, "templates", "configmap.yaml"), `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}
+`)
+	values := filepath.Join(t.TempDir(), "values.yaml")
+	writeFile(t, values, "replicas: 3\n")
+
+	objs, err := RenderHelm(context.Background(), HelmOptions{
+		Chart:       chart,
+		ReleaseName: "web",
+		Namespace:   "prod",
+		ValuesFiles: []string{values},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	workloads, skipped := FilterPredictable(objs)
+	if len(workloads) != 1 || workloads[0].GetName() != "web" || workloads[0].GetNamespace() != "prod" {
+		t.Fatalf("expected Deployment prod/web, got %v", workloads)
+	}
+	if _, replicas, _ := PodSpec(workloads[0]); replicas != 3 {
+		t.Errorf("expected 3 replicas from the values file, got %d", replicas)
+	}
+	if got := SkippedSummary(skipped); got != "ConfigMap (1)" {
+		t.Errorf("unexpected skipped kinds %q", got)
+	}
+
+	_, err = RenderHelm(context.Background(), HelmOptions{Chart: filepath.Join(chart, "missing")})
+	if err == nil || !strings.Contains(err.Error(), "helm template") {
+		t.Errorf("expected an error running helm on a missing chart, got %v", err)
+	}
+}
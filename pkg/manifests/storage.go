@@ -0,0 +1,126 @@
+package manifests
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+)
+
+// StorageClaim is persistent storage requested by an object, either a
+// standalone PersistentVolumeClaim or a volumeClaimTemplate of a StatefulSet.
+type StorageClaim struct {
+	// Kind, Namespace and Name identify the object which makes the claim.
+	Kind      string
+	Namespace string
+	Name      string
+
+	// StorageClass is empty if the claim uses the cluster's default class.
+	StorageClass string
+
+	// Bytes is the total requested storage, across all replicas.
+	Bytes int64
+}
+
+// StorageClaims returns the persistent storage claimed by objs. Objects
+// which don't claim storage are ignored.
+func StorageClaims(objs []*unstructured.Unstructured) ([]StorageClaim, error) {
+	var claims []StorageClaim
+	for _, obj := range objs {
+		switch obj.GetKind() {
+		case "PersistentVolumeClaim":
+			var pvc corev1.PersistentVolumeClaim
+			if err := fromUnstructured(obj, &pvc); err != nil {
+				return nil, fmt.Errorf("PersistentVolumeClaim %s/%s: %s", obj.GetNamespace(), obj.GetName(), err)
+			}
+			claims = append(claims, pvcClaim(obj.GetKind(), obj.GetNamespace(), obj.GetName(), pvc.Spec, 1))
+		case "StatefulSet":
+			var sts appsv1.StatefulSet
+			if err := fromUnstructured(obj, &sts); err != nil {
+				return nil, fmt.Errorf("StatefulSet %s/%s: %s", obj.GetNamespace(), obj.GetName(), err)
+			}
+			claims = append(claims, statefulSetClaims(&sts)...)
+		}
+	}
+	return claims, nil
+}
+
+func statefulSetClaims(sts *appsv1.StatefulSet) []StorageClaim {
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+
+	var claims []StorageClaim
+	for _, t := range sts.Spec.VolumeClaimTemplates {
+		claims = append(claims, pvcClaim("StatefulSet", sts.Namespace, sts.Name, t.Spec, replicas))
+	}
+	return claims
+}
+
+func pvcClaim(kind, namespace, name string, spec corev1.PersistentVolumeClaimSpec, count int32) StorageClaim {
+	c := StorageClaim{
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+	}
+	if spec.StorageClassName != nil {
+		c.StorageClass = *spec.StorageClassName
+	}
+	if qty, ok := spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		c.Bytes = qty.Value() * int64(count)
+	}
+	return c
+}
+
+// FetchLiveStorageClaims returns the storage currently claimed by the object
+// of the given kind, namespace and name in the cluster, or nothing if it
+// doesn't exist. Kinds other than PersistentVolumeClaim and StatefulSet claim
+// nothing.
+func FetchLiveStorageClaims(ctx context.Context, clientset kubernetes.Interface, kind, namespace, name string) ([]StorageClaim, error) {
+	switch kind {
+	case "PersistentVolumeClaim":
+		pvc, err := clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("getting persistentvolumeclaim: %s", err)
+		}
+		return []StorageClaim{pvcClaim(kind, namespace, name, pvc.Spec, 1)}, nil
+	case "StatefulSet":
+		sts, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("getting statefulset: %s", err)
+		}
+		return statefulSetClaims(sts), nil
+	}
+	return nil, nil
+}
+
+// DefaultStorageClass returns the name of the cluster's default StorageClass,
+// or an empty string if there is none.
+func DefaultStorageClass(ctx context.Context, clientset kubernetes.Interface) (string, error) {
+	classes, err := clientset.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("listing storage classes: %s", err)
+	}
+	for _, c := range classes.Items {
+		if isDefaultStorageClass(c) {
+			return c.Name, nil
+		}
+	}
+	return "", nil
+}
+
+func isDefaultStorageClass(c storagev1.StorageClass) bool {
+	return c.Annotations["storageclass.kubernetes.io/is-default-class"] == "true" ||
+		c.Annotations["storageclass.beta.kubernetes.io/is-default-class"] == "true"
+}
@@ -0,0 +1,59 @@
+package manifests
+
+import (
+	"testing"
+)
+
+func TestStorageClaims(t *testing.T) {
+	objs, err := Decode("test", []byte(deployment("web")+`---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data
+spec:
+  storageClassName: gp3
+  accessModes: [ReadWriteOnce]
+  resources:
+    requests:
+      storage: 10Gi
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: db
+  volumeClaimTemplates:
+  - metadata:
+      name: data
+    spec:
+      resources:
+        requests:
+          storage: 500Gi
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	claims, err := StorageClaims(objs)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []StorageClaim{
+		{Kind: "PersistentVolumeClaim", Name: "data", StorageClass: "gp3", Bytes: 10 << 30},
+		{Kind: "StatefulSet", Name: "db", Bytes: 3 * 500 << 30},
+	}
+	if len(claims) != len(expected) {
+		t.Fatalf("expected %d claims, got %d: %+v", len(expected), len(claims), claims)
+	}
+	for i := range expected {
+		if claims[i] != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], claims[i])
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Package period resolves calendar periods, such as this month or a quarter,
+// to query windows, and projects costs over a window to a month or to the end
+// of a period.
+package period
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opencost/opencost/core/pkg/util/timeutil"
+)
+
+// Formats lists what Parse accepts, for flag help and errors.
+const Formats = "this-month, last-month, quarter, last-quarter or a month given as YYYY-MM"
+
+// Period is a calendar month or quarter, in UTC.
+type Period struct {
+	Start time.Time
+	End   time.Time
+
+	quarter bool
+}
+
+// Parse parses a period relative to now: this-month, last-month, quarter,
+// last-quarter, or a month given as YYYY-MM.
+func Parse(s string, now time.Time) (Period, error) {
+	now = now.UTC()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	thisQuarter := time.Date(now.Year(), now.Month()-(now.Month()-1)%3, 1, 0, 0, 0, 0, time.UTC)
+
+	switch s {
+	case "this-month":
+		return month(thisMonth), nil
+	case "last-month":
+		return month(thisMonth.AddDate(0, -1, 0)), nil
+	case "quarter", "this-quarter":
+		return quarter(thisQuarter), nil
+	case "last-quarter":
+		return quarter(thisQuarter.AddDate(0, -3, 0)), nil
+	}
+
+	start, err := time.Parse("2006-01", s)
+	if err != nil {
+		return Period{}, fmt.Errorf("unknown period '%s', must be one of: %s", s, Formats)
+	}
+	return month(start), nil
+}
+
+func month(start time.Time) Period {
+	return Period{Start: start, End: start.AddDate(0, 1, 0)}
+}
+
+func quarter(start time.Time) Period {
+	return Period{Start: start, End: start.AddDate(0, 3, 0), quarter: true}
+}
+
+// InProgress is whether now is within the period.
+func (p Period) InProgress(now time.Time) bool {
+	return !now.Before(p.Start) && now.Before(p.End)
+}
+
+// Window is the period as a window of Kubecost's APIs. A period in progress
+// ends now, to the minute.
+func (p Period) Window(now time.Time) string {
+	end := p.End
+	if p.InProgress(now) {
+		end = now.UTC().Truncate(time.Minute)
+	}
+	return fmt.Sprintf("%s,%s", p.Start.Format(time.RFC3339), end.Format(time.RFC3339))
+}
+
+// Remaining is how much of the period is left after now.
+func (p Period) Remaining(now time.Time) time.Duration {
+	if !p.InProgress(now) {
+		return 0
+	}
+	return p.End.Sub(now.UTC().Truncate(time.Minute))
+}
+
+func (p Period) String() string {
+	if p.quarter {
+		return fmt.Sprintf("Q%d %d", (int(p.Start.Month())-1)/3+1, p.Start.Year())
+	}
+	return p.Start.Format("January 2006")
+}
+
+// ProjectionFormats lists what ParseProjection accepts.
+const ProjectionFormats = "a number of days such as 30d, of hours such as 730h, or calendar-month"
+
+// DefaultProjection is the length of a month which monthly rates are
+// projected to by default, as used for predictions.
+const DefaultProjection = "730h"
+
+// ParseProjection parses the length of a month in hours which costs are
+// projected to: a number of days such as 30d, of hours such as 730h, or
+// calendar-month for the length of the month which now is in.
+func ParseProjection(s string, now time.Time) (float64, error) {
+	switch {
+	case s == "calendar-month":
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start.AddDate(0, 1, 0).Sub(start).Hours(), nil
+	case strings.HasSuffix(s, "d"):
+		days, err := strconv.ParseFloat(strings.TrimSuffix(s, "d"), 64)
+		if err == nil && days > 0 {
+			return days * 24, nil
+		}
+	case strings.HasSuffix(s, "h"):
+		hours, err := strconv.ParseFloat(strings.TrimSuffix(s, "h"), 64)
+		if err == nil && hours > 0 {
+			return hours, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown projection '%s', must be %s", s, ProjectionFormats)
+}
+
+// Projection is how costs over a window are displayed: as they are, as a
+// monthly rate, or to date with the projected remainder of a period in
+// progress. The zero value displays costs as they are.
+type Projection struct {
+	// MonthHours is the length of a month which costs are projected to.
+	MonthHours float64
+
+	// RemainingHours is what is left of a period in progress. If positive,
+	// costs are shown to date plus their rate over the remainder, instead
+	// of as a monthly rate.
+	RemainingHours float64
+}
+
+// Monthly projects costs to a monthly rate with a month of the given hours.
+func Monthly(hours float64) Projection {
+	return Projection{MonthHours: hours}
+}
+
+// DefaultMonthly projects costs to a monthly rate with a month of
+// timeutil.HoursPerMonth.
+func DefaultMonthly() Projection {
+	return Monthly(timeutil.HoursPerMonth)
+}
+
+// ToEndOf projects costs to date to the end of a period in progress. The
+// costs of a period which is over are shown as they are, i.e. as what the
+// period cost.
+func ToEndOf(p Period, now time.Time, monthHours float64) Projection {
+	if !p.InProgress(now) {
+		return Projection{}
+	}
+	return Projection{MonthHours: monthHours, RemainingHours: p.Remaining(now).Hours()}
+}
+
+// Projects is whether costs are scaled at all.
+func (p Projection) Projects() bool {
+	return p.MonthHours > 0 || p.RemainingHours > 0
+}
+
+// ToDate is whether costs are shown to date plus a projected remainder.
+func (p Projection) ToDate() bool {
+	return p.RemainingHours > 0
+}
+
+// Scale is what a cost over minutes is multiplied by to display it.
+func (p Projection) Scale(minutes float64) float64 {
+	if !p.Projects() || minutes <= 0 {
+		return 1
+	}
+	if p.ToDate() {
+		return 1 + p.RemainingHours*60/minutes
+	}
+
+	// Note that this assumes the window's costs apply through the entire
+	// month, no matter the window size.
+	return p.MonthHours * 60 / minutes
+}
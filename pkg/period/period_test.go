@@ -0,0 +1,98 @@
+package period
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2026, time.November, 10, 12, 30, 45, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	cases := map[string]struct {
+		window string
+		name   string
+	}{
+		"this-month":   {"2026-11-01T00:00:00Z,2026-11-10T12:30:00Z", "November 2026"},
+		"last-month":   {"2026-10-01T00:00:00Z,2026-11-01T00:00:00Z", "October 2026"},
+		"quarter":      {"2026-10-01T00:00:00Z,2026-11-10T12:30:00Z", "Q4 2026"},
+		"last-quarter": {"2026-07-01T00:00:00Z,2026-10-01T00:00:00Z", "Q3 2026"},
+		"2026-09":      {"2026-09-01T00:00:00Z,2026-10-01T00:00:00Z", "September 2026"},
+		"2026-12":      {"2026-12-01T00:00:00Z,2027-01-01T00:00:00Z", "December 2026"},
+	}
+	for s, c := range cases {
+		p, err := Parse(s, now)
+		if err != nil {
+			t.Errorf("%s: %s", s, err)
+			continue
+		}
+		if p.Window(now) != c.window || p.String() != c.name {
+			t.Errorf("%s: expected %s (%s), got %s (%s)", s, c.window, c.name, p.Window(now), p)
+		}
+	}
+
+	for _, s := range []string{"", "2026", "2026-13", "09-2026", "next-month"} {
+		if _, err := Parse(s, now); err == nil {
+			t.Errorf("expected an error for '%s'", s)
+		}
+	}
+}
+
+func TestParseLastMonthInJanuary(t *testing.T) {
+	p, err := Parse("last-month", time.Date(2027, time.January, 5, 0, 0, 0, 0, time.UTC))
+	if err != nil || p.String() != "December 2026" {
+		t.Errorf("expected December 2026, got %s (%v)", p, err)
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	p, _ := Parse("this-month", now)
+	if got := p.Remaining(now); got != 20*24*time.Hour+11*time.Hour+30*time.Minute {
+		t.Errorf("unexpected remainder %s", got)
+	}
+	p, _ = Parse("last-month", now)
+	if p.Remaining(now) != 0 {
+		t.Errorf("expected no remainder of a past period")
+	}
+}
+
+func TestParseProjection(t *testing.T) {
+	cases := map[string]float64{
+		"30d":            720,
+		"730h":           730,
+		"calendar-month": 720,
+	}
+	for s, expected := range cases {
+		hours, err := ParseProjection(s, now)
+		if err != nil || hours != expected {
+			t.Errorf("%s: expected %f, got %f (%v)", s, expected, hours, err)
+		}
+	}
+
+	for _, s := range []string{"", "month", "0d", "-1h", "30"} {
+		if _, err := ParseProjection(s, now); err == nil {
+			t.Errorf("expected an error for '%s'", s)
+		}
+	}
+}
+
+func TestProjectionScale(t *testing.T) {
+	if s := (Projection{}).Scale(60); s != 1 {
+		t.Errorf("expected no scaling, got %f", s)
+	}
+	if s := DefaultMonthly().Scale(1440); s != 730.0/24 {
+		t.Errorf("expected a day to be scaled to 730 hours, got %f", s)
+	}
+
+	// Ten days in with twenty left, the cost to date is tripled.
+	p, _ := Parse("2026-11", now)
+	proj := ToEndOf(p, time.Date(2026, time.November, 11, 0, 0, 0, 0, time.UTC), 730)
+	if !proj.ToDate() || math.Abs(proj.Scale(10*24*60)-3) > 1e-9 {
+		t.Errorf("expected to-date projection to triple costs, got %+v scaling by %f", proj, proj.Scale(10*24*60))
+	}
+
+	past := ToEndOf(p, time.Date(2026, time.December, 2, 0, 0, 0, 0, time.UTC), 730)
+	if past.Projects() || past.Scale(30*24*60) != 1 {
+		t.Errorf("expected a past period to be shown as what it cost, got %+v", past)
+	}
+}
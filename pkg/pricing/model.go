@@ -0,0 +1,203 @@
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kubecost/kubectl-cost/pkg/query"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Model is a snapshot of the resource prices of one or more clusters. It is
+// exported from Kubecost so that costs can be predicted without access to
+// the cluster.
+type Model struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+
+	// Window is the window of assets which prices were averaged over.
+	Window string `json:"window"`
+
+	CurrencyCode string `json:"currencyCode"`
+
+	// LocalCluster is the ID of the cluster which Kubecost was queried in,
+	// which predictions default to.
+	LocalCluster string `json:"localCluster"`
+
+	Clusters map[string]*ClusterPrices `json:"clusters"`
+}
+
+// ResourcePrices are the prices of compute resources.
+type ResourcePrices struct {
+	CPUCoreHour float64 `json:"cpuCoreHour"`
+	RAMGiBHour  float64 `json:"ramGiBHour"`
+	GPUHour     float64 `json:"gpuHour"`
+}
+
+// ClusterPrices are the prices of a single cluster.
+type ClusterPrices struct {
+	// ResourcePrices are averaged over every node of the cluster.
+	ResourcePrices
+
+	// NodeTypes are prices averaged over the nodes of each node type, e.g.
+	// "m5.xlarge".
+	NodeTypes map[string]ResourcePrices `json:"nodeTypes"`
+
+	Storage StorageRates `json:"storage"`
+
+	// DefaultStorageClass is the class used by claims which don't set one.
+	DefaultStorageClass string `json:"defaultStorageClass,omitempty"`
+
+	// Nodes are the scheduling-relevant parts of the cluster's nodes, used to
+	// determine which nodes a DaemonSet runs on. It is only known for the
+	// local cluster.
+	Nodes []Node `json:"nodes,omitempty"`
+}
+
+// Node is the part of a node which determines which pods can run on it.
+type Node struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Taints []corev1.Taint    `json:"taints,omitempty"`
+}
+
+// resourceTotals accumulates cost and usage, to be averaged into prices.
+type resourceTotals struct {
+	cpuCost, cpuCoreHours float64
+	ramCost, ramGiBHours  float64
+	gpuCost, gpuHours     float64
+}
+
+func (t *resourceTotals) add(n query.AssetNode) {
+	t.cpuCost += n.CPUCost
+	t.cpuCoreHours += n.CPUCoreHours
+	t.ramCost += n.RAMCost
+	t.ramGiBHours += n.RAMByteHours / BytesPerGiB
+	t.gpuCost += n.GPUCost
+	t.gpuHours += n.GPUHours
+}
+
+func (t resourceTotals) prices() ResourcePrices {
+	var p ResourcePrices
+	if t.cpuCoreHours > 0 {
+		p.CPUCoreHour = t.cpuCost / t.cpuCoreHours
+	}
+	if t.ramGiBHours > 0 {
+		p.RAMGiBHour = t.ramCost / t.ramGiBHours
+	}
+	if t.gpuHours > 0 {
+		p.GPUHour = t.gpuCost / t.gpuHours
+	}
+	return p
+}
+
+// NewModel averages the cost of node and disk assets into prices for each
+// cluster they belong to.
+func NewModel(window string, nodes []query.AssetNode, disks []query.AssetDisk) *Model {
+	clusterTotals := map[string]*resourceTotals{}
+	nodeTypeTotals := map[string]map[string]*resourceTotals{}
+	for _, n := range nodes {
+		cluster := n.Properties.Cluster
+		if clusterTotals[cluster] == nil {
+			clusterTotals[cluster] = &resourceTotals{}
+			nodeTypeTotals[cluster] = map[string]*resourceTotals{}
+		}
+		clusterTotals[cluster].add(n)
+
+		if n.NodeType != "" {
+			if nodeTypeTotals[cluster][n.NodeType] == nil {
+				nodeTypeTotals[cluster][n.NodeType] = &resourceTotals{}
+			}
+			nodeTypeTotals[cluster][n.NodeType].add(n)
+		}
+	}
+
+	disksByCluster := map[string][]query.AssetDisk{}
+	for _, d := range disks {
+		disksByCluster[d.Properties.Cluster] = append(disksByCluster[d.Properties.Cluster], d)
+	}
+
+	m := &Model{
+		GeneratedAt: time.Now().UTC(),
+		Window:      window,
+		Clusters:    map[string]*ClusterPrices{},
+	}
+	for cluster, totals := range clusterTotals {
+		c := &ClusterPrices{
+			ResourcePrices: totals.prices(),
+			NodeTypes:      map[string]ResourcePrices{},
+			Storage:        StorageRatesFromDisks(disksByCluster[cluster]),
+		}
+		for nodeType, t := range nodeTypeTotals[cluster] {
+			c.NodeTypes[nodeType] = t.prices()
+		}
+		m.Clusters[cluster] = c
+	}
+	return m
+}
+
+// Cluster returns the prices of the given cluster, or of the local cluster
+// if id is empty.
+func (m *Model) Cluster(id string) (*ClusterPrices, error) {
+	if id == "" {
+		id = m.LocalCluster
+	}
+	if c, ok := m.Clusters[id]; ok {
+		return c, nil
+	}
+
+	ids := make([]string, 0, len(m.Clusters))
+	for id := range m.Clusters {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return nil, fmt.Errorf("no prices for cluster '%s', the pricing model contains: %s", id, strings.Join(ids, ", "))
+}
+
+// SetNodes records the scheduling-relevant parts of nodes.
+func (c *ClusterPrices) SetNodes(nodes []corev1.Node) {
+	c.Nodes = nil
+	for _, n := range nodes {
+		c.Nodes = append(c.Nodes, Node{
+			Name:   n.Name,
+			Labels: n.Labels,
+			Taints: n.Spec.Taints,
+		})
+	}
+}
+
+// KubeNodes returns the recorded nodes as Kubernetes objects.
+func (c *ClusterPrices) KubeNodes() []corev1.Node {
+	var nodes []corev1.Node
+	for _, n := range c.Nodes {
+		nodes = append(nodes, corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: n.Name, Labels: n.Labels},
+			Spec:       corev1.NodeSpec{Taints: n.Taints},
+		})
+	}
+	return nodes
+}
+
+// Write writes m as indented JSON.
+func (m *Model) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// ReadModel reads a model written by Write.
+func ReadModel(r io.Reader) (*Model, error) {
+	var m Model
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("decoding pricing model: %s", err)
+	}
+	if len(m.Clusters) == 0 {
+		return nil, fmt.Errorf("pricing model contains no clusters")
+	}
+	return &m, nil
+}
@@ -0,0 +1,126 @@
+package pricing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubecost/kubectl-cost/pkg/manifests"
+	"github.com/kubecost/kubectl-cost/pkg/query"
+
+	"github.com/opencost/opencost/core/pkg/util/timeutil"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const resourceGPU corev1.ResourceName = "nvidia.com/gpu"
+
+// instanceTypeLabels are the node labels which name a node's type.
+var instanceTypeLabels = []string{
+	corev1.LabelInstanceTypeStable,
+	corev1.LabelInstanceType,
+}
+
+// Predict estimates the monthly cost of workloads from their resource
+// requests, like the speccost API does when historical usage is ignored.
+// Workloads which select a node type with a nodeSelector are priced at that
+// node type. Predictions are not compared against anything, so the cost
+// before is always zero. The returned assumptions describe estimates which
+// had to be made.
+func (c *ClusterPrices) Predict(workloads []*unstructured.Unstructured) ([]query.SpecCostDiff, []string, error) {
+	var rows []query.SpecCostDiff
+	var assumptions []string
+
+	for _, w := range workloads {
+		spec, replicas, err := manifests.PodSpec(w)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s %s/%s: %s", w.GetKind(), w.GetNamespace(), w.GetName(), err)
+		}
+
+		prices := c.ResourcePrices
+		for _, label := range instanceTypeLabels {
+			nodeType, ok := spec.NodeSelector[label]
+			if !ok {
+				continue
+			}
+			if p, ok := c.NodeTypes[nodeType]; ok {
+				prices = p
+			} else {
+				assumptions = append(assumptions, fmt.Sprintf("%s %s %s: no prices for node type '%s', priced at the cluster average", w.GetNamespace(), strings.ToLower(w.GetKind()), w.GetName(), nodeType))
+			}
+			break
+		}
+
+		requests := podRequests(spec)
+		hours := float64(replicas) * timeutil.HoursPerMonth
+		cpuCoreHours := float64(requests.Cpu().MilliValue()) / 1000 * hours
+		ramByteHours := float64(requests.Memory().Value()) * hours
+		gpuHours := float64(requests.Name(resourceGPU, resource.DecimalSI).Value()) * hours
+
+		after := query.CostPrediction{
+			CPUMonthlyRate: cpuCoreHours * prices.CPUCoreHour,
+			RAMMonthlyRate: ramByteHours / BytesPerGiB * prices.RAMGiBHour,
+			GPUMonthlyRate: gpuHours * prices.GPUHour,
+
+			MonthlyCPUCoreHours: cpuCoreHours,
+			MonthlyRAMByteHours: ramByteHours,
+			MonthlyGPUHours:     gpuHours,
+		}
+		after.TotalMonthlyRate = after.CPUMonthlyRate + after.RAMMonthlyRate + after.GPUMonthlyRate
+
+		rows = append(rows, query.SpecCostDiff{
+			Namespace:      w.GetNamespace(),
+			ControllerKind: strings.ToLower(w.GetKind()),
+			ControllerName: w.GetName(),
+			CostAfter:      after,
+			CostChange:     after,
+		})
+	}
+
+	return rows, assumptions, nil
+}
+
+// podRequests returns the effective resource requests of a pod: the sum of
+// its containers' requests, or the largest init container request if that is
+// larger, plus the pod overhead. GPUs are usually only set as limits, which
+// then also serve as requests.
+func podRequests(spec corev1.PodSpec) corev1.ResourceList {
+	containerRequests := func(c corev1.Container) corev1.ResourceList {
+		r := c.Resources.Requests.DeepCopy()
+		if r == nil {
+			r = corev1.ResourceList{}
+		}
+		if _, ok := r[resourceGPU]; !ok {
+			if gpus, ok := c.Resources.Limits[resourceGPU]; ok {
+				r[resourceGPU] = gpus
+			}
+		}
+		return r
+	}
+
+	total := corev1.ResourceList{}
+	for _, c := range spec.Containers {
+		for name, qty := range containerRequests(c) {
+			sum := total[name]
+			sum.Add(qty)
+			total[name] = sum
+		}
+	}
+
+	for _, c := range spec.InitContainers {
+		for name, qty := range containerRequests(c) {
+			if current, ok := total[name]; !ok || qty.Cmp(current) > 0 {
+				total[name] = qty
+			}
+		}
+	}
+
+	for name, qty := range spec.Overhead {
+		sum := total[name]
+		sum.Add(qty)
+		total[name] = sum
+	}
+
+	return total
+}
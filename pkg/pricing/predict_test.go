@@ -0,0 +1,77 @@
+package pricing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kubecost/kubectl-cost/pkg/manifests"
+)
+
+func TestClusterPricesPredict(t *testing.T) {
+	objs, err := manifests.Decode("test", []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+spec:
+  replicas: 2
+  template:
+    spec:
+      nodeSelector:
+        node.kubernetes.io/instance-type: m5.xlarge
+      initContainers:
+      - name: migrate
+        resources:
+          requests:
+            cpu: "2"
+      containers:
+      - name: app
+        resources:
+          requests:
+            cpu: 500m
+            memory: 1Gi
+      - name: sidecar
+        resources:
+          requests:
+            cpu: 500m
+          limits:
+            nvidia.com/gpu: 1
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	prices := ClusterPrices{
+		ResourcePrices: ResourcePrices{CPUCoreHour: 1, RAMGiBHour: 1, GPUHour: 1},
+		NodeTypes: map[string]ResourcePrices{
+			"m5.xlarge": {CPUCoreHour: 0.04, RAMGiBHour: 0.005, GPUHour: 2},
+		},
+	}
+	rows, _, err := prices.Predict(objs)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 row, got %d", len(rows))
+	}
+
+	// The init container's 2 cores exceed the containers' 1 core.
+	after := rows[0].CostAfter
+	expected := map[string][2]float64{
+		"CPU":      {after.MonthlyCPUCoreHours, 2 * 2 * 730},
+		"RAM":      {after.MonthlyRAMByteHours, 2 * 730 * BytesPerGiB},
+		"GPU":      {after.MonthlyGPUHours, 2 * 730},
+		"CPU cost": {after.CPUMonthlyRate, 2 * 2 * 730 * 0.04},
+		"RAM cost": {after.RAMMonthlyRate, 2 * 730 * 0.005},
+		"GPU cost": {after.GPUMonthlyRate, 2 * 730 * 2},
+		"total":    {after.TotalMonthlyRate, 2*2*730*0.04 + 2*730*0.005 + 2*730*2},
+	}
+	for name, e := range expected {
+		if math.Abs(e[0]-e[1]) > 1e-6 {
+			t.Errorf("%s: expected %f, got %f", name, e[1], e[0])
+		}
+	}
+	if rows[0].CostChange != after || rows[0].ControllerKind != "deployment" {
+		t.Errorf("unexpected row %+v", rows[0])
+	}
+}
@@ -0,0 +1,59 @@
+// Package pricing derives unit prices from the assets which Kubecost tracks,
+// for costs which have to be estimated client-side.
+package pricing
+
+import (
+	"github.com/kubecost/kubectl-cost/pkg/query"
+)
+
+// BytesPerGiB converts bytes to GiB, the unit which RAM and storage are
+// priced in.
+const BytesPerGiB = 1024 * 1024 * 1024
+
+// StorageRates are prices of persistent storage per GiB-hour.
+type StorageRates struct {
+	// ByClass maps StorageClass names to the average price of the disks
+	// provisioned with that class.
+	ByClass map[string]float64 `json:"byClass"`
+
+	// Default is the average price of all persistent volumes, used for
+	// classes which have no disks yet.
+	Default float64 `json:"default"`
+}
+
+// StorageRatesFromDisks averages the cost of disk assets by StorageClass.
+// Disks without a StorageClass, like node root volumes, are ignored.
+func StorageRatesFromDisks(disks []query.AssetDisk) StorageRates {
+	costs := map[string]float64{}
+	gibHours := map[string]float64{}
+	totalCost, totalGiBHours := 0.0, 0.0
+
+	for _, d := range disks {
+		if d.StorageClass == "" || d.ByteHours <= 0 {
+			continue
+		}
+		costs[d.StorageClass] += d.TotalCost
+		gibHours[d.StorageClass] += d.ByteHours / BytesPerGiB
+		totalCost += d.TotalCost
+		totalGiBHours += d.ByteHours / BytesPerGiB
+	}
+
+	rates := StorageRates{ByClass: map[string]float64{}}
+	for class, cost := range costs {
+		rates.ByClass[class] = cost / gibHours[class]
+	}
+	if totalGiBHours > 0 {
+		rates.Default = totalCost / totalGiBHours
+	}
+	return rates
+}
+
+// GiBHourRate returns the price of storage of the given class, and false if
+// the price is the average of all classes because there are no disks of that
+// class.
+func (r StorageRates) GiBHourRate(class string) (float64, bool) {
+	if rate, ok := r.ByClass[class]; ok {
+		return rate, true
+	}
+	return r.Default, false
+}
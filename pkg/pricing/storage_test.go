@@ -0,0 +1,28 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/kubecost/kubectl-cost/pkg/query"
+)
+
+func TestStorageRatesFromDisks(t *testing.T) {
+	disks := []query.AssetDisk{
+		{StorageClass: "gp3", ByteHours: 100 * BytesPerGiB, TotalCost: 1},
+		{StorageClass: "gp3", ByteHours: 300 * BytesPerGiB, TotalCost: 3},
+		{StorageClass: "io2", ByteHours: 100 * BytesPerGiB, TotalCost: 6},
+		// A node root volume, which must be ignored.
+		{ByteHours: 1000 * BytesPerGiB, TotalCost: 1000},
+	}
+	rates := StorageRatesFromDisks(disks)
+
+	if rate, ok := rates.GiBHourRate("gp3"); !ok || rate != 0.01 {
+		t.Errorf("gp3: expected 0.01, got %f (%t)", rate, ok)
+	}
+	if rate, ok := rates.GiBHourRate("io2"); !ok || rate != 0.06 {
+		t.Errorf("io2: expected 0.06, got %f (%t)", rate, ok)
+	}
+	if rate, ok := rates.GiBHourRate("standard"); ok || rate != 0.02 {
+		t.Errorf("standard: expected the average 0.02, got %f (%t)", rate, ok)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/opencost/opencost/core/pkg/opencost"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -30,15 +29,105 @@ type AssetParameters struct {
 // through the Kubernetes API server if useProxy is true or, if it isn't, by
 // temporarily port forwarding to a Kubecost pod.
 func QueryAssets(p AssetParameters) ([]map[string]AssetNode, error) {
+	bytes, err := queryAssetsRaw(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var ar assetResponse
+	err = json.Unmarshal(bytes, &ar)
+	if err != nil {
+		return ar.Data, fmt.Errorf("failed to unmarshal allocation response: %s", err)
+	}
+
+	return ar.Data, nil
+}
+
+type assetSetResponse struct {
+	Code int        `json:"code"`
+	Data []AssetSet `json:"data"`
+}
+
+type typedAssetResponse[T any] struct {
+	Code int            `json:"code"`
+	Data []map[string]T `json:"data"`
+}
+
+// queryTypedAssets queries /model/assets like QueryAssets, decoding each asset
+// as T.
+func queryTypedAssets[T any](p AssetParameters) ([]map[string]T, error) {
+	bytes, err := queryAssetsRaw(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var ar typedAssetResponse[T]
+	err = json.Unmarshal(bytes, &ar)
+	if err != nil {
+		return ar.Data, fmt.Errorf("failed to unmarshal asset response: %s", err)
+	}
+
+	return ar.Data, nil
+}
+
+// QueryDiskAssets is like QueryAssets, but for assets of type Disk. FilterTypes
+// is always "Disk".
+func QueryDiskAssets(p AssetParameters) ([]map[string]AssetDisk, error) {
+	p.FilterTypes = AssetTypeDisk
+	return queryTypedAssets[AssetDisk](p)
+}
+
+// QueryLoadBalancerAssets is like QueryAssets, but for assets of type
+// LoadBalancer. FilterTypes is always "LoadBalancer".
+func QueryLoadBalancerAssets(p AssetParameters) ([]map[string]AssetLoadBalancer, error) {
+	p.FilterTypes = AssetTypeLoadBalancer
+	return queryTypedAssets[AssetLoadBalancer](p)
+}
+
+// QueryNetworkAssets is like QueryAssets, but for assets of type Network.
+// FilterTypes is always "Network".
+func QueryNetworkAssets(p AssetParameters) ([]map[string]AssetNetwork, error) {
+	p.FilterTypes = AssetTypeNetwork
+	return queryTypedAssets[AssetNetwork](p)
+}
+
+// QueryCloudAssets is like QueryAssets, but for out-of-cluster cloud assets.
+// FilterTypes is always "Cloud".
+func QueryCloudAssets(p AssetParameters) ([]map[string]AssetCloud, error) {
+	p.FilterTypes = AssetTypeCloud
+	return queryTypedAssets[AssetCloud](p)
+}
+
+// QueryAssetSets is like QueryAssets, but decodes each asset as the type
+// named by its "type" field, so that FilterTypes can name any types, or none.
+func QueryAssetSets(p AssetParameters) ([]AssetSet, error) {
+	bytes, err := queryAssetsRaw(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var ar assetSetResponse
+	err = json.Unmarshal(bytes, &ar)
+	if err != nil {
+		return ar.Data, fmt.Errorf("failed to unmarshal asset response: %s", err)
+	}
+
+	return ar.Data, nil
+}
+
+func queryAssetsRaw(p AssetParameters) ([]byte, error) {
 
 	// aggregate, accumulate, and disableAdjustments are hardcoded;
 	// as other asset types are added in to be filtered by, this may change,
 	// but for now anything beyond isn't needed.
 
 	requestParams := map[string]string{
-		"window":      p.Window,
-		"accumulate":  p.Accumulate,
-		"filterTypes": p.FilterTypes,
+		"window":     p.Window,
+		"accumulate": p.Accumulate,
+	}
+
+	if p.FilterTypes != "" {
+		requestParams["filterTypes"] = p.FilterTypes
 	}
 
 	if p.Aggregate != "" {
@@ -53,7 +142,7 @@ func QueryAssets(p AssetParameters) ([]map[string]AssetNode, error) {
 			return nil, fmt.Errorf("failed to create clientset: %s", err)
 		}
 
-		bytes, err = clientset.CoreV1().Services(p.KubecostNamespace).ProxyGet("", p.ServiceName, string(p.ServicePort), "/model/assets", requestParams).DoRaw(p.Ctx)
+		bytes, err = clientset.CoreV1().Services(p.KubecostNamespace).ProxyGet("", p.ServiceName, fmt.Sprint(p.ServicePort), "/model/assets", requestParams).DoRaw(p.Ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to proxy get opencost. err: %s; data: %s", err, bytes)
 		}
@@ -64,36 +153,5 @@ func QueryAssets(p AssetParameters) ([]map[string]AssetNode, error) {
 		}
 	}
 
-	var ar assetResponse
-	err = json.Unmarshal(bytes, &ar)
-	if err != nil {
-		return ar.Data, fmt.Errorf("failed to unmarshal allocation response: %s", err)
-	}
-
-	return ar.Data, nil
-}
-
-type AssetNode struct {
-	Type         string                   `json:"type"`
-	Properties   opencost.AssetProperties `json:"properties"`
-	Labels       opencost.AssetLabels     `json:"labels"`
-	Start        string                   `json:"start"`
-	End          string                   `json:"end"`
-	Minutes      float64                  `json:"minutes"`
-	NodeType     string                   `json:"nodeType"`
-	CpuCores     float64                  `json:"cpuCores"`
-	RamBytes     float64                  `json:"ramBytes"`
-	CPUCoreHours float64                  `json:"cpuCoreHours"`
-	RAMByteHours float64                  `json:"ramByteHours"`
-	GPUHours     float64                  `json:"GPUHours"`
-	CPUBreakdown opencost.Breakdown       `json:"cpuBreakdown"`
-	GPUBreakdown opencost.Breakdown       `json:"ramBreakdown"`
-	Preemptible  float64                  `json:"preemptible"`
-	Discount     float64                  `json:"discount"`
-	CPUCost      float64                  `json:"cpuCost"`
-	GPUCost      float64                  `json:"gpuCost"`
-	GPUCount     float64                  `json:"gpuCount"`
-	RAMCost      float64                  `json:"ramCost"`
-	Adjustment   float64                  `json:"adjustment"`
-	TotalCost    float64                  `json:"totalCost"`
+	return bytes, nil
 }
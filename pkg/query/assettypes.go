@@ -0,0 +1,217 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/opencost/opencost/core/pkg/opencost"
+)
+
+// Asset types, as named by the "type" field of assets in /model/assets
+// responses.
+const (
+	AssetTypeNode              = "Node"
+	AssetTypeDisk              = "Disk"
+	AssetTypeLoadBalancer      = "LoadBalancer"
+	AssetTypeNetwork           = "Network"
+	AssetTypeClusterManagement = "ClusterManagement"
+	AssetTypeCloud             = "Cloud"
+)
+
+// Asset is implemented by all typed assets. Common returns the fields which
+// all asset types have.
+type Asset interface {
+	Common() AssetCommon
+}
+
+// AssetSet is a set of assets of any type, keyed like the sets of a
+// /model/assets response. Each asset is decoded by DecodeAsset.
+type AssetSet map[string]Asset
+
+func (as *AssetSet) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	set := make(AssetSet, len(raw))
+	for key, r := range raw {
+		asset, err := DecodeAsset(r)
+		if err != nil {
+			return fmt.Errorf("decoding asset '%s': %s", key, err)
+		}
+		set[key] = asset
+	}
+	*as = set
+	return nil
+}
+
+// DecodeAsset decodes an asset as the type named by its "type" field. Assets
+// of types unknown to this package decode as AssetCommon.
+func DecodeAsset(b []byte) (Asset, error) {
+	var typed struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(b, &typed); err != nil {
+		return nil, err
+	}
+
+	switch typed.Type {
+	case AssetTypeNode:
+		return decodeAs[AssetNode](b)
+	case AssetTypeDisk:
+		return decodeAs[AssetDisk](b)
+	case AssetTypeLoadBalancer:
+		return decodeAs[AssetLoadBalancer](b)
+	case AssetTypeNetwork:
+		return decodeAs[AssetNetwork](b)
+	case AssetTypeClusterManagement:
+		return decodeAs[AssetClusterManagement](b)
+	case AssetTypeCloud:
+		return decodeAs[AssetCloud](b)
+	}
+	return decodeAs[AssetCommon](b)
+}
+
+func decodeAs[T Asset](b []byte) (Asset, error) {
+	var asset T
+	if err := json.Unmarshal(b, &asset); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// AssetCommon holds the fields which are common to all asset types.
+type AssetCommon struct {
+	Type       string                   `json:"type"`
+	Properties opencost.AssetProperties `json:"properties"`
+	Labels     opencost.AssetLabels     `json:"labels"`
+	Start      string                   `json:"start"`
+	End        string                   `json:"end"`
+	Minutes    float64                  `json:"minutes"`
+	Adjustment float64                  `json:"adjustment"`
+	TotalCost  float64                  `json:"totalCost"`
+}
+
+func (a AssetCommon) Common() AssetCommon {
+	return a
+}
+
+type AssetNode struct {
+	Type         string                   `json:"type"`
+	Properties   opencost.AssetProperties `json:"properties"`
+	Labels       opencost.AssetLabels     `json:"labels"`
+	Start        string                   `json:"start"`
+	End          string                   `json:"end"`
+	Minutes      float64                  `json:"minutes"`
+	NodeType     string                   `json:"nodeType"`
+	Pool         string                   `json:"pool"`
+	CpuCores     float64                  `json:"cpuCores"`
+	RamBytes     float64                  `json:"ramBytes"`
+	CPUCoreHours float64                  `json:"cpuCoreHours"`
+	RAMByteHours float64                  `json:"ramByteHours"`
+	GPUHours     float64                  `json:"GPUHours"`
+	CPUBreakdown opencost.Breakdown       `json:"cpuBreakdown"`
+	RAMBreakdown opencost.Breakdown       `json:"ramBreakdown"`
+	Preemptible  float64                  `json:"preemptible"`
+	Discount     float64                  `json:"discount"`
+	CPUCost      float64                  `json:"cpuCost"`
+	GPUCost      float64                  `json:"gpuCost"`
+	GPUCount     float64                  `json:"gpuCount"`
+	RAMCost      float64                  `json:"ramCost"`
+	Adjustment   float64                  `json:"adjustment"`
+	Overhead     *opencost.NodeOverhead   `json:"overhead,omitempty"`
+	TotalCost    float64                  `json:"totalCost"`
+}
+
+func (a AssetNode) Common() AssetCommon {
+	return AssetCommon{a.Type, a.Properties, a.Labels, a.Start, a.End, a.Minutes, a.Adjustment, a.TotalCost}
+}
+
+type AssetDisk struct {
+	Type           string                   `json:"type"`
+	Properties     opencost.AssetProperties `json:"properties"`
+	Labels         opencost.AssetLabels     `json:"labels"`
+	Start          string                   `json:"start"`
+	End            string                   `json:"end"`
+	Minutes        float64                  `json:"minutes"`
+	ByteHours      float64                  `json:"byteHours"`
+	Bytes          float64                  `json:"bytes"`
+	ByteHoursUsed  *float64                 `json:"byteHoursUsed"`
+	ByteUsageMax   *float64                 `json:"byteUsageMax"`
+	Breakdown      opencost.Breakdown       `json:"breakdown"`
+	Adjustment     float64                  `json:"adjustment"`
+	TotalCost      float64                  `json:"totalCost"`
+	StorageClass   string                   `json:"storageClass"`
+	VolumeName     string                   `json:"volumeName"`
+	ClaimName      string                   `json:"claimName"`
+	ClaimNamespace string                   `json:"claimNamespace"`
+}
+
+func (a AssetDisk) Common() AssetCommon {
+	return AssetCommon{a.Type, a.Properties, a.Labels, a.Start, a.End, a.Minutes, a.Adjustment, a.TotalCost}
+}
+
+type AssetLoadBalancer struct {
+	Type       string                   `json:"type"`
+	Properties opencost.AssetProperties `json:"properties"`
+	Labels     opencost.AssetLabels     `json:"labels"`
+	Start      string                   `json:"start"`
+	End        string                   `json:"end"`
+	Minutes    float64                  `json:"minutes"`
+	Adjustment float64                  `json:"adjustment"`
+	TotalCost  float64                  `json:"totalCost"`
+	Private    bool                     `json:"private"`
+	IP         string                   `json:"ip"`
+}
+
+func (a AssetLoadBalancer) Common() AssetCommon {
+	return AssetCommon{a.Type, a.Properties, a.Labels, a.Start, a.End, a.Minutes, a.Adjustment, a.TotalCost}
+}
+
+type AssetNetwork struct {
+	Type       string                   `json:"type"`
+	Properties opencost.AssetProperties `json:"properties"`
+	Labels     opencost.AssetLabels     `json:"labels"`
+	Start      string                   `json:"start"`
+	End        string                   `json:"end"`
+	Minutes    float64                  `json:"minutes"`
+	Adjustment float64                  `json:"adjustment"`
+	TotalCost  float64                  `json:"totalCost"`
+}
+
+func (a AssetNetwork) Common() AssetCommon {
+	return AssetCommon{a.Type, a.Properties, a.Labels, a.Start, a.End, a.Minutes, a.Adjustment, a.TotalCost}
+}
+
+// AssetClusterManagement is the fee charged for a managed control plane. It
+// has no adjustment.
+type AssetClusterManagement struct {
+	Type       string                   `json:"type"`
+	Properties opencost.AssetProperties `json:"properties"`
+	Labels     opencost.AssetLabels     `json:"labels"`
+	Start      string                   `json:"start"`
+	End        string                   `json:"end"`
+	Minutes    float64                  `json:"minutes"`
+	TotalCost  float64                  `json:"totalCost"`
+}
+
+func (a AssetClusterManagement) Common() AssetCommon {
+	return AssetCommon{a.Type, a.Properties, a.Labels, a.Start, a.End, a.Minutes, 0, a.TotalCost}
+}
+
+type AssetCloud struct {
+	Type       string                   `json:"type"`
+	Properties opencost.AssetProperties `json:"properties"`
+	Labels     opencost.AssetLabels     `json:"labels"`
+	Start      string                   `json:"start"`
+	End        string                   `json:"end"`
+	Minutes    float64                  `json:"minutes"`
+	Adjustment float64                  `json:"adjustment"`
+	Credit     float64                  `json:"credit"`
+	TotalCost  float64                  `json:"totalCost"`
+}
+
+func (a AssetCloud) Common() AssetCommon {
+	return AssetCommon{a.Type, a.Properties, a.Labels, a.Start, a.End, a.Minutes, a.Adjustment, a.TotalCost}
+}
@@ -0,0 +1,158 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func readAssetPayload(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("reading payload: %s", err)
+	}
+	return b
+}
+
+func TestAssetNodeDecoding(t *testing.T) {
+	var ar assetResponse
+	if err := json.Unmarshal(readAssetPayload(t, "assets_node.json"), &ar); err != nil {
+		t.Fatalf("unmarshaling: %s", err)
+	}
+	if len(ar.Data) != 1 || len(ar.Data[0]) != 2 {
+		t.Fatalf("expected one set of two nodes, got %+v", ar.Data)
+	}
+
+	node := ar.Data[0]["GCP/__undefined__/guestbook-227502/Compute/cluster-one/Node/Kubernetes/6794302418893391284/gke-cluster-one-default-pool-d6266c7c-dqms"]
+	if node.CPUBreakdown.Idle != 0.6112 {
+		t.Errorf("expected CPU idle 0.6112, got %f", node.CPUBreakdown.Idle)
+	}
+	if node.RAMBreakdown.Idle != 0.3825 || node.RAMBreakdown.User != 0.4252 {
+		t.Errorf("expected RAM breakdown to be decoded from ramBreakdown, got %+v", node.RAMBreakdown)
+	}
+	if node.Pool != "default-pool" || node.Preemptible != 1 || node.Discount != 0.7 {
+		t.Errorf("unexpected node %+v", node)
+	}
+	if node.Overhead == nil || node.Overhead.CpuOverheadFraction != 0.0775 {
+		t.Errorf("expected overhead to be decoded, got %+v", node.Overhead)
+	}
+
+	gpu := ar.Data[0]["GCP/__undefined__/guestbook-227502/Compute/cluster-one/Node/Kubernetes/3110587716427651170/gke-cluster-one-gpu-pool-9bb98ef8-3w6g"]
+	if gpu.GPUCount != 1 || gpu.GPUHours != 18 || gpu.GPUCost != 6.3 || gpu.Overhead != nil {
+		t.Errorf("unexpected GPU node %+v", gpu)
+	}
+}
+
+func TestAssetSetDecoding(t *testing.T) {
+	var ar assetSetResponse
+	if err := json.Unmarshal(readAssetPayload(t, "assets_all.json"), &ar); err != nil {
+		t.Fatalf("unmarshaling: %s", err)
+	}
+	if len(ar.Data) != 1 {
+		t.Fatalf("expected one set, got %d", len(ar.Data))
+	}
+
+	counts := map[string]int{}
+	for key, asset := range ar.Data[0] {
+		common := asset.Common()
+		if common.Minutes != 1440 || common.TotalCost <= 0 {
+			t.Errorf("%s: unexpected common fields %+v", key, common)
+		}
+
+		switch a := asset.(type) {
+		case AssetNode:
+			counts[AssetTypeNode]++
+			if a.NodeType != "n2-standard-4" {
+				t.Errorf("unexpected node %+v", a)
+			}
+		case AssetDisk:
+			counts[AssetTypeDisk]++
+			if a.ClaimName == "data-postgres-0" && (a.ByteUsageMax == nil || *a.ByteUsageMax != 53687091200) {
+				t.Errorf("expected max usage of claimed disk, got %+v", a)
+			}
+			if a.ClaimName == "" && a.ByteHoursUsed != nil {
+				t.Errorf("expected no usage of node disk, got %f", *a.ByteHoursUsed)
+			}
+		case AssetLoadBalancer:
+			counts[AssetTypeLoadBalancer]++
+			if a.IP != "34.122.18.201" || a.Private {
+				t.Errorf("unexpected load balancer %+v", a)
+			}
+		case AssetNetwork:
+			counts[AssetTypeNetwork]++
+		case AssetClusterManagement:
+			counts[AssetTypeClusterManagement]++
+			if a.Properties.Provider != "GCP" {
+				t.Errorf("unexpected cluster management %+v", a)
+			}
+		case AssetCloud:
+			counts[AssetTypeCloud]++
+			if a.Credit != -0.021 || a.Properties.Service != "Cloud Storage" {
+				t.Errorf("unexpected cloud asset %+v", a)
+			}
+		default:
+			t.Errorf("%s: decoded as unexpected type %T", key, asset)
+		}
+	}
+
+	expected := map[string]int{
+		AssetTypeNode:              1,
+		AssetTypeDisk:              2,
+		AssetTypeLoadBalancer:      1,
+		AssetTypeNetwork:           1,
+		AssetTypeClusterManagement: 1,
+		AssetTypeCloud:             1,
+	}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("expected %v, got %v", expected, counts)
+	}
+}
+
+// TestAssetModelIsComplete fails when the payloads have fields which the
+// typed model doesn't decode. The window duplicates start and end.
+func TestAssetModelIsComplete(t *testing.T) {
+	for _, name := range []string{"assets_node.json", "assets_all.json"} {
+		var raw struct {
+			Data []map[string]map[string]json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(readAssetPayload(t, name), &raw); err != nil {
+			t.Fatalf("%s: unmarshaling: %s", name, err)
+		}
+
+		for _, set := range raw.Data {
+			for key, fields := range set {
+				delete(fields, "window")
+				b, err := json.Marshal(fields)
+				if err != nil {
+					t.Fatalf("%s: %s", key, err)
+				}
+
+				asset, err := DecodeAsset(b)
+				if err != nil {
+					t.Fatalf("%s: decoding: %s", key, err)
+				}
+
+				strict := reflect.New(reflect.TypeOf(asset)).Interface()
+				dec := json.NewDecoder(bytes.NewReader(b))
+				dec.DisallowUnknownFields()
+				if err := dec.Decode(strict); err != nil {
+					t.Errorf("%s: %T is missing a field: %s", key, asset, err)
+				}
+			}
+		}
+	}
+}
+
+func TestDecodeUnknownAssetType(t *testing.T) {
+	asset, err := DecodeAsset([]byte(`{"type":"Shared","properties":{"name":"support"},"minutes":60,"totalCost":5}`))
+	if err != nil {
+		t.Fatalf("decoding: %s", err)
+	}
+	common, ok := asset.(AssetCommon)
+	if !ok || common.Properties.Name != "support" || common.TotalCost != 5 {
+		t.Errorf("expected unknown type to decode as AssetCommon, got %#v", asset)
+	}
+}
@@ -30,7 +30,7 @@ func QueryClusterID(p ClusterInfoParameters) (string, error) {
 			return "", fmt.Errorf("failed to create clientset: %s", err)
 		}
 
-		bytes, err = clientset.CoreV1().Services(p.KubecostNamespace).ProxyGet("", p.ServiceName, string(p.ServicePort), "/model/clusterInfo", nil).DoRaw(p.Ctx)
+		bytes, err = clientset.CoreV1().Services(p.KubecostNamespace).ProxyGet("", p.ServiceName, fmt.Sprint(p.ServicePort), "/model/clusterInfo", nil).DoRaw(p.Ctx)
 
 		if err != nil {
 			return "", fmt.Errorf("failed to proxy get kubecost. err: %s; data: %s", err, bytes)
@@ -100,6 +100,16 @@ func (o *QueryBackendOptions) Validate() error {
 	return nil
 }
 
+// Describe is a short description of the service queries are sent to and
+// how, e.g. "kubecost/kubecost-cost-analyzer:9090 via port-forward".
+func (o *QueryBackendOptions) Describe() string {
+	via := "port-forward"
+	if o.UseProxy {
+		via = "API server proxy"
+	}
+	return fmt.Sprintf("%s/%s:%d via %s", o.KubecostNamespace, o.ServiceName, o.ServicePort, via)
+}
+
 func AddQueryBackendOptionsFlags(cmd *cobra.Command, options *QueryBackendOptions) {
 	cmd.Flags().StringVarP(&options.HelmReleaseName, "release-name", "r", "kubecost", "The name of the Helm release, used to template service names if they are unset. For example, if Kubecost is installed with 'helm install kubecost2 kubecost/cost-analyzer', then this should be set to 'kubecost2'.")
 	cmd.Flags().StringVarP(&options.KubecostNamespace, "kubecost-namespace", "N", "", "The namespace that Kubecost is deployed in. Requests to the API will be directed to this namespace. Defaults to the Helm release name.")
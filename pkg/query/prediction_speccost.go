@@ -16,9 +16,51 @@ type CostPrediction struct {
 	RAMMonthlyRate   float64 `json:"ramMonthlyRate"`
 	GPUMonthlyRate   float64 `json:"gpuMonthlyRate"`
 
+	// PVMonthlyRate is not returned by the speccost API. It is predicted
+	// client-side from storage prices, see pkg/pricing.
+	PVMonthlyRate float64 `json:"pvMonthlyRate"`
+
 	MonthlyCPUCoreHours float64 `json:"monthlyCPUCoreHours"`
 	MonthlyRAMByteHours float64 `json:"monthlyRAMByteHours"`
 	MonthlyGPUHours     float64 `json:"monthlyGPUHours"`
+	MonthlyPVByteHours  float64 `json:"monthlyPVByteHours"`
+}
+
+// Add returns the sum p + o.
+func (p CostPrediction) Add(o CostPrediction) CostPrediction {
+	return p.Sub(o.Scale(-1))
+}
+
+// Sub returns the difference p - o.
+func (p CostPrediction) Sub(o CostPrediction) CostPrediction {
+	return CostPrediction{
+		TotalMonthlyRate: p.TotalMonthlyRate - o.TotalMonthlyRate,
+		CPUMonthlyRate:   p.CPUMonthlyRate - o.CPUMonthlyRate,
+		RAMMonthlyRate:   p.RAMMonthlyRate - o.RAMMonthlyRate,
+		GPUMonthlyRate:   p.GPUMonthlyRate - o.GPUMonthlyRate,
+		PVMonthlyRate:    p.PVMonthlyRate - o.PVMonthlyRate,
+
+		MonthlyCPUCoreHours: p.MonthlyCPUCoreHours - o.MonthlyCPUCoreHours,
+		MonthlyRAMByteHours: p.MonthlyRAMByteHours - o.MonthlyRAMByteHours,
+		MonthlyGPUHours:     p.MonthlyGPUHours - o.MonthlyGPUHours,
+		MonthlyPVByteHours:  p.MonthlyPVByteHours - o.MonthlyPVByteHours,
+	}
+}
+
+// Scale returns p with every rate and quantity multiplied by f.
+func (p CostPrediction) Scale(f float64) CostPrediction {
+	return CostPrediction{
+		TotalMonthlyRate: p.TotalMonthlyRate * f,
+		CPUMonthlyRate:   p.CPUMonthlyRate * f,
+		RAMMonthlyRate:   p.RAMMonthlyRate * f,
+		GPUMonthlyRate:   p.GPUMonthlyRate * f,
+		PVMonthlyRate:    p.PVMonthlyRate * f,
+
+		MonthlyCPUCoreHours: p.MonthlyCPUCoreHours * f,
+		MonthlyRAMByteHours: p.MonthlyRAMByteHours * f,
+		MonthlyGPUHours:     p.MonthlyGPUHours * f,
+		MonthlyPVByteHours:  p.MonthlyPVByteHours * f,
+	}
 }
 
 type SpecCostDiff struct {
@@ -0,0 +1,364 @@
+package rightsizing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// PreviousResourcesAnnotation records the container resources of a
+// controller from before recommendations were applied to it, as a JSON
+// object of container name to resources.
+const PreviousResourcesAnnotation = "cost.kubecost.com/previous-resources"
+
+// liveKinds are the kinds which recommendations can be applied to live.
+var liveKinds = map[string]bool{
+	"Deployment":  true,
+	"StatefulSet": true,
+	"DaemonSet":   true,
+}
+
+// CanApplyLive returns true if changes to controllers of the given kind can
+// be applied to the cluster.
+func CanApplyLive(kind string) bool {
+	return liveKinds[kind]
+}
+
+// LiveController is the resource-related state of a controller in the
+// cluster.
+type LiveController struct {
+	Kind      string
+	Namespace string
+	Name      string
+
+	Containers  map[string]corev1.ResourceRequirements
+	Annotations map[string]string
+}
+
+func newLiveController(kind string, meta metav1.ObjectMeta, spec corev1.PodSpec) *LiveController {
+	c := &LiveController{
+		Kind:        kind,
+		Namespace:   meta.Namespace,
+		Name:        meta.Name,
+		Containers:  map[string]corev1.ResourceRequirements{},
+		Annotations: meta.Annotations,
+	}
+	for _, container := range spec.Containers {
+		c.Containers[container.Name] = container.Resources
+	}
+	return c
+}
+
+// GetLiveController reads a Deployment, StatefulSet or DaemonSet.
+func GetLiveController(ctx context.Context, clientset kubernetes.Interface, kind, namespace, name string) (*LiveController, error) {
+	switch kind {
+	case "Deployment":
+		d, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return newLiveController(kind, d.ObjectMeta, d.Spec.Template.Spec), nil
+	case "StatefulSet":
+		s, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return newLiveController(kind, s.ObjectMeta, s.Spec.Template.Spec), nil
+	case "DaemonSet":
+		d, err := clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return newLiveController(kind, d.ObjectMeta, d.Spec.Template.Spec), nil
+	}
+	return nil, fmt.Errorf("unsupported controller kind '%s', must be one of: Deployment, StatefulSet, DaemonSet", kind)
+}
+
+// ListRevertable returns the controllers in namespace (all namespaces if
+// empty) which have had recommendations applied.
+func ListRevertable(ctx context.Context, clientset kubernetes.Interface, namespace string) ([]*LiveController, error) {
+	var result []*LiveController
+	keep := func(kind string, meta metav1.ObjectMeta, spec corev1.PodSpec) {
+		if _, ok := meta.Annotations[PreviousResourcesAnnotation]; ok {
+			result = append(result, newLiveController(kind, meta, spec))
+		}
+	}
+
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing deployments: %s", err)
+	}
+	for _, d := range deployments.Items {
+		keep("Deployment", d.ObjectMeta, d.Spec.Template.Spec)
+	}
+
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing statefulsets: %s", err)
+	}
+	for _, s := range statefulSets.Items {
+		keep("StatefulSet", s.ObjectMeta, s.Spec.Template.Spec)
+	}
+
+	daemonSets, err := clientset.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing daemonsets: %s", err)
+	}
+	for _, d := range daemonSets.Items {
+		keep("DaemonSet", d.ObjectMeta, d.Spec.Template.Spec)
+	}
+
+	return result, nil
+}
+
+// PreviousResources decodes the PreviousResourcesAnnotation of c.
+func (c *LiveController) PreviousResources() (map[string]corev1.ResourceRequirements, error) {
+	value, ok := c.Annotations[PreviousResourcesAnnotation]
+	if !ok {
+		return nil, fmt.Errorf("%s %s/%s has no %s annotation", c.Kind, c.Namespace, c.Name, PreviousResourcesAnnotation)
+	}
+	var previous map[string]corev1.ResourceRequirements
+	if err := json.Unmarshal([]byte(value), &previous); err != nil {
+		return nil, fmt.Errorf("decoding %s annotation of %s %s/%s: %s", PreviousResourcesAnnotation, c.Kind, c.Namespace, c.Name, err)
+	}
+	return previous, nil
+}
+
+// ApplyPatch returns a strategic-merge patch which applies change to the
+// live controller and records its current resources in the
+// PreviousResourcesAnnotation. If recommendations were applied before, the
+// recorded resources of containers are kept and only containers which weren't
+// changed before are added, so that reverting restores the original
+// resources.
+func ApplyPatch(change Change, live *LiveController) ([]byte, error) {
+	for _, res := range change.Containers {
+		if _, ok := live.Containers[res.Name]; !ok {
+			return nil, fmt.Errorf("%s %s/%s has no container '%s'", live.Kind, live.Namespace, live.Name, res.Name)
+		}
+	}
+
+	patch := change.fitLimits(live).StrategicMergePatch()
+	delete(patch, "apiVersion")
+	delete(patch, "kind")
+	delete(patch, "metadata")
+
+	previous := map[string]corev1.ResourceRequirements{}
+	if _, ok := live.Annotations[PreviousResourcesAnnotation]; ok {
+		var err error
+		if previous, err = live.PreviousResources(); err != nil {
+			return nil, err
+		}
+	}
+	added := false
+	for _, res := range change.Containers {
+		if _, ok := previous[res.Name]; !ok {
+			previous[res.Name] = live.Containers[res.Name]
+			added = true
+		}
+	}
+
+	if added {
+		b, err := json.Marshal(previous)
+		if err != nil {
+			return nil, fmt.Errorf("encoding previous resources: %s", err)
+		}
+		patch["metadata"] = map[string]interface{}{
+			"annotations": map[string]interface{}{
+				PreviousResourcesAnnotation: string(b),
+			},
+		}
+	}
+
+	return json.Marshal(patch)
+}
+
+// fitLimits returns change with limits raised to the new requests where a
+// request would exceed the live limit and change doesn't set that limit,
+// since the API server rejects requests above limits.
+func (change Change) fitLimits(live *LiveController) Change {
+	fitted := change
+	fitted.Containers = make([]ContainerResources, len(change.Containers))
+	for i, res := range change.Containers {
+		current := live.Containers[res.Name]
+		for name, request := range res.Requests {
+			if _, ok := res.Limits[name]; ok {
+				continue
+			}
+			limit, ok := current.Limits[name]
+			if !ok || request.Cmp(limit) <= 0 {
+				continue
+			}
+			limits := corev1.ResourceList{}
+			for n, qty := range res.Limits {
+				limits[n] = qty
+			}
+			limits[name] = request
+			res.Limits = limits
+		}
+		fitted.Containers[i] = res
+	}
+	return fitted
+}
+
+// RevertPatch returns a strategic-merge patch which restores the resources
+// recorded in the PreviousResourcesAnnotation of the live controller and
+// removes the annotation.
+func RevertPatch(live *LiveController) ([]byte, error) {
+	previous, err := live.PreviousResources()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range previous {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var containers []interface{}
+	for _, name := range names {
+		// Replace rather than merge, so that limits which were added are
+		// removed again.
+		resources := map[string]interface{}{"$patch": "replace"}
+		if len(previous[name].Requests) > 0 {
+			resources["requests"] = quantities(previous[name].Requests)
+		}
+		if len(previous[name].Limits) > 0 {
+			resources["limits"] = quantities(previous[name].Limits)
+		}
+		containers = append(containers, map[string]interface{}{
+			"name":      name,
+			"resources": resources,
+		})
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				PreviousResourcesAnnotation: nil,
+			},
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": containers,
+				},
+			},
+		},
+	})
+}
+
+// Patch applies a strategic-merge patch to a live controller. If dryRun is
+// true, the API server validates the patch without persisting it.
+func Patch(ctx context.Context, clientset kubernetes.Interface, kind, namespace, name string, patch []byte, dryRun bool) error {
+	opts := metav1.PatchOptions{}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+
+	var err error
+	switch kind {
+	case "Deployment":
+		_, err = clientset.AppsV1().Deployments(namespace).Patch(ctx, name, types.StrategicMergePatchType, patch, opts)
+	case "StatefulSet":
+		_, err = clientset.AppsV1().StatefulSets(namespace).Patch(ctx, name, types.StrategicMergePatchType, patch, opts)
+	case "DaemonSet":
+		_, err = clientset.AppsV1().DaemonSets(namespace).Patch(ctx, name, types.StrategicMergePatchType, patch, opts)
+	default:
+		err = fmt.Errorf("unsupported controller kind '%s'", kind)
+	}
+	if err != nil {
+		return fmt.Errorf("patching %s %s/%s: %s", strings.ToLower(kind), namespace, name, err)
+	}
+	return nil
+}
+
+// ResourceChange is a change of a single request or limit of a container.
+type ResourceChange struct {
+	// Field is e.g. "cpu request".
+	Field string
+
+	// From and To are empty if the request or limit isn't set.
+	From string
+	To   string
+}
+
+// ContainerDiff is the set of changes to a single container.
+type ContainerDiff struct {
+	Name    string
+	Changes []ResourceChange
+}
+
+// Diff compares the resources of containers before and after a change.
+// Unchanged values are omitted.
+func Diff(before, after map[string]corev1.ResourceRequirements) []ContainerDiff {
+	var names []string
+	for name := range after {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var diffs []ContainerDiff
+	for _, name := range names {
+		d := ContainerDiff{Name: name}
+		for _, r := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			fields := []struct {
+				field         string
+				before, after corev1.ResourceList
+			}{
+				{fmt.Sprintf("%s request", r), before[name].Requests, after[name].Requests},
+				{fmt.Sprintf("%s limit", r), before[name].Limits, after[name].Limits},
+			}
+			for _, f := range fields {
+				from, to := quantityString(f.before, r), quantityString(f.after, r)
+				if from != to {
+					d.Changes = append(d.Changes, ResourceChange{Field: f.field, From: from, To: to})
+				}
+			}
+		}
+		if len(d.Changes) > 0 {
+			diffs = append(diffs, d)
+		}
+	}
+	return diffs
+}
+
+func quantityString(l corev1.ResourceList, name corev1.ResourceName) string {
+	if qty, ok := l[name]; ok {
+		return qty.String()
+	}
+	return ""
+}
+
+// Resources returns the container resources of the live controller after
+// change is applied, for use with Diff. Limits which the change doesn't set
+// are unchanged, unless the new request exceeds them, in which case they are
+// raised to the request.
+func (change Change) Resources(live *LiveController) map[string]corev1.ResourceRequirements {
+	after := map[string]corev1.ResourceRequirements{}
+	for _, res := range change.fitLimits(live).Containers {
+		current := live.Containers[res.Name]
+		r := *current.DeepCopy()
+		if r.Requests == nil {
+			r.Requests = corev1.ResourceList{}
+		}
+		for name, qty := range res.Requests {
+			r.Requests[name] = qty
+		}
+		if len(res.Limits) > 0 && r.Limits == nil {
+			r.Limits = corev1.ResourceList{}
+		}
+		for name, qty := range res.Limits {
+			r.Limits[name] = qty
+		}
+		after[res.Name] = r
+	}
+	return after
+}
@@ -0,0 +1,205 @@
+package rightsizing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubecost/kubectl-cost/pkg/query"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestApplyAndRevert(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "api"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "app",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("500m"),
+									corev1.ResourceMemory: resource.MustParse("512Mi"),
+								},
+							},
+						},
+						{Name: "sidecar"},
+					},
+				},
+			},
+		},
+	})
+
+	changes, _, err := Changes([]query.RequestSizingRecommendation{
+		rec("deployment", "api", "app", "100m", "100Mi"),
+	}, Options{CPULimitRatio: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	live, err := GetLiveController(ctx, clientset, "Deployment", "prod", "api")
+	if err != nil {
+		t.Fatalf("getting deployment: %s", err)
+	}
+
+	diff := Diff(live.Containers, changes[0].Resources(live))
+	expected := []ResourceChange{
+		{Field: "cpu request", From: "500m", To: "100m"},
+		{Field: "cpu limit", From: "", To: "200m"},
+		{Field: "memory request", From: "512Mi", To: "100Mi"},
+	}
+	if len(diff) != 1 || diff[0].Name != "app" || len(diff[0].Changes) != len(expected) {
+		t.Fatalf("unexpected diff %+v", diff)
+	}
+	for i, c := range expected {
+		if diff[0].Changes[i] != c {
+			t.Errorf("change %d: expected %+v, got %+v", i, c, diff[0].Changes[i])
+		}
+	}
+
+	patch, err := ApplyPatch(changes[0], live)
+	if err != nil {
+		t.Fatalf("building patch: %s", err)
+	}
+	if err := Patch(ctx, clientset, "Deployment", "prod", "api", patch, false); err != nil {
+		t.Fatalf("applying: %s", err)
+	}
+
+	applied, err := GetLiveController(ctx, clientset, "Deployment", "prod", "api")
+	if err != nil {
+		t.Fatalf("getting deployment: %s", err)
+	}
+	app := applied.Containers["app"]
+	if app.Requests.Cpu().String() != "100m" || app.Limits.Cpu().String() != "200m" {
+		t.Errorf("unexpected resources after apply: %+v", app)
+	}
+	if _, ok := applied.Annotations[PreviousResourcesAnnotation]; !ok {
+		t.Errorf("expected the previous resources to be recorded")
+	}
+
+	revert, err := RevertPatch(applied)
+	if err != nil {
+		t.Fatalf("building revert patch: %s", err)
+	}
+	if err := Patch(ctx, clientset, "Deployment", "prod", "api", revert, false); err != nil {
+		t.Fatalf("reverting: %s", err)
+	}
+
+	reverted, err := GetLiveController(ctx, clientset, "Deployment", "prod", "api")
+	if err != nil {
+		t.Fatalf("getting deployment: %s", err)
+	}
+	if d := Diff(live.Containers, reverted.Containers); len(d) != 0 {
+		t.Errorf("expected the original resources after revert, got diff %+v", d)
+	}
+	if _, ok := reverted.Annotations[PreviousResourcesAnnotation]; ok {
+		t.Errorf("expected the annotation to be removed")
+	}
+}
+
+func TestApplyTwiceAndRevert(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "api"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "app",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("500m"),
+									corev1.ResourceMemory: resource.MustParse("512Mi"),
+								},
+							},
+						},
+						{
+							Name: "sidecar",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("32Mi")},
+								Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	original, err := GetLiveController(ctx, clientset, "Deployment", "prod", "api")
+	if err != nil {
+		t.Fatalf("getting deployment: %s", err)
+	}
+
+	apply := func(recs ...query.RequestSizingRecommendation) *LiveController {
+		changes, _, err := Changes(recs, Options{})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		live, err := GetLiveController(ctx, clientset, "Deployment", "prod", "api")
+		if err != nil {
+			t.Fatalf("getting deployment: %s", err)
+		}
+		patch, err := ApplyPatch(changes[0], live)
+		if err != nil {
+			t.Fatalf("building patch: %s", err)
+		}
+		if err := Patch(ctx, clientset, "Deployment", "prod", "api", patch, false); err != nil {
+			t.Fatalf("applying: %s", err)
+		}
+		applied, err := GetLiveController(ctx, clientset, "Deployment", "prod", "api")
+		if err != nil {
+			t.Fatalf("getting deployment: %s", err)
+		}
+		return applied
+	}
+
+	apply(rec("deployment", "api", "app", "200m", "256Mi"))
+
+	// The second apply changes app again, which must keep its original
+	// resources recorded, and sidecar, whose request exceeds its limit.
+	applied := apply(
+		rec("deployment", "api", "app", "100m", "128Mi"),
+		rec("deployment", "api", "sidecar", "", "100Mi"),
+	)
+	app := applied.Containers["app"]
+	if app.Requests.Cpu().String() != "100m" {
+		t.Errorf("expected a CPU request of 100m, got %s", app.Requests.Cpu().String())
+	}
+	sidecar := applied.Containers["sidecar"]
+	if sidecar.Requests.Memory().String() != "100Mi" || sidecar.Limits.Memory().String() != "100Mi" {
+		t.Errorf("expected the sidecar memory limit to be raised to its request, got %+v", sidecar)
+	}
+
+	previous, err := applied.PreviousResources()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if d := Diff(original.Containers, previous); len(previous) != 2 || len(d) != 0 {
+		t.Errorf("expected the original resources of both containers to be recorded, got %+v", previous)
+	}
+
+	revert, err := RevertPatch(applied)
+	if err != nil {
+		t.Fatalf("building revert patch: %s", err)
+	}
+	if err := Patch(ctx, clientset, "Deployment", "prod", "api", revert, false); err != nil {
+		t.Fatalf("reverting: %s", err)
+	}
+
+	reverted, err := GetLiveController(ctx, clientset, "Deployment", "prod", "api")
+	if err != nil {
+		t.Fatalf("getting deployment: %s", err)
+	}
+	if d := Diff(original.Containers, reverted.Containers); len(d) != 0 {
+		t.Errorf("expected the original resources after revert, got diff %+v", d)
+	}
+}
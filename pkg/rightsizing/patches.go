@@ -0,0 +1,259 @@
+// Package rightsizing turns container request sizing recommendations into
+// changes which can be applied to the workloads they are for.
+package rightsizing
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kubecost/kubectl-cost/pkg/query"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+// controllerTypes maps the controller kinds reported by Kubecost to their API
+// type and the path of their pod spec.
+var controllerTypes = map[string]struct {
+	apiVersion string
+	kind       string
+	podSpec    []string
+}{
+	"deployment":  {"apps/v1", "Deployment", []string{"spec", "template", "spec"}},
+	"statefulset": {"apps/v1", "StatefulSet", []string{"spec", "template", "spec"}},
+	"daemonset":   {"apps/v1", "DaemonSet", []string{"spec", "template", "spec"}},
+	"replicaset":  {"apps/v1", "ReplicaSet", []string{"spec", "template", "spec"}},
+	"job":         {"batch/v1", "Job", []string{"spec", "template", "spec"}},
+	"cronjob":     {"batch/v1", "CronJob", []string{"spec", "jobTemplate", "spec", "template", "spec"}},
+}
+
+// Options control how recommendations are turned into resources.
+type Options struct {
+	// CPUMargin and RAMMargin are added on top of recommendations as
+	// headroom, e.g. 0.1 for 10%.
+	CPUMargin float64
+	RAMMargin float64
+
+	// CPULimitRatio and RAMLimitRatio, if positive, set limits to this
+	// multiple of the new requests. Otherwise, limits are left unchanged.
+	CPULimitRatio float64
+	RAMLimitRatio float64
+}
+
+// ContainerResources are the new resources of a single container.
+type ContainerResources struct {
+	Name     string
+	Requests corev1.ResourceList
+	Limits   corev1.ResourceList
+}
+
+// Change is the set of new container resources for a single controller.
+type Change struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+
+	// PodSpecPath is the path of the pod spec in the controller, e.g.
+	// spec.template.spec.
+	PodSpecPath []string
+
+	Containers []ContainerResources
+}
+
+// Changes groups recommendations by controller and computes the resources
+// of each container, with the margins and limit ratios of opts applied.
+// Recommendations for controllers which can't be changed, like bare pods,
+// are returned as skipped.
+func Changes(recs []query.RequestSizingRecommendation, opts Options) ([]Change, []query.RequestSizingRecommendation, error) {
+	byController := map[string]*Change{}
+	var skipped []query.RequestSizingRecommendation
+
+	for _, rec := range recs {
+		t, ok := controllerTypes[strings.ToLower(rec.ControllerKind)]
+		if !ok || rec.ControllerName == "" {
+			skipped = append(skipped, rec)
+			continue
+		}
+
+		key := fmt.Sprintf("%s/%s/%s", rec.Namespace, t.kind, rec.ControllerName)
+		c, ok := byController[key]
+		if !ok {
+			c = &Change{
+				APIVersion:  t.apiVersion,
+				Kind:        t.kind,
+				Namespace:   rec.Namespace,
+				Name:        rec.ControllerName,
+				PodSpecPath: t.podSpec,
+			}
+			byController[key] = c
+		}
+
+		res := ContainerResources{
+			Name:     rec.ContainerName,
+			Requests: corev1.ResourceList{},
+		}
+		if err := setResource(&res, corev1.ResourceCPU, rec.RecommendedRequest.CPU, opts.CPUMargin, opts.CPULimitRatio); err != nil {
+			return nil, nil, fmt.Errorf("%s container %s: %s", key, rec.ContainerName, err)
+		}
+		if err := setResource(&res, corev1.ResourceMemory, rec.RecommendedRequest.Memory, opts.RAMMargin, opts.RAMLimitRatio); err != nil {
+			return nil, nil, fmt.Errorf("%s container %s: %s", key, rec.ContainerName, err)
+		}
+		if len(res.Requests) > 0 {
+			c.Containers = append(c.Containers, res)
+		}
+	}
+
+	var changes []Change
+	for _, c := range byController {
+		if len(c.Containers) == 0 {
+			continue
+		}
+		sort.Slice(c.Containers, func(i, j int) bool { return c.Containers[i].Name < c.Containers[j].Name })
+		changes = append(changes, *c)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].FileName() < changes[j].FileName() })
+
+	return changes, skipped, nil
+}
+
+// setResource sets the request, and optionally the limit, of one resource
+// from its recommendation. Empty recommendations are ignored.
+func setResource(res *ContainerResources, name corev1.ResourceName, recommended string, margin, limitRatio float64) error {
+	if recommended == "" {
+		return nil
+	}
+	qty, err := resource.ParseQuantity(recommended)
+	if err != nil {
+		return fmt.Errorf("parsing recommended %s '%s': %s", name, recommended, err)
+	}
+
+	request := scale(qty, name, 1+margin)
+	res.Requests[name] = request
+
+	if limitRatio > 0 {
+		if res.Limits == nil {
+			res.Limits = corev1.ResourceList{}
+		}
+		res.Limits[name] = scale(request, name, limitRatio)
+	}
+	return nil
+}
+
+// scale multiplies qty by f, rounding up to whole millicores for CPU and to
+// whole MiB for memory so that the result is readable.
+func scale(qty resource.Quantity, name corev1.ResourceName, f float64) resource.Quantity {
+	// Rounding error would otherwise turn e.g. 100 * 1.1 into 111.
+	ceil := func(x float64) int64 {
+		return int64(math.Ceil(x - 1e-9))
+	}
+
+	if name == corev1.ResourceCPU {
+		return *resource.NewMilliQuantity(ceil(float64(qty.MilliValue())*f), resource.DecimalSI)
+	}
+
+	const mib = 1024 * 1024
+	return *resource.NewQuantity(ceil(float64(qty.Value())*f/mib)*mib, resource.BinarySI)
+}
+
+// FileName is the name of the file which the patch for c is written to.
+func (c Change) FileName() string {
+	return strings.ToLower(fmt.Sprintf("%s-%s-%s.yaml", c.Namespace, c.Kind, c.Name))
+}
+
+// StrategicMergePatch returns c as a strategic-merge patch. It includes the
+// type and metadata of the controller, so it can also be used as a
+// Kustomize patch without a target.
+func (c Change) StrategicMergePatch() map[string]interface{} {
+	var containers []interface{}
+	for _, res := range c.Containers {
+		resources := map[string]interface{}{
+			"requests": quantities(res.Requests),
+		}
+		if len(res.Limits) > 0 {
+			resources["limits"] = quantities(res.Limits)
+		}
+		containers = append(containers, map[string]interface{}{
+			"name":      res.Name,
+			"resources": resources,
+		})
+	}
+
+	var podSpec interface{} = map[string]interface{}{"containers": containers}
+	for i := len(c.PodSpecPath) - 1; i >= 0; i-- {
+		podSpec = map[string]interface{}{c.PodSpecPath[i]: podSpec}
+	}
+
+	patch := podSpec.(map[string]interface{})
+	patch["apiVersion"] = c.APIVersion
+	patch["kind"] = c.Kind
+	patch["metadata"] = map[string]interface{}{
+		"name":      c.Name,
+		"namespace": c.Namespace,
+	}
+	return patch
+}
+
+func quantities(l corev1.ResourceList) map[string]interface{} {
+	m := map[string]interface{}{}
+	for name, qty := range l {
+		m[string(name)] = qty.String()
+	}
+	return m
+}
+
+// WritePatches writes a strategic-merge patch file for each change to dir,
+// creating it if necessary, and returns the paths of the files.
+func WritePatches(dir string, changes []Change) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating directory '%s': %s", dir, err)
+	}
+
+	var paths []string
+	for _, c := range changes {
+		b, err := yaml.Marshal(c.StrategicMergePatch())
+		if err != nil {
+			return nil, fmt.Errorf("marshaling patch for %s/%s: %s", c.Namespace, c.Name, err)
+		}
+		path := filepath.Join(dir, c.FileName())
+		if err := os.WriteFile(path, b, 0644); err != nil {
+			return nil, fmt.Errorf("writing '%s': %s", path, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// WriteKustomizeComponent writes the patches for changes to dir, along with
+// a kustomization.yaml which makes dir a Kustomize Component. Adding the
+// directory to the components of an overlay applies the recommendations.
+func WriteKustomizeComponent(dir string, changes []Change) ([]string, error) {
+	paths, err := WritePatches(dir, changes)
+	if err != nil {
+		return nil, err
+	}
+
+	var patches []interface{}
+	for _, c := range changes {
+		patches = append(patches, map[string]interface{}{"path": c.FileName()})
+	}
+	b, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "kustomize.config.k8s.io/v1alpha1",
+		"kind":       "Component",
+		"patches":    patches,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling kustomization: %s", err)
+	}
+
+	path := filepath.Join(dir, "kustomization.yaml")
+	if err := os.WriteFile(path, b, 0644); err != nil {
+		return nil, fmt.Errorf("writing '%s': %s", path, err)
+	}
+	return append(paths, path), nil
+}
@@ -0,0 +1,126 @@
+package rightsizing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubecost/kubectl-cost/pkg/manifests"
+	"github.com/kubecost/kubectl-cost/pkg/query"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func rec(kind, name, container, cpu, memory string) query.RequestSizingRecommendation {
+	r := query.RequestSizingRecommendation{
+		Namespace:      "prod",
+		ControllerKind: kind,
+		ControllerName: name,
+		ContainerName:  container,
+	}
+	r.RecommendedRequest.CPU = cpu
+	r.RecommendedRequest.Memory = memory
+	return r
+}
+
+func TestChanges(t *testing.T) {
+	recs := []query.RequestSizingRecommendation{
+		rec("deployment", "api", "app", "100m", "100Mi"),
+		rec("deployment", "api", "sidecar", "10m", ""),
+		rec("cronjob", "report", "report", "1", "1Gi"),
+		rec("", "", "bare", "1", "1Gi"),
+	}
+
+	changes, skipped, err := Changes(recs, Options{CPUMargin: 0.25, RAMMargin: 0.1, CPULimitRatio: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(skipped) != 1 || skipped[0].ContainerName != "bare" {
+		t.Errorf("expected the bare pod to be skipped, got %+v", skipped)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %d", len(changes))
+	}
+
+	// Changes are sorted by file name.
+	api := changes[1]
+	if api.Kind != "Deployment" || len(api.Containers) != 2 {
+		t.Fatalf("unexpected change %+v", api)
+	}
+	app := api.Containers[0]
+	checks := map[string]string{
+		"app cpu request":    app.Requests.Cpu().String(),
+		"app memory request": app.Requests.Memory().String(),
+		"app cpu limit":      app.Limits.Cpu().String(),
+	}
+	expected := map[string]string{
+		"app cpu request":    "125m",
+		"app memory request": "110Mi",
+		"app cpu limit":      "250m",
+	}
+	for name, got := range checks {
+		if got != expected[name] {
+			t.Errorf("%s: expected %s, got %s", name, expected[name], got)
+		}
+	}
+	if _, ok := app.Limits["memory"]; ok {
+		t.Errorf("expected no memory limit without a ratio")
+	}
+	if _, ok := api.Containers[1].Requests["memory"]; ok {
+		t.Errorf("expected no memory request without a recommendation")
+	}
+
+	cj := changes[0].StrategicMergePatch()
+	if _, found, _ := unstructured.NestedSlice(cj, "spec", "jobTemplate", "spec", "template", "spec", "containers"); !found {
+		t.Errorf("expected the CronJob patch to set containers in its job template, got %v", cj)
+	}
+}
+
+func TestWriteKustomizeComponent(t *testing.T) {
+	dir := t.TempDir()
+
+	changes, _, err := Changes([]query.RequestSizingRecommendation{
+		rec("deployment", "api", "app", "200m", "256Mi"),
+	}, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := WriteKustomizeComponent(filepath.Join(dir, "rightsizing"), changes); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	files := map[string]string{
+		"kustomization.yaml": "resources: [deployment.yaml]\ncomponents: [rightsizing]\n",
+		"deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  namespace: prod
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+        resources:
+          requests:
+            cpu: "1"
+            memory: 1Gi
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	objs, err := manifests.RenderKustomize(dir)
+	if err != nil {
+		t.Fatalf("unexpected error building overlay: %s", err)
+	}
+	containers, _, _ := unstructured.NestedSlice(objs[0].Object, "spec", "template", "spec", "containers")
+	requests, _, _ := unstructured.NestedStringMap(containers[0].(map[string]interface{}), "resources", "requests")
+	if requests["cpu"] != "200m" || requests["memory"] != "256Mi" {
+		t.Errorf("expected the recommended requests, got %v", requests)
+	}
+}
@@ -0,0 +1,83 @@
+// Package waste finds resources which cost money without doing useful work,
+// like workloads which receive no traffic and volumes which no pod uses.
+package waste
+
+import (
+	"sort"
+
+	"github.com/opencost/opencost/core/pkg/opencost"
+	"github.com/opencost/opencost/core/pkg/util/timeutil"
+)
+
+// AbandonedOptions are the thresholds below which a workload is considered
+// abandoned.
+type AbandonedOptions struct {
+	// MaxCPUCores is the highest average CPU usage, in cores.
+	MaxCPUCores float64
+
+	// MaxTrafficBytesPerSecond is the highest average network traffic,
+	// transmitted and received, in bytes per second.
+	MaxTrafficBytesPerSecond float64
+}
+
+// AbandonedWorkload is a controller with negligible CPU usage and network
+// traffic over a window.
+type AbandonedWorkload struct {
+	Cluster        string
+	Namespace      string
+	ControllerKind string
+	ControllerName string
+
+	CPUCoreUsageAverage   float64
+	TrafficBytesPerSecond float64
+	MonthlyRate           float64
+}
+
+// FindAbandoned returns the controllers of allocations, aggregated by
+// controller, whose usage is below the thresholds of opts, sorted by monthly
+// cost in descending order. Idle, unallocated and unmounted allocations are
+// ignored.
+func FindAbandoned(allocations map[string]opencost.Allocation, opts AbandonedOptions) []AbandonedWorkload {
+	var abandoned []AbandonedWorkload
+	for _, alloc := range allocations {
+		if alloc.Properties == nil || alloc.Properties.Controller == "" || alloc.IsIdle() || alloc.IsUnallocated() || alloc.IsUnmounted() {
+			continue
+		}
+		minutes := alloc.Minutes()
+		if minutes <= 0 {
+			continue
+		}
+
+		traffic := (alloc.NetworkTransferBytes + alloc.NetworkReceiveBytes) / (minutes * 60)
+		if alloc.CPUCoreUsageAverage > opts.MaxCPUCores || traffic > opts.MaxTrafficBytesPerSecond {
+			continue
+		}
+
+		abandoned = append(abandoned, AbandonedWorkload{
+			Cluster:               alloc.Properties.Cluster,
+			Namespace:             alloc.Properties.Namespace,
+			ControllerKind:        alloc.Properties.ControllerKind,
+			ControllerName:        alloc.Properties.Controller,
+			CPUCoreUsageAverage:   alloc.CPUCoreUsageAverage,
+			TrafficBytesPerSecond: traffic,
+			MonthlyRate:           alloc.TotalCost() / (minutes / 60) * timeutil.HoursPerMonth,
+		})
+	}
+
+	sort.Slice(abandoned, func(i, j int) bool {
+		return abandoned[i].MonthlyRate > abandoned[j].MonthlyRate
+	})
+	return abandoned
+}
+
+// HasNetworkData returns true if any of allocations has recorded network
+// traffic. Without it, e.g. if Kubecost's network cost monitoring is not
+// enabled, every workload looks like it receives no traffic.
+func HasNetworkData(allocations map[string]opencost.Allocation) bool {
+	for _, alloc := range allocations {
+		if alloc.NetworkTransferBytes > 0 || alloc.NetworkReceiveBytes > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package waste
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opencost/opencost/core/pkg/opencost"
+)
+
+func TestFindAbandoned(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	alloc := func(controller string, cpu, networkBytes, cost float64) opencost.Allocation {
+		return opencost.Allocation{
+			Name: controller,
+			Properties: &opencost.AllocationProperties{
+				Cluster:        "cluster-one",
+				Namespace:      "default",
+				ControllerKind: "deployment",
+				Controller:     controller,
+			},
+			// A day, so that 1 byte per second is 86400 bytes.
+			Start:                start,
+			End:                  start.Add(24 * time.Hour),
+			CPUCoreUsageAverage:  cpu,
+			NetworkReceiveBytes:  networkBytes,
+			NetworkTransferBytes: networkBytes,
+			CPUCost:              cost,
+		}
+	}
+	allocations := map[string]opencost.Allocation{
+		"busy":      alloc("busy", 0.5, 1e9, 24),
+		"quiet":     alloc("quiet", 0.001, 86400*10, 24),
+		"cheap":     alloc("cheap", 0, 0, 2.4),
+		"chatty":    alloc("chatty", 0.001, 86400*1000, 24),
+		"__idle__":  {Name: "__idle__", Properties: &opencost.AllocationProperties{}, Start: start, End: start.Add(24 * time.Hour)},
+		"unmanaged": alloc("", 0, 0, 24),
+	}
+
+	abandoned := FindAbandoned(allocations, AbandonedOptions{MaxCPUCores: 0.01, MaxTrafficBytesPerSecond: 100})
+	if len(abandoned) != 2 {
+		t.Fatalf("expected 2 abandoned workloads, got %+v", abandoned)
+	}
+
+	quiet := abandoned[0]
+	if quiet.ControllerName != "quiet" {
+		t.Errorf("expected the most expensive workload first, got %s", quiet.ControllerName)
+	}
+	if quiet.TrafficBytesPerSecond != 20 {
+		t.Errorf("expected 20 B/s of traffic, got %f", quiet.TrafficBytesPerSecond)
+	}
+	if quiet.MonthlyRate != 730 {
+		t.Errorf("expected 1/h to be projected to 730/month, got %f", quiet.MonthlyRate)
+	}
+	if abandoned[1].ControllerName != "cheap" {
+		t.Errorf("expected cheap second, got %s", abandoned[1].ControllerName)
+	}
+
+	if !HasNetworkData(allocations) {
+		t.Errorf("expected network data")
+	}
+}
@@ -0,0 +1,142 @@
+package waste
+
+import (
+	"sort"
+
+	"github.com/kubecost/kubectl-cost/pkg/pricing"
+	"github.com/kubecost/kubectl-cost/pkg/query"
+
+	"github.com/opencost/opencost/core/pkg/opencost"
+	"github.com/opencost/opencost/core/pkg/util/timeutil"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// VolumeCosts prices persistent volumes.
+type VolumeCosts struct {
+	// ByVolume maps volume names to their monthly rate, from disk assets.
+	ByVolume map[string]float64
+
+	// Rates estimate the cost of volumes which have no disk asset yet.
+	Rates pricing.StorageRates
+}
+
+// NewVolumeCosts computes the monthly rate of each disk asset which backs a
+// persistent volume.
+func NewVolumeCosts(disks []query.AssetDisk) VolumeCosts {
+	costs := VolumeCosts{
+		ByVolume: map[string]float64{},
+		Rates:    pricing.StorageRatesFromDisks(disks),
+	}
+	for _, d := range disks {
+		name := d.VolumeName
+		if name == "" {
+			name = d.Properties.Name
+		}
+		if name == "" || d.Minutes <= 0 {
+			continue
+		}
+		costs.ByVolume[name] += d.TotalCost / (d.Minutes / 60) * timeutil.HoursPerMonth
+	}
+	return costs
+}
+
+// UnclaimedVolume is a PersistentVolume which no running pod mounts.
+type UnclaimedVolume struct {
+	Name         string
+	StorageClass string
+	Bytes        int64
+	Phase        corev1.PersistentVolumePhase
+
+	// ClaimNamespace and ClaimName are the claim the volume is bound to,
+	// if any.
+	ClaimNamespace string
+	ClaimName      string
+
+	// UsedInWindow is true if a pod used the volume at some point during the
+	// window of the usage data.
+	UsedInWindow bool
+
+	MonthlyRate float64
+
+	// Estimated is true if there is no disk asset for the volume, so its
+	// cost is estimated from the price of its StorageClass.
+	Estimated bool
+}
+
+// FindUnclaimedVolumes returns the volumes of pvs which are not mounted by any
+// of pods that hasn't terminated, sorted by monthly cost in descending order.
+// used is the set of volume names which pods used during the window of the
+// usage data.
+func FindUnclaimedVolumes(pvs []corev1.PersistentVolume, pods []corev1.Pod, used map[string]bool, costs VolumeCosts) []UnclaimedVolume {
+	mounted := map[string]bool{}
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, v := range pod.Spec.Volumes {
+			if v.PersistentVolumeClaim != nil {
+				mounted[pod.Namespace+"/"+v.PersistentVolumeClaim.ClaimName] = true
+			}
+			// Generic ephemeral volumes are backed by a claim named after
+			// the pod and volume.
+			if v.Ephemeral != nil {
+				mounted[pod.Namespace+"/"+pod.Name+"-"+v.Name] = true
+			}
+		}
+	}
+
+	var unclaimed []UnclaimedVolume
+	for _, pv := range pvs {
+		v := UnclaimedVolume{
+			Name:         pv.Name,
+			StorageClass: pv.Spec.StorageClassName,
+			Phase:        pv.Status.Phase,
+			UsedInWindow: used[pv.Name],
+		}
+		if ref := pv.Spec.ClaimRef; ref != nil {
+			if mounted[ref.Namespace+"/"+ref.Name] {
+				continue
+			}
+			v.ClaimNamespace, v.ClaimName = ref.Namespace, ref.Name
+		}
+		if capacity, ok := pv.Spec.Capacity[corev1.ResourceStorage]; ok {
+			v.Bytes = capacity.Value()
+		}
+
+		if rate, ok := costs.ByVolume[pv.Name]; ok {
+			v.MonthlyRate = rate
+		} else {
+			rate, _ := costs.Rates.GiBHourRate(v.StorageClass)
+			v.MonthlyRate = float64(v.Bytes) / pricing.BytesPerGiB * rate * timeutil.HoursPerMonth
+			v.Estimated = true
+		}
+
+		unclaimed = append(unclaimed, v)
+	}
+
+	sort.Slice(unclaimed, func(i, j int) bool {
+		if unclaimed[i].MonthlyRate != unclaimed[j].MonthlyRate {
+			return unclaimed[i].MonthlyRate > unclaimed[j].MonthlyRate
+		}
+		return unclaimed[i].Name < unclaimed[j].Name
+	})
+	return unclaimed
+}
+
+// UsedVolumes returns the names of the volumes of cluster which allocations
+// used. Allocations for unmounted volumes are ignored.
+func UsedVolumes(allocations map[string]opencost.Allocation, cluster string) map[string]bool {
+	used := map[string]bool{}
+	for _, alloc := range allocations {
+		if alloc.IsUnmounted() {
+			continue
+		}
+		for key := range alloc.PVs {
+			if cluster == "" || key.Cluster == cluster {
+				used[key.Name] = true
+			}
+		}
+	}
+	return used
+}
@@ -0,0 +1,83 @@
+package waste
+
+import (
+	"testing"
+
+	"github.com/kubecost/kubectl-cost/pkg/pricing"
+	"github.com/kubecost/kubectl-cost/pkg/query"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFindUnclaimedVolumes(t *testing.T) {
+	pv := func(name, claim string, phase corev1.PersistentVolumePhase) corev1.PersistentVolume {
+		v := corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PersistentVolumeSpec{
+				StorageClassName: "standard",
+				Capacity:         corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+			},
+			Status: corev1.PersistentVolumeStatus{Phase: phase},
+		}
+		if claim != "" {
+			v.Spec.ClaimRef = &corev1.ObjectReference{Namespace: "default", Name: claim}
+		}
+		return v
+	}
+	pod := func(claim string, phase corev1.PodPhase) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-" + claim},
+			Spec: corev1.PodSpec{
+				Volumes: []corev1.Volume{{
+					Name: "data",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+					},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	pvs := []corev1.PersistentVolume{
+		pv("pv-mounted", "mounted", corev1.VolumeBound),
+		pv("pv-completed", "completed", corev1.VolumeBound),
+		pv("pv-released", "deleted", corev1.VolumeReleased),
+		pv("pv-available", "", corev1.VolumeAvailable),
+	}
+	pods := []corev1.Pod{
+		pod("mounted", corev1.PodRunning),
+		pod("completed", corev1.PodSucceeded),
+	}
+	costs := NewVolumeCosts([]query.AssetDisk{{
+		VolumeName:   "pv-released",
+		StorageClass: "standard",
+		Minutes:      60,
+		ByteHours:    10 * pricing.BytesPerGiB,
+		TotalCost:    1,
+	}})
+
+	unclaimed := FindUnclaimedVolumes(pvs, pods, map[string]bool{"pv-completed": true}, costs)
+	if len(unclaimed) != 3 {
+		t.Fatalf("expected 3 unclaimed volumes, got %+v", unclaimed)
+	}
+
+	// All volumes are 10Gi of the same class, so their estimated costs
+	// equal that of the disk asset.
+	for _, v := range unclaimed {
+		if v.MonthlyRate != 730 {
+			t.Errorf("%s: expected a monthly rate of 730, got %f", v.Name, v.MonthlyRate)
+		}
+		if v.Estimated != (v.Name != "pv-released") {
+			t.Errorf("%s: unexpected estimated %t", v.Name, v.Estimated)
+		}
+		if v.UsedInWindow != (v.Name == "pv-completed") {
+			t.Errorf("%s: unexpected used in window %t", v.Name, v.UsedInWindow)
+		}
+	}
+	if unclaimed[0].Name != "pv-available" || unclaimed[0].ClaimName != "" {
+		t.Errorf("expected volumes of equal cost to be sorted by name, got %+v", unclaimed[0])
+	}
+}
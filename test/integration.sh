@@ -25,6 +25,7 @@ $binary namespace --window 5d
 # https://stackoverflow.com/questions/59895/how-do-i-get-the-directory-where-a-bash-script-is-located-from-within-the-script
 SCRIPT_DIR=$( cd -- "$( dirname -- "${BASH_SOURCE[0]}" )" &> /dev/null && pwd )
 $binary predict -f "${SCRIPT_DIR}/multi.yaml"
+$binary predict -R -f "${SCRIPT_DIR}"
 $binary predict --no-usage -f "${SCRIPT_DIR}/multi.yaml"
 $binary predict --hide-diff --show-total -f "${SCRIPT_DIR}/multi.yaml"
 